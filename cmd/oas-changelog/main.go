@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/sarpt/openapi-utils/pkg/openapi"
+)
+
+var (
+	oldFile      *string
+	newFile      *string
+	inputFile    *string
+	gitRepo      *string
+	oldRev       *string
+	newRev       *string
+	outputFile   *string
+	format       *string
+	templateFile *string
+	title        *string
+)
+
+func init() {
+	oldFile = flag.String("old", "", "path to the root yaml file of the old version of the specification. Ignored when old-rev is provided")
+	newFile = flag.String("new", "", "path to the root yaml file of the new version of the specification. Ignored when new-rev is provided")
+	inputFile = flag.String("input-file", "", "path to the root yaml file of the specification inside of the git repository. Used along with old-rev and new-rev")
+	gitRepo = flag.String("git-repo", ".", "path to the git repository holding the specification. Used along with old-rev and new-rev")
+	oldRev = flag.String("old-rev", "", "git revision of the old version of the specification, eg. v1.3.0. Requires input-file")
+	newRev = flag.String("new-rev", "", "git revision of the new version of the specification, eg. HEAD. Requires input-file")
+	outputFile = flag.String("output-file", "", "path to the output file. When not provided standard output is used")
+	format = flag.String("format", string(openapi.MarkdownChangelog), "format of the changelog when rendered with a built-in template: markdown or html")
+	templateFile = flag.String("template", "", "path to a custom Go template rendering the changelog. Templates with .html extension are escaped as HTML. Overrides format")
+	title = flag.String("title", "Changelog", "title of the changelog")
+	flag.Parse()
+}
+
+func main() {
+	oldDocument, err := parseVersion(*oldFile, *oldRev)
+	if err != nil {
+		log.Fatalf("Error while parsing the old version: %v", err)
+	}
+
+	newDocument, err := parseVersion(*newFile, *newRev)
+	if err != nil {
+		log.Fatalf("Error while parsing the new version: %v", err)
+	}
+
+	changelog := openapi.NewChangelog(*title, openapi.Diff(&oldDocument, &newDocument))
+
+	tmpl, err := changelogTemplate()
+	if err != nil {
+		log.Fatalf("Could not parse the changelog template: %v", err)
+	}
+
+	if *outputFile != "" {
+		outputFilePath, err := filepath.Abs(*outputFile)
+		if err != nil {
+			log.Fatalf("Could not parse output file path: %v", err)
+		}
+
+		var output bytes.Buffer
+		err = changelog.WriteTemplate(&output, tmpl)
+		if err != nil {
+			log.Fatalf("Error while rendering the changelog: %v", err)
+		}
+
+		err = ioutil.WriteFile(outputFilePath, output.Bytes(), os.FileMode(0644))
+		if err != nil {
+			log.Fatalf("Error while writing output to path %s: %v", outputFilePath, err)
+		}
+
+		fmt.Printf("Wrote changelog to %s", outputFilePath)
+	} else {
+		err := changelog.WriteTemplate(os.Stdout, tmpl)
+		if err != nil {
+			log.Fatalf("Could not write changelog to standard output: %v", err)
+		}
+	}
+}
+
+func changelogTemplate() (openapi.Template, error) {
+	if *templateFile != "" {
+		return openapi.ParseChangelogTemplate(*templateFile)
+	}
+
+	return openapi.ChangelogTemplate(openapi.ChangelogFormat(*format))
+}
+
+// parseVersion combines a version of the specification, either from a file or from the git revision when it is provided.
+// Revisions are read from the object database of the repository, without exporting or checking them out.
+func parseVersion(file string, rev string) (openapi.Document, error) {
+	if rev == "" {
+		if file == "" {
+			return openapi.Document{}, fmt.Errorf("either a file or a git revision needs to be provided")
+		}
+
+		path, err := filepath.Abs(file)
+		if err != nil {
+			return openapi.Document{}, err
+		}
+
+		return openapi.ParseDocument(openapi.Config{}, path)
+	}
+
+	if *inputFile == "" {
+		return openapi.Document{}, fmt.Errorf("input-file needs to be provided along with a git revision")
+	}
+
+	gitLoader, err := openapi.NewGitLoader(*gitRepo, rev)
+	if err != nil {
+		return openapi.Document{}, fmt.Errorf("could not read revision %s: %w", rev, err)
+	}
+
+	path, err := filepath.Abs(*inputFile)
+	if err != nil {
+		return openapi.Document{}, err
+	}
+
+	return openapi.ParseDocument(openapi.Config{Loader: gitLoader}, path)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/sarpt/openapi-utils/pkg/openapi"
+)
+
+const (
+	breakingExitCode = 1
+)
+
+var (
+	oldFile        *string
+	newFile        *string
+	oldRev         *string
+	newRev         *string
+	outputFile     *string
+	format         *string
+	failOnBreaking *bool
+)
+
+func init() {
+	oldFile = flag.String("old", "", "path to the root yaml file of the old version of the specification. Remote refs are resolved before comparison")
+	newFile = flag.String("new", "", "path to the root yaml file of the new version of the specification. Remote refs are resolved before comparison")
+	oldRev = flag.String("old-rev", "", "git revision, eg. a tag 'v1.3.0', a branch or a commit hash, at which old and all files referenced by it are read from the object database of the repository holding old, without checking the revision out")
+	newRev = flag.String("new-rev", "", "git revision, eg. a tag 'v1.4.0', a branch or a commit hash, at which new and all files referenced by it are read from the object database of the repository holding new, without checking the revision out")
+	outputFile = flag.String("output-file", "", "path to the output file. When not provided standard output is used")
+	format = flag.String("format", string(openapi.TextChanges), "output format of changes: text, json or markdown")
+	failOnBreaking = flag.Bool("fail-on-breaking", false, "exit with a non-zero code when any breaking change is found. False by default")
+	flag.Parse()
+}
+
+func main() {
+	if *oldFile == "" || *newFile == "" {
+		log.Fatalf("Both old and new need to be provided")
+	}
+
+	oldDocument, err := parseDocument(*oldFile, *oldRev)
+	if err != nil {
+		log.Fatalf("Error while parsing the old document: %v", err)
+	}
+
+	newDocument, err := parseDocument(*newFile, *newRev)
+	if err != nil {
+		log.Fatalf("Error while parsing the new document: %v", err)
+	}
+
+	changes := openapi.Diff(&oldDocument, &newDocument)
+
+	if *outputFile != "" {
+		outputFilePath, err := filepath.Abs(*outputFile)
+		if err != nil {
+			log.Fatalf("Could not parse output file path: %v", err)
+		}
+
+		output, err := os.Create(outputFilePath)
+		if err != nil {
+			log.Fatalf("Could not create output file %s: %v", outputFilePath, err)
+		}
+
+		err = openapi.WriteChanges(output, changes, openapi.ChangesFormat(*format))
+		output.Close()
+		if err != nil {
+			log.Fatalf("Error while writing output to path %s: %v", outputFilePath, err)
+		}
+
+		fmt.Printf("Wrote changes to %s", outputFilePath)
+	} else {
+		err := openapi.WriteChanges(os.Stdout, changes, openapi.ChangesFormat(*format))
+		if err != nil {
+			log.Fatalf("Could not write changes to standard output: %v", err)
+		}
+	}
+
+	if *failOnBreaking && openapi.HasBreaking(changes) {
+		os.Exit(breakingExitCode)
+	}
+}
+
+// parseDocument combines the document, as it is at the git revision when it is provided
+func parseDocument(path string, rev string) (openapi.Document, error) {
+	absolutePath, err := filepath.Abs(path)
+	if err != nil {
+		return openapi.Document{}, err
+	}
+
+	if rev == "" {
+		return openapi.ParseDocument(openapi.Config{}, absolutePath)
+	}
+
+	gitLoader, err := openapi.NewGitLoader(filepath.Dir(absolutePath), rev)
+	if err != nil {
+		return openapi.Document{}, fmt.Errorf("could not read revision %s: %w", rev, err)
+	}
+
+	return openapi.ParseDocument(openapi.Config{Loader: gitLoader}, absolutePath)
+}
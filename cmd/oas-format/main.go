@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/sarpt/openapi-utils/pkg/openapi"
+)
+
+const (
+	unformattedExitCode = 1
+)
+
+var (
+	inputFile  *string
+	outputFile *string
+	write      *bool
+	check      *bool
+)
+
+func init() {
+	inputFile = flag.String("input-file", "", "path to the yaml file to be formatted. Refs are left in place, so every file of a multi-file specification can be formatted separately. When not provided, standard input is used to read the file contents")
+	outputFile = flag.String("output-file", "", "path to the output yaml file. When not provided standard output is used")
+	write = flag.Bool("write", false, "write the formatted document back to input-file instead of the output. False by default")
+	check = flag.Bool("check", false, "instead of writing the output, exit with a non-zero code when the input is not formatted. False by default")
+	flag.Parse()
+}
+
+func main() {
+	var input []byte
+	var err error
+	if *inputFile != "" {
+		input, err = ioutil.ReadFile(*inputFile)
+	} else {
+		input, err = ioutil.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		log.Fatalf("Error while reading the input: %v", err)
+	}
+
+	document := openapi.NewDocument(openapi.Config{})
+	err = document.Parse(input)
+	if err != nil {
+		log.Fatalf("Error while parsing the document: %v", err)
+	}
+
+	err = document.Normalize()
+	if err != nil {
+		log.Fatalf("Error while normalizing the document: %v", err)
+	}
+
+	output, err := document.YAML()
+	if err != nil {
+		log.Fatalf("Error while formatting the document: %v", err)
+	}
+
+	if *check {
+		if !bytes.Equal(input, output) {
+			fmt.Fprintln(os.Stderr, "Document is not formatted")
+			os.Exit(unformattedExitCode)
+		}
+
+		return
+	}
+
+	outputPath := *outputFile
+	if *write {
+		if *inputFile == "" {
+			log.Fatalf("input-file needs to be provided along with write")
+		}
+
+		outputPath = *inputFile
+	}
+
+	if outputPath != "" {
+		outputFilePath, err := filepath.Abs(outputPath)
+		if err != nil {
+			log.Fatalf("Could not parse output file path: %v", err)
+		}
+
+		err = ioutil.WriteFile(outputFilePath, output, os.FileMode(0644))
+		if err != nil {
+			log.Fatalf("Error while writing output to path %s: %v", outputFilePath, err)
+		}
+
+		fmt.Printf("Wrote formatted YAML file to %s", outputFilePath)
+	} else {
+		_, err := os.Stdout.Write(output)
+		if err != nil {
+			log.Fatalf("Could not write yaml to standard output: %v", err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/sarpt/openapi-utils/pkg/openapi"
+)
+
+var (
+	inputFile      *string
+	outputFile     *string
+	format         *string
+	dependenciesOf *string
+	dependentsOf   *string
+)
+
+func init() {
+	inputFile = flag.String("input-file", "", "path to the root yaml file of the specification. Files referenced from it are included in the graph")
+	outputFile = flag.String("output-file", "", "path to the output file. When not provided standard output is used")
+	format = flag.String("format", string(openapi.DOTFormat), "output format of the graph: dot, mermaid or json")
+	dependenciesOf = flag.String("dependencies-of", "", "limit the graph to nodes reachable from the matching nodes, eg. 'POST /orders', an operationId or 'schemas/Money'")
+	dependentsOf = flag.String("dependents-of", "", "limit the graph to nodes that depend on the matching nodes, eg. 'schemas/Money'")
+	flag.Parse()
+}
+
+func main() {
+	if *inputFile == "" {
+		log.Fatalf("The input-file needs to be provided")
+	}
+
+	graph, err := openapi.BuildGraph(*inputFile)
+	if err != nil {
+		log.Fatalf("Error while building the graph: %v", err)
+	}
+
+	if *dependenciesOf != "" {
+		ids, err := graph.Find(*dependenciesOf)
+		if err != nil {
+			log.Fatalf("Could not find nodes to get dependencies of: %v", err)
+		}
+
+		graph = graph.Dependencies(ids...)
+	}
+
+	if *dependentsOf != "" {
+		ids, err := graph.Find(*dependentsOf)
+		if err != nil {
+			log.Fatalf("Could not find nodes to get dependents of: %v", err)
+		}
+
+		graph = graph.Dependents(ids...)
+	}
+
+	if *outputFile != "" {
+		outputFilePath, err := filepath.Abs(*outputFile)
+		if err != nil {
+			log.Fatalf("Could not parse output file path: %v", err)
+		}
+
+		output, err := os.Create(outputFilePath)
+		if err != nil {
+			log.Fatalf("Could not create output file %s: %v", outputFilePath, err)
+		}
+		defer output.Close()
+
+		err = graph.Write(output, openapi.GraphFormat(*format))
+		if err != nil {
+			log.Fatalf("Error while writing output to path %s: %v", outputFilePath, err)
+		}
+
+		fmt.Printf("Wrote graph to %s", outputFilePath)
+	} else {
+		err := graph.Write(os.Stdout, openapi.GraphFormat(*format))
+		if err != nil {
+			log.Fatalf("Could not write graph to standard output: %v", err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/sarpt/openapi-utils/pkg/openapi"
+)
+
+const (
+	renameCommand = "rename"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case renameCommand:
+		rename(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [arguments]\n\nCommands:\n  %s\trename or move a component, rewriting all references to it\n", filepath.Base(os.Args[0]), renameCommand)
+}
+
+func rename(args []string) {
+	flags := flag.NewFlagSet(renameCommand, flag.ExitOnError)
+	inputFile := flags.String("input-file", "", "path to the root yaml file of the specification. All files referenced from it, directly or indirectly, are updated")
+	component := flags.String("component", "", "reference to the component that should be renamed or moved, relative to the input-file, eg. '#/components/schemas/UserDTO' or 'common.yaml#/components/schemas/UserDTO'")
+	name := flags.String("name", "", "new name of the component. When not provided, the component keeps its name")
+	file := flags.String("file", "", "path to the file to which the component should be moved. When not provided, the component stays in its file")
+	dryRun := flags.Bool("dry-run", false, "only list the files that would be changed, without writing them")
+	flags.Parse(args)
+
+	if *inputFile == "" || *component == "" {
+		log.Fatalf("Both input-file and component need to be provided")
+	}
+
+	if *name == "" && *file == "" {
+		log.Fatalf("At least one of name or file needs to be provided")
+	}
+
+	refactoring, err := openapi.NewRefactoring(*inputFile)
+	if err != nil {
+		log.Fatalf("Error while reading the specification: %v", err)
+	}
+
+	target, err := targetReference(refactoring.RootPath, *component, *name, *file)
+	if err != nil {
+		log.Fatalf("Could not construct target reference: %v", err)
+	}
+
+	err = refactoring.RenameComponent(*component, target)
+	if err != nil {
+		log.Fatalf("Error while renaming the component: %v", err)
+	}
+
+	for _, changedFile := range refactoring.ChangedFiles() {
+		fmt.Println(changedFile)
+	}
+
+	if *dryRun {
+		return
+	}
+
+	err = refactoring.WriteFiles()
+	if err != nil {
+		log.Fatalf("Error while writing the specification: %v", err)
+	}
+}
+
+// targetReference builds a reference to the new location of the component, relative to the root file.
+func targetReference(rootPath string, component string, name string, file string) (string, error) {
+	documentPath, pointer := splitReference(component)
+	if name != "" {
+		pointer = path.Join(path.Dir(pointer), name)
+	}
+
+	if file != "" {
+		filePath, err := filepath.Abs(file)
+		if err != nil {
+			return "", err
+		}
+
+		documentPath, err = filepath.Rel(filepath.Dir(rootPath), filePath)
+		if err != nil {
+			return "", err
+		}
+
+		if filePath == rootPath {
+			documentPath = ""
+		}
+	}
+
+	return fmt.Sprintf("%s#%s", filepath.ToSlash(documentPath), pointer), nil
+}
+
+func splitReference(ref string) (string, string) {
+	for idx, char := range ref {
+		if char == '#' {
+			return ref[:idx], ref[idx+1:]
+		}
+	}
+
+	return ref, ""
+}
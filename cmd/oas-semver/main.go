@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/sarpt/openapi-utils/pkg/openapi"
+)
+
+var (
+	oldFile        *string
+	newFile        *string
+	currentVersion *string
+	rulesFile      *string
+	write          *bool
+	verbose        *bool
+)
+
+func init() {
+	oldFile = flag.String("old", "", "path to the root yaml file of the old version of the specification")
+	newFile = flag.String("new", "", "path to the root yaml file of the new version of the specification")
+	currentVersion = flag.String("current-version", "", "version that should be bumped. When not provided, info.version of the old version of the specification is used")
+	rulesFile = flag.String("rules", "", "path to a yaml file with bump rules, eg. 'extensions: {x-breaking: major}'. When not provided, breaking changes bump major version, additions and deprecations bump minor version and other changes bump patch version")
+	write = flag.Bool("write", false, "write the suggested version to info.version of the new root file. False by default")
+	verbose = flag.Bool("verbose", false, "print changes that were taken into account before the suggested version. False by default")
+	flag.Parse()
+}
+
+func main() {
+	if *oldFile == "" || *newFile == "" {
+		log.Fatalf("Both old and new need to be provided")
+	}
+
+	rules := openapi.DefaultBumpRules()
+	if *rulesFile != "" {
+		var err error
+		rules, err = openapi.ReadBumpRules(*rulesFile)
+		if err != nil {
+			log.Fatalf("Could not read bump rules: %v", err)
+		}
+	}
+
+	oldDocument, err := parseDocument(*oldFile)
+	if err != nil {
+		log.Fatalf("Error while parsing the old document: %v", err)
+	}
+
+	newDocument, err := parseDocument(*newFile)
+	if err != nil {
+		log.Fatalf("Error while parsing the new document: %v", err)
+	}
+
+	current := *currentVersion
+	if current == "" && oldDocument.Root.Info != nil {
+		current = oldDocument.Root.Info.Version
+	}
+
+	changes := openapi.Diff(&oldDocument, &newDocument)
+	version, bump, err := openapi.SuggestVersion(current, changes, rules)
+	if err != nil {
+		log.Fatalf("Could not suggest a version: %v", err)
+	}
+
+	if *verbose {
+		err = openapi.WriteChanges(os.Stderr, changes, openapi.TextChanges)
+		if err != nil {
+			log.Fatalf("Could not write changes: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "Suggested bump: %s\n", bump)
+	}
+
+	fmt.Println(version)
+
+	if *write {
+		newFilePath, err := filepath.Abs(*newFile)
+		if err != nil {
+			log.Fatalf("Could not parse new file path: %v", err)
+		}
+
+		err = openapi.WriteVersion(newFilePath, version)
+		if err != nil {
+			log.Fatalf("Error while writing version to %s: %v", newFilePath, err)
+		}
+	}
+}
+
+func parseDocument(path string) (openapi.Document, error) {
+	absolutePath, err := filepath.Abs(path)
+	if err != nil {
+		return openapi.Document{}, err
+	}
+
+	return openapi.ParseDocument(openapi.Config{}, absolutePath)
+}
@@ -1,24 +1,87 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/sarpt/openapi-utils/pkg/openapi"
 )
 
+const (
+	mergePrefixSeparator = "="
+)
+
 var (
-	inputFile        *string
-	outputFile       *string
-	refDirectory     *string
-	inlineLocalRefs  *bool
-	inlineRemoteRefs *bool
-	keepLocalRefs    *bool
+	inputFile         *string
+	outputFile        *string
+	refDirectory      *string
+	inlineLocalRefs   *bool
+	inlineRemoteRefs  *bool
+	keepLocalRefs     *bool
+	prune             *bool
+	pruneDryRun       *bool
+	includeTags       *string
+	excludeTags       *string
+	includePaths      *string
+	includeOperations *string
+	removeMarked      listFlag
+	overlays          listFlag
+	inputArchive      *string
+	gitRev            *string
+	httpTimeout       *time.Duration
+	timeout           *time.Duration
+	allowHosts        *string
+	cacheDir          *string
+	offline           *bool
+	parallelism       *int
+	parseCacheDir     *string
+	stream            *bool
+	watch             *bool
+	watchInterval     *time.Duration
+	sandbox           *bool
+	sandboxRoot       *string
+	sandboxAllowURLs  *bool
+	forbidSymlinks    *bool
+	forbidAbsRefs     *bool
+	maxFileSize       *int64
+	maxFiles          *int
+	maxRefDepth       *int
+	valuesFile        *string
+	setValues         listFlag
+	expandEnv         *bool
+	mergeFiles        listFlag
+	onPathConflict    *string
+	mergeReport       *string
+	extractInline     *bool
+	extractNaming     *string
+	flattenAllOf      *bool
+	dedupe            *bool
+	dedupeNaming      *string
+	dedupePrefer      *string
 )
 
+// listFlag collects values of a flag that can be provided multiple times
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, " ")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 func init() {
 	inputFile = flag.String("input-file", "", "path to the input yaml file to be processed. Providing input-file sets the ref directory to the parent directory of provided input-file path. When not provided, standard input is used to read the file contents")
 	outputFile = flag.String("output-file", "", "path to the output yaml file. When not provided standard output is used to return the result of documents combining")
@@ -26,29 +89,380 @@ func init() {
 	inlineLocalRefs = flag.Bool("inline-local", false, "should local refs be inlined in place when resolved. When set to false, local references are left in place since they are skipped from resolving. False by default")
 	inlineRemoteRefs = flag.Bool("inline-remote", false, "should remote refs be inlined in place rather than being placed in a local equivalent. False by default. Note: remote refs are always resolved and never left in place when encountered in a document, since it's the whole point of combining documents")
 	keepLocalRefs = flag.Bool("keep-local", false, "keep local refs after inlining. Makes sense only when inline-local is specified as true, otherwise has no effect in order to prevent outputting incorrect yaml file with missing references")
+	prune = flag.Bool("prune", false, "remove components that are not reachable from paths, webhooks or security requirements after refs are resolved. Note: with keep-local set to true, components inlined in place are no longer referenced and are removed too. False by default")
+	pruneDryRun = flag.Bool("prune-dry-run", false, "instead of writing the output, print refs of components that would be removed by prune. False by default")
+	includeTags = flag.String("include-tags", "", "comma separated list of tags. When provided, only operations with at least one of the tags are kept. Unreachable components and unused tags are removed afterwards")
+	excludeTags = flag.String("exclude-tags", "", "comma separated list of tags. When provided, operations with any of the tags are removed. Unreachable components and unused tags are removed afterwards")
+	includePaths = flag.String("include-paths", "", "comma separated list of path globs, eg. '/orders/**'. When provided, only operations of matching paths are kept. Unreachable components and unused tags are removed afterwards")
+	includeOperations = flag.String("include-operations", "", "comma separated list of operationIds. When provided, only operations with the ids are kept. Unreachable components and unused tags are removed afterwards")
+	flag.Var(&removeMarked, "remove-marked", "remove operations, parameters, properties, components and other objects marked with an extension, eg. 'x-internal' (matching x-internal: true) or 'x-audience=partner,internal' (matching the value or any element of a list). Objects referencing removed components are removed too. Can be provided multiple times")
+	extractInline = flag.Bool("extract-inline", false, "move inline object schemas with properties used by operations, along with object schemas nested in them, to components/schemas and replace them with refs. False by default")
+	extractNaming = flag.String("extract-naming", openapi.DefaultSchemaNameTemplate, "Go template naming extracted schemas. Available fields: Operation, OperationID, Method, Path, Location (Request, Response, Parameter, Property, Items, AllOf, OneOf, AnyOf, Not or AdditionalProperties), StatusCode, MediaType, Name, Parent and Title")
+	inputArchive = flag.String("input-archive", "", "path to a zip, tar, tar.gz or tgz archive holding the specification. When provided, input-file and merge-file paths point to files inside of the archive, relative to its root")
+	gitRev = flag.String("git-rev", "", "git revision, eg. a tag 'v1.4.0', a branch or a commit hash, at which input-file, merge-file and all referenced files are read from the object database of the repository holding input-file (or the current working directory), without checking the revision out")
+	httpTimeout = flag.Duration("http-timeout", openapi.DefaultHTTPTimeout, "time limit of fetching a single document referenced by an URL, eg. 10s")
+	timeout = flag.Duration("timeout", 0, "time limit of the whole run, eg. 1m. Reading, resolving refs (including fetching documents referenced by URLs) and writing stop once it is exceeded. Not limited by default")
+	allowHosts = flag.String("allow-hosts", "", "comma separated list of hosts documents referenced by URLs can be fetched from, eg. 'schemas.example.com,*.internal.example.com'. When not provided, all hosts are allowed")
+	cacheDir = flag.String("cache-dir", "", "directory where documents fetched from URLs are cached and revalidated with their ETags. When not provided, documents are not cached")
+	offline = flag.Bool("offline", false, "read documents referenced by URLs only from cache-dir, without fetching them. False by default")
+	parallelism = flag.Int("parallelism", openapi.DefaultParallelism, "maximum number of referenced documents loaded and parsed at the same time")
+	parseCacheDir = flag.String("parse-cache-dir", "", "directory where parsed documents are kept between runs, keyed by hashes of their content, so only changed documents are parsed again. When not provided, documents are always parsed")
+	stream = flag.Bool("stream", false, "read, resolve and write paths and webhooks one by one, so huge specifications are combined with bounded memory. Cannot be used with inline-local, watch, prune, filters, merge-file, overlay, flatten-allof, extract-inline, dedupe, input-archive and git-rev. False by default")
+	watch = flag.Bool("watch", false, "keep running after writing the output, and write it again whenever input-file or any file it references changes. Only changed files and files referencing them are loaded and resolved again. Requires input-file and cannot be used with stream, prune, filters, merge-file, overlay, flatten-allof, extract-inline, dedupe, input-archive and git-rev. False by default")
+	watchInterval = flag.Duration("watch-interval", 500*time.Millisecond, "how often files are checked for changes in watch mode")
+	sandbox = flag.Bool("sandbox", false, "resolve refs in a sandbox, eg. for specifications submitted by users: documents outside of sandbox-root (also through symbolic links) and documents referenced by URLs are rejected, and limits are enforced. False by default")
+	sandboxRoot = flag.String("sandbox-root", "", "directory documents have to be in when sandbox is set. When not provided, the directory of input-file is used")
+	sandboxAllowURLs = flag.Bool("sandbox-allow-urls", false, "allow documents referenced by URLs when sandbox is set. False by default")
+	forbidSymlinks = flag.Bool("forbid-symlinks", false, "reject paths going through symbolic links when sandbox is set. False by default")
+	forbidAbsRefs = flag.Bool("forbid-absolute-refs", false, "reject refs pointing to documents by absolute paths when sandbox is set. False by default")
+	maxFileSize = flag.Int64("max-file-size", 0, "maximum size of a single document in bytes when sandbox is set. Not limited by default")
+	maxFiles = flag.Int("max-files", 0, "maximum number of documents read, including input-file, when sandbox is set. Not limited by default")
+	maxRefDepth = flag.Int("max-ref-depth", 0, "maximum length of chains of refs to other documents when sandbox is set, eg. 1 allows only documents referenced directly by input-file. Not limited by default")
+	valuesFile = flag.String("values", "", "path to a yaml file with values expanding ${name} and {{ .name }} placeholders in the input file and all referenced files before parsing. Nested values are available as ${parent.child}")
+	flag.Var(&setValues, "set", "value expanding ${key} and {{ .key }} placeholders in the input file and all referenced files before parsing, in key=value form. Overrides values from the values file. Can be provided multiple times")
+	expandEnv = flag.Bool("env", false, "expand ${NAME} and {{ .Env.NAME }} placeholders in the input file and all referenced files with environment variables before parsing. Values from the values file and set flags take precedence. False by default")
+	flag.Var(&mergeFiles, "merge-file", "path to a root yaml file of a specification merged with the input file (or with other merge files when input-file is not provided), optionally followed by '=' and a prefix for its paths, eg. 'billing/openapi.yaml=/billing'. Paths, components, tags and servers are unioned, colliding components are renamed with info.title (or the file name) as a prefix. Can be provided multiple times")
+	onPathConflict = flag.String("on-path-conflict", string(openapi.FailOnConflict), "what happens when merged documents define the same operation: error, or override with the operation of the document merged last")
+	mergeReport = flag.String("merge-report", "", "path to a file where conflicts of merged documents are written, as JSON when the file has .json extension. When not provided, conflicts are written to standard error")
+	flag.Var(&overlays, "overlay", "path to an OpenAPI Overlay 1.0 file applied to the combined document, eg. with environment-specific servers or extensions. Can be provided multiple times - overlays are applied in order")
+	flattenAllOf = flag.Bool("flatten-allof", false, "merge allOf branches of every schema into the schema itself, unioning properties and required lists and intersecting constraints. Branches that are refs are merged by their content. Schemas with conflicting branches, eg. of different types, keep their allOf and are reported on standard error. False by default")
+	dedupe = flag.Bool("dedupe", false, "merge structurally equal components into one and replace inline objects equal to a component with a $ref. Inline schemas with a title found more than once are added to schemas. False by default")
+	dedupeNaming = flag.String("dedupe-naming", string(openapi.FirstName), "name kept when equal components are merged: first (alphabetically), shortest or longest")
+	dedupePrefer = flag.String("dedupe-prefer", "", "comma separated list of component names that are kept whenever they are among names of equal components, regardless of dedupe-naming")
 	flag.Parse()
 }
 
 func main() {
+	ctx, cancel := runContext()
+	defer cancel()
+
 	rootCfg := openapi.Config{
 		InlineLocalRefs:  *inlineLocalRefs,
 		InlineRemoteRefs: *inlineRemoteRefs,
 		KeepLocalRefs:    *keepLocalRefs,
+		Substitution:     substitution(),
+		Loader:           loader(),
+		Sandbox:          sandboxConfig(),
+		Parallelism:      *parallelism,
+		ParseCache:       parseCache(),
+	}
+
+	if *stream {
+		streamRootDocument(ctx, rootCfg)
+		return
+	}
+
+	if *watch {
+		watchRootDocument(ctx, rootCfg)
+		return
+	}
+
+	var rootDocument openapi.Document
+	if *inputFile != "" || len(mergeFiles) == 0 {
+		rootDocument = readRootDocument(ctx, rootCfg)
+	}
+
+	if len(mergeFiles) > 0 {
+		rootDocument = mergeDocuments(ctx, rootCfg, rootDocument)
+	}
+
+	for _, overlayFile := range overlays {
+		overlay, err := openapi.ReadOverlay(overlayFile)
+		if err != nil {
+			log.Fatalf("Could not read overlay %s: %v", overlayFile, err)
+		}
+
+		unmatched, err := rootDocument.ApplyOverlay(overlay)
+		if err != nil {
+			log.Fatalf("Error while applying overlay %s: %v", overlayFile, err)
+		}
+
+		for _, action := range unmatched {
+			fmt.Fprintf(os.Stderr, "Target %s of overlay %s matched nothing\n", action.Target, overlayFile)
+		}
+	}
+
+	filter := openapi.Filter{
+		IncludeTags:       splitList(*includeTags),
+		ExcludeTags:       splitList(*excludeTags),
+		IncludePaths:      splitList(*includePaths),
+		IncludeOperations: splitList(*includeOperations),
+	}
+	if !filter.Empty() {
+		err := rootDocument.Filter(filter)
+		if err != nil {
+			log.Fatalf("Error while filtering operations: %v", err)
+		}
+	}
+
+	if len(removeMarked) > 0 {
+		var markers []openapi.Marker
+		for _, marker := range removeMarked {
+			markers = append(markers, openapi.ParseMarker(marker))
+		}
+
+		rootDocument.RemoveMarked(markers...)
+	}
+
+	if *flattenAllOf {
+		conflicts := rootDocument.FlattenAllOf(openapi.FlattenConfig{ResolveRefs: true})
+		for _, conflict := range conflicts {
+			fmt.Fprintf(os.Stderr, "Could not flatten allOf: %s\n", conflict)
+		}
+	}
+
+	if *extractInline {
+		_, err := rootDocument.ExtractSchemas(openapi.ExtractConfig{NameTemplate: *extractNaming})
+		if err != nil {
+			log.Fatalf("Error while extracting inline schemas: %v", err)
+		}
+	}
+
+	if *dedupe {
+		dedupeCfg := openapi.DeduplicateConfig{
+			Naming:    openapi.NameSelection(*dedupeNaming),
+			Preferred: splitList(*dedupePrefer),
+		}
+
+		_, err := rootDocument.Deduplicate(dedupeCfg)
+		if err != nil {
+			log.Fatalf("Error while deduplicating objects: %v", err)
+		}
+	}
+
+	if *prune || *pruneDryRun {
+		prunedRefs, err := rootDocument.PruneComponents(*pruneDryRun)
+		if err != nil {
+			log.Fatalf("Error while looking for unreachable components: %v", err)
+		}
+
+		if *pruneDryRun {
+			for _, ref := range prunedRefs {
+				fmt.Println(ref)
+			}
+
+			return
+		}
+	}
+
+	writeOutput(ctx, rootDocument)
+}
+
+// writeOutput writes the document to the output file or standard output
+func writeOutput(ctx context.Context, rootDocument openapi.Document) {
+	if *outputFile != "" {
+		outputFilePath, err := filepath.Abs(*outputFile)
+		if err != nil {
+			log.Fatalf("Could not parse output file path: %v", err)
+		}
+
+		err = rootDocument.WriteFileContext(ctx, outputFilePath)
+		if err != nil {
+			log.Fatalf("Error while writing output to path %s: %v", outputFilePath, err)
+		}
+
+		fmt.Printf("Wrote output YAML file to %s", outputFilePath)
+	} else {
+		err := rootDocument.WriteContext(ctx, os.Stdout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not write yaml to standard output: %v", err)
+		}
+	}
+}
+
+// watchRootDocument writes the root document, then polls files it depends on and writes it again after reloading changed files
+func watchRootDocument(ctx context.Context, rootCfg openapi.Config) {
+	if *inputFile == "" || *prune || *pruneDryRun || len(mergeFiles) > 0 || len(overlays) > 0 || len(removeMarked) > 0 || *flattenAllOf || *extractInline || *dedupe || *inputArchive != "" || *gitRev != "" ||
+		*includeTags != "" || *excludeTags != "" || *includePaths != "" || *includeOperations != "" {
+		log.Fatalf("watch requires input-file and cannot be used with prune, filters, merge-file, overlay, flatten-allof, extract-inline, dedupe, input-archive or git-rev")
+	}
+
+	rootDocument := readRootDocument(ctx, rootCfg)
+	writeOutput(ctx, rootDocument)
+	modTimes := sourceModTimes(rootDocument, nil)
+
+	ticker := time.NewTicker(*watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var changed []string
+		current := sourceModTimes(rootDocument, modTimes)
+		for source, modTime := range current {
+			if previous, ok := modTimes[source]; ok && !modTime.Equal(previous) {
+				changed = append(changed, source)
+			}
+		}
+
+		modTimes = current
+		if len(changed) == 0 {
+			continue
+		}
+
+		start := time.Now()
+		err := rootDocument.ReloadContext(ctx, changed...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error while reloading %s: %v\n", strings.Join(changed, ", "), err)
+			continue
+		}
+
+		writeOutput(ctx, rootDocument)
+		fmt.Fprintf(os.Stderr, "Reloaded %s in %v\n", strings.Join(changed, ", "), time.Since(start))
+	}
+}
+
+// sourceModTimes returns modification times of files the document depends on. URLs are not watched, and files that cannot be read keep their previous times
+func sourceModTimes(rootDocument openapi.Document, previous map[string]time.Time) map[string]time.Time {
+	modTimes := make(map[string]time.Time)
+	for _, source := range rootDocument.Sources() {
+		if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+			continue
+		}
+
+		info, err := os.Stat(source)
+		if err != nil {
+			modTimes[source] = previous[source]
+			continue
+		}
+
+		modTimes[source] = info.ModTime()
+	}
+
+	return modTimes
+}
+
+// runContext returns a context canceled on interrupt or once the timeout is exceeded, when provided
+func runContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	if *timeout <= 0 {
+		return ctx, stop
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// substitution returns placeholders expansion configured by flags, or nil when none of the flags is provided
+func substitution() *openapi.Substitution {
+	if *valuesFile == "" && len(setValues) == 0 && !*expandEnv {
+		return nil
+	}
+
+	substitution := openapi.NewSubstitution(*expandEnv)
+	if *valuesFile != "" {
+		err := substitution.ReadValues(*valuesFile)
+		if err != nil {
+			log.Fatalf("Could not read values file %s: %v", *valuesFile, err)
+		}
+	}
+
+	for _, assignment := range setValues {
+		err := substitution.Set(assignment)
+		if err != nil {
+			log.Fatalf("Could not set value: %v", err)
+		}
+	}
+
+	return substitution
+}
+
+// parseCache returns a cache of parsed documents persisted in the parse cache directory, or nil when the directory is not provided
+func parseCache() *openapi.ParseCache {
+	if *parseCacheDir == "" {
+		return nil
+	}
+
+	return &openapi.ParseCache{Dir: *parseCacheDir}
+}
+
+// sandboxConfig returns a sandbox configured by flags, or nil when the sandbox is disabled
+func sandboxConfig() *openapi.Sandbox {
+	if !*sandbox {
+		return nil
+	}
+
+	return &openapi.Sandbox{
+		RootDir:             *sandboxRoot,
+		ForbidSymlinks:      *forbidSymlinks,
+		ForbidAbsolutePaths: *forbidAbsRefs,
+		AllowURLs:           *sandboxAllowURLs,
+		MaxFileSize:         *maxFileSize,
+		MaxFiles:            *maxFiles,
+		MaxRefDepth:         *maxRefDepth,
+	}
+}
+
+// loader returns a loader fetching documents referenced by URLs and reading files from the input archive or the git revision, or from the filesystem when neither is provided
+func loader() openapi.Loader {
+	httpLoader := openapi.HTTPLoader{
+		Timeout:      *httpTimeout,
+		AllowedHosts: splitList(*allowHosts),
+		CacheDir:     *cacheDir,
+		Offline:      *offline,
+	}
+
+	if *inputArchive != "" && *gitRev != "" {
+		log.Fatalf("Only one of input-archive and git-rev can be provided")
+	}
+
+	if *gitRev != "" {
+		repositoryPath := filepath.Dir(*inputFile)
+		if *inputFile == "" {
+			repositoryPath = "."
+		}
+
+		gitLoader, err := openapi.NewGitLoader(repositoryPath, *gitRev)
+		if err != nil {
+			log.Fatalf("Could not read revision %s: %v", *gitRev, err)
+		}
+
+		httpLoader.Fallback = gitLoader
 	}
 
+	if *inputArchive != "" {
+		archiveLoader, err := openapi.NewArchiveLoader(*inputArchive)
+		if err != nil {
+			log.Fatalf("Could not read input archive %s: %v", *inputArchive, err)
+		}
+
+		httpLoader.Fallback = archiveLoader
+	}
+
+	return httpLoader
+}
+
+// documentPath returns an absolute path of the file, unless the file is read from the input archive or it is an URL
+func documentPath(path string) (string, error) {
+	if *inputArchive != "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path, nil
+	}
+
+	return filepath.Abs(path)
+}
+
+// readRootDocument reads the root document from the input file or standard input and resolves its references
+func readRootDocument(ctx context.Context, rootCfg openapi.Config) openapi.Document {
 	rootDocument := openapi.NewDocument(rootCfg)
 	if *inputFile != "" {
-		inputFilePath, err := filepath.Abs(*inputFile)
+		inputFilePath, err := documentPath(*inputFile)
 		if err != nil {
 			log.Fatalf("Could not parse input file path: %v", err)
 		}
 
-		err = rootDocument.ReadFile(inputFilePath)
+		err = rootDocument.ReadFileContext(ctx, inputFilePath)
 		if err != nil {
 			log.Fatalf("Error while parsing the root document: %v", err)
 		}
 	} else {
-		err := rootDocument.Read(os.Stdin)
+		err := rootDocument.ReadContext(ctx, os.Stdin)
 		if err != nil {
 			log.Fatalf("Error while reading from standard input: %v", err)
 		}
@@ -65,27 +479,160 @@ func main() {
 		}
 	}
 
-	err := rootDocument.ResolveReferences()
+	err := rootDocument.ResolveReferencesContext(ctx)
 	if err != nil {
 		log.Fatalf("Error while resolving references in root document: %v", err)
 	}
 
-	if *outputFile != "" {
-		outputFilePath, err := filepath.Abs(*outputFile)
+	return rootDocument
+}
+
+// streamRootDocument reads the root document from the input file or standard input, resolves its references and writes it piece by piece
+func streamRootDocument(ctx context.Context, rootCfg openapi.Config) {
+	if *watch || *prune || *pruneDryRun || len(mergeFiles) > 0 || len(overlays) > 0 || len(removeMarked) > 0 || *flattenAllOf || *extractInline || *dedupe || *inputArchive != "" || *gitRev != "" ||
+		*includeTags != "" || *excludeTags != "" || *includePaths != "" || *includeOperations != "" {
+		log.Fatalf("stream cannot be used with watch, prune, filters, merge-file, overlay, flatten-allof, extract-inline, dedupe, input-archive or git-rev")
+	}
+
+	rootDocument := openapi.NewDocument(rootCfg)
+	input := os.Stdin
+	if *inputFile != "" {
+		inputFilePath, err := filepath.Abs(*inputFile)
 		if err != nil {
-			log.Fatalf("Could not parse output file path: %v", err)
+			log.Fatalf("Could not parse input file path: %v", err)
 		}
 
-		err = rootDocument.WriteFile(outputFilePath)
+		input, err = os.Open(inputFilePath)
 		if err != nil {
-			log.Fatalf("Error while writing output to path %s: %v", outputFilePath, err)
+			log.Fatalf("Error while opening the root document: %v", err)
 		}
+		defer input.Close()
 
-		fmt.Printf("Wrote output YAML file to %s", outputFilePath)
+		rootDocument.SetRefDirectory(filepath.Dir(inputFilePath))
+		rootDocument.FileName = filepath.Base(inputFilePath)
+	} else if *refDirectory != "" {
+		rootDocument.SetRefDirectory(*refDirectory)
 	} else {
-		err := rootDocument.Write(os.Stdout)
+		pwdRefDir, err := os.Getwd()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Could not write yaml to standard output: %v", err)
+			log.Fatalf("Could not set reference directory to current working directory: %v", err)
+		}
+
+		rootDocument.SetRefDirectory(pwdRefDir)
+	}
+
+	if *outputFile == "" {
+		err := rootDocument.ResolveStreamContext(ctx, input, os.Stdout)
+		if err != nil {
+			log.Fatalf("Error while streaming the root document: %v", err)
+		}
+
+		return
+	}
+
+	outputFilePath, err := filepath.Abs(*outputFile)
+	if err != nil {
+		log.Fatalf("Could not parse output file path: %v", err)
+	}
+
+	output, err := os.OpenFile(outputFilePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(0777))
+	if err != nil {
+		log.Fatalf("Error while writing output to path %s: %v", outputFilePath, err)
+	}
+
+	err = rootDocument.ResolveStreamContext(ctx, input, output)
+	closeErr := output.Close()
+	if err != nil {
+		log.Fatalf("Error while streaming the root document: %v", err)
+	} else if closeErr != nil {
+		log.Fatalf("Error while writing output to path %s: %v", outputFilePath, closeErr)
+	}
+
+	fmt.Printf("Wrote output YAML file to %s", outputFilePath)
+}
+
+// mergeDocuments merges the root document, when it has been read, with documents of merge-file flags and reports conflicts
+func mergeDocuments(ctx context.Context, rootCfg openapi.Config, rootDocument openapi.Document) openapi.Document {
+	var sources []openapi.MergeSource
+	if rootDocument.Root != nil {
+		sources = append(sources, openapi.MergeSource{Document: rootDocument})
+	}
+
+	for _, mergeFile := range mergeFiles {
+		path, prefix := mergeFile, ""
+		if idx := strings.LastIndex(mergeFile, mergePrefixSeparator); idx >= 0 {
+			path, prefix = mergeFile[:idx], mergeFile[idx+1:]
 		}
+
+		documentFilePath, err := documentPath(path)
+		if err != nil {
+			log.Fatalf("Could not parse merge file path: %v", err)
+		}
+
+		document, err := openapi.ParseDocumentContext(ctx, rootCfg, documentFilePath)
+		if err != nil {
+			log.Fatalf("Error while parsing document %s: %v", documentFilePath, err)
+		}
+
+		sources = append(sources, openapi.MergeSource{Document: document, PathPrefix: prefix})
 	}
+
+	mergeCfg := openapi.MergeConfig{
+		OnPathConflict: openapi.PathConflictPolicy(*onPathConflict),
+	}
+	merged, conflicts, mergeErr := openapi.Merge(mergeCfg, sources...)
+
+	err := writeMergeReport(conflicts)
+	if err != nil {
+		log.Fatalf("Could not write merge report: %v", err)
+	}
+
+	if mergeErr != nil {
+		log.Fatalf("Error while merging documents: %v", mergeErr)
+	}
+
+	return merged
+}
+
+// writeMergeReport writes conflicts to the merge-report file, as JSON when the file has .json extension, or to standard error when the file is not provided
+func writeMergeReport(conflicts []openapi.MergeConflict) error {
+	if *mergeReport == "" {
+		for _, conflict := range conflicts {
+			fmt.Fprintf(os.Stderr, "Merge conflict: %s\n", conflict)
+		}
+
+		return nil
+	}
+
+	var report bytes.Buffer
+	if strings.EqualFold(filepath.Ext(*mergeReport), ".json") {
+		if conflicts == nil {
+			conflicts = []openapi.MergeConflict{}
+		}
+
+		encoder := json.NewEncoder(&report)
+		encoder.SetIndent("", "  ")
+		err := encoder.Encode(conflicts)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, conflict := range conflicts {
+			fmt.Fprintln(&report, conflict)
+		}
+	}
+
+	return ioutil.WriteFile(*mergeReport, report.Bytes(), os.FileMode(0644))
+}
+
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
 }
@@ -1,5 +1,5 @@
 module github.com/sarpt/openapi-utils
 
-go 1.13
+go 1.16
 
 require gopkg.in/yaml.v2 v2.2.8
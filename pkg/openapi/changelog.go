@@ -0,0 +1,217 @@
+package openapi
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+// ChangelogFormat is an output format of a changelog rendered with a built-in template
+type ChangelogFormat string
+
+const (
+	// MarkdownChangelog renders a changelog as Markdown
+	MarkdownChangelog ChangelogFormat = "markdown"
+	// HTMLChangelog renders a changelog as an HTML fragment
+	HTMLChangelog ChangelogFormat = "html"
+)
+
+// ChangelogKinds lists kinds of changes in the order of changelog sections
+var ChangelogKinds = []ChangeKind{Added, Changed, Deprecated, Removed}
+
+// Template renders a changelog. Both text/template and html/template templates satisfy it
+type Template interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// Changelog groups changes into sections by their kind, and inside of the sections by endpoints and components
+type Changelog struct {
+	Title    string
+	Sections []ChangelogSection
+}
+
+// ChangelogSection holds changes of a single kind, eg. all additions
+type ChangelogSection struct {
+	Kind       ChangeKind
+	Title      string
+	Endpoints  []ChangelogItem
+	Components []ChangelogItem
+}
+
+// ChangelogItem holds changes of a single endpoint or component.
+// Changes are empty when the item as a whole has been added, removed or deprecated.
+type ChangelogItem struct {
+	Name     string
+	Breaking bool
+	Changes  []Change
+}
+
+// NewChangelog groups changes into a changelog. Sections without changes are omitted
+func NewChangelog(title string, changes []Change) Changelog {
+	changelog := Changelog{
+		Title: title,
+	}
+
+	for _, kind := range ChangelogKinds {
+		section := ChangelogSection{
+			Kind:  kind,
+			Title: strings.ToUpper(string(kind[:1])) + string(kind[1:]),
+		}
+
+		endpoints := make(map[string]*ChangelogItem)
+		components := make(map[string]*ChangelogItem)
+		for _, change := range changes {
+			if change.Kind != kind {
+				continue
+			}
+
+			items, name := endpoints, change.Endpoint
+			if name == "" {
+				items, name = components, change.Component
+			}
+
+			item, ok := items[name]
+			if !ok {
+				item = &ChangelogItem{Name: name}
+				items[name] = item
+			}
+
+			item.Breaking = item.Breaking || change.Level == Breaking
+			if change.Location != "" || change.Extension != "" {
+				item.Changes = append(item.Changes, change)
+			}
+		}
+
+		section.Endpoints = sortedChangelogItems(endpoints)
+		section.Components = sortedChangelogItems(components)
+		if len(section.Endpoints) > 0 || len(section.Components) > 0 {
+			changelog.Sections = append(changelog.Sections, section)
+		}
+	}
+
+	return changelog
+}
+
+// Write renders the changelog with the built-in template for the format
+func (c Changelog) Write(w io.Writer, format ChangelogFormat) error {
+	tmpl, err := ChangelogTemplate(format)
+	if err != nil {
+		return err
+	}
+
+	return c.WriteTemplate(w, tmpl)
+}
+
+// WriteTemplate renders the changelog with a custom template, which receives the Changelog as data
+func (c Changelog) WriteTemplate(w io.Writer, tmpl Template) error {
+	return tmpl.Execute(w, c)
+}
+
+// ChangelogTemplate returns a built-in template for the format
+func ChangelogTemplate(format ChangelogFormat) (Template, error) {
+	switch format {
+	case MarkdownChangelog:
+		return texttemplate.New(string(format)).Parse(markdownChangelogTemplate)
+	case HTMLChangelog:
+		return htmltemplate.New(string(format)).Parse(htmlChangelogTemplate)
+	default:
+		return nil, fmt.Errorf("unsupported changelog format %s", format)
+	}
+}
+
+// ParseChangelogTemplate parses a custom template. Templates of files with .html or .htm extension are parsed as HTML templates, escaping the content
+func ParseChangelogTemplate(path string) (Template, error) {
+	extension := strings.ToLower(filepath.Ext(path))
+	if extension == ".html" || extension == ".htm" {
+		return htmltemplate.ParseFiles(path)
+	}
+
+	return texttemplate.ParseFiles(path)
+}
+
+func sortedChangelogItems(items map[string]*ChangelogItem) []ChangelogItem {
+	var sorted []ChangelogItem
+	for _, item := range items {
+		sorted = append(sorted, *item)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	return sorted
+}
+
+const markdownChangelogTemplate = `# {{ .Title }}
+{{ if not .Sections }}
+No changes
+{{ end -}}
+{{ range .Sections }}
+## {{ .Title }}
+{{ if .Endpoints }}
+### Endpoints
+
+{{ range .Endpoints -}}
+- ` + "`{{ .Name }}`" + `{{ if .Breaking }} **(breaking)**{{ end }}
+{{- range .Changes }}
+  - {{ if .Location }}` + "`{{ .Location }}`" + `: {{ end }}{{ .Message }}
+{{- end }}
+{{ end -}}
+{{ end -}}
+{{ if .Components }}
+### Components
+
+{{ range .Components -}}
+- ` + "`{{ .Name }}`" + `
+{{- range .Changes }}
+  - {{ if .Location }}` + "`{{ .Location }}`" + `: {{ end }}{{ .Message }}
+{{- end }}
+{{ end -}}
+{{ end -}}
+{{ end -}}
+`
+
+const htmlChangelogTemplate = `<h1>{{ .Title }}</h1>
+{{- if not .Sections }}
+<p>No changes</p>
+{{- end }}
+{{- range .Sections }}
+<h2>{{ .Title }}</h2>
+{{- if .Endpoints }}
+<h3>Endpoints</h3>
+<ul>
+{{- range .Endpoints }}
+  <li><code>{{ .Name }}</code>{{ if .Breaking }} <strong>(breaking)</strong>{{ end }}
+  {{- if .Changes }}
+    <ul>
+    {{- range .Changes }}
+      <li>{{ if .Location }}<code>{{ .Location }}</code>: {{ end }}{{ .Message }}</li>
+    {{- end }}
+    </ul>
+  {{- end }}
+  </li>
+{{- end }}
+</ul>
+{{- end }}
+{{- if .Components }}
+<h3>Components</h3>
+<ul>
+{{- range .Components }}
+  <li><code>{{ .Name }}</code>
+  {{- if .Changes }}
+    <ul>
+    {{- range .Changes }}
+      <li>{{ if .Location }}<code>{{ .Location }}</code>: {{ end }}{{ .Message }}</li>
+    {{- end }}
+    </ul>
+  {{- end }}
+  </li>
+{{- end }}
+</ul>
+{{- end }}
+{{- end }}
+`
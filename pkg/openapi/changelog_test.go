@@ -0,0 +1,99 @@
+package openapi
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var changelogChanges = []Change{
+	{Level: NonBreaking, Kind: Added, Endpoint: "POST /orders", Message: "endpoint added"},
+	{Level: Breaking, Kind: Changed, Endpoint: "GET /orders", Location: "parameters/query/limit", Message: "query parameter 'limit' became required"},
+	{Level: NonBreaking, Kind: Changed, Endpoint: "GET /orders", Location: "responses/404", Message: "response '404' added"},
+	{Level: Informational, Kind: Added, Component: "schemas/Money", Message: "component added"},
+	{Level: Informational, Kind: Deprecated, Endpoint: "GET /legacy", Message: "endpoint deprecated"},
+}
+
+func TestNewChangelog(t *testing.T) {
+	changelog := NewChangelog("Changes", changelogChanges)
+
+	var kinds []ChangeKind
+	for _, section := range changelog.Sections {
+		kinds = append(kinds, section.Kind)
+	}
+	if !reflect.DeepEqual(kinds, []ChangeKind{Added, Changed, Deprecated}) {
+		t.Fatalf("expected sections only for kinds with changes, got %v", kinds)
+	}
+
+	added := changelog.Sections[0]
+	if len(added.Endpoints) != 1 || added.Endpoints[0].Name != "POST /orders" || len(added.Endpoints[0].Changes) != 0 {
+		t.Errorf("expected an added endpoint without details, got %+v", added.Endpoints)
+	}
+	if len(added.Components) != 1 || added.Components[0].Name != "schemas/Money" {
+		t.Errorf("expected an added component, got %+v", added.Components)
+	}
+
+	changed := changelog.Sections[1]
+	if len(changed.Endpoints) != 1 || !changed.Endpoints[0].Breaking || len(changed.Endpoints[0].Changes) != 2 {
+		t.Errorf("expected changes grouped by a breaking endpoint, got %+v", changed.Endpoints)
+	}
+}
+
+func TestChangelogWrite(t *testing.T) {
+	changelog := NewChangelog("Changes", changelogChanges[:3])
+
+	var markdown bytes.Buffer
+	if err := changelog.Write(&markdown, MarkdownChangelog); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "# Changes\n" +
+		"\n## Added\n\n### Endpoints\n\n- `POST /orders`\n" +
+		"\n## Changed\n\n### Endpoints\n\n- `GET /orders` **(breaking)**\n" +
+		"  - `parameters/query/limit`: query parameter 'limit' became required\n" +
+		"  - `responses/404`: response '404' added\n"
+	if markdown.String() != expected {
+		t.Errorf("expected markdown:\n%s\ngot:\n%s", expected, markdown.String())
+	}
+
+	var empty bytes.Buffer
+	if err := NewChangelog("Changes", nil).Write(&empty, MarkdownChangelog); err != nil || empty.String() != "# Changes\n\nNo changes\n" {
+		t.Errorf("expected an empty changelog, got %q (%v)", empty.String(), err)
+	}
+
+	var html bytes.Buffer
+	if err := NewChangelog("<Changes>", nil).Write(&html, HTMLChangelog); err != nil || html.String() != "<h1>&lt;Changes&gt;</h1>\n<p>No changes</p>\n" {
+		t.Errorf("expected an escaped HTML changelog, got %q (%v)", html.String(), err)
+	}
+
+	if err := changelog.Write(&bytes.Buffer{}, ChangelogFormat("pdf")); err == nil {
+		t.Errorf("expected an error for an unsupported format")
+	}
+}
+
+func TestParseChangelogTemplate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"changelog.txt":  "{{ .Title }}: {{ len .Sections }}",
+		"changelog.html": "<p>{{ .Title }}</p>",
+	})
+
+	for file, expected := range map[string]string{
+		"changelog.txt":  "<b>: 3",
+		"changelog.html": "<p>&lt;b&gt;</p>",
+	} {
+		tmpl, err := ParseChangelogTemplate(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", file, err)
+		}
+
+		var out bytes.Buffer
+		if err := NewChangelog("<b>", changelogChanges).WriteTemplate(&out, tmpl); err != nil {
+			t.Fatalf("%s: unexpected error: %v", file, err)
+		}
+
+		if out.String() != expected {
+			t.Errorf("%s: expected %q, got %q", file, expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package openapi
+
+import (
+	"context"
+	"io"
+)
+
+const (
+	// contextChunkSize is the size of chunks written by contextWriter, so the context is checked between chunks of large documents
+	contextChunkSize = 64 * 1024
+)
+
+// contextReader stops reading with the error of the context once the context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	err := r.ctx.Err()
+	if err != nil {
+		return 0, err
+	}
+
+	return r.r.Read(p)
+}
+
+// contextWriter writes in chunks, stopping with the error of the context once the context is done
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (w contextWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		err := w.ctx.Err()
+		if err != nil {
+			return written, err
+		}
+
+		end := written + contextChunkSize
+		if end > len(p) {
+			end = len(p)
+		}
+
+		n, err := w.w.Write(p[written:end])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
@@ -0,0 +1,167 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// cancelingWriter cancels the context after the first write
+type cancelingWriter struct {
+	cancel context.CancelFunc
+	buffer bytes.Buffer
+}
+
+func (w *cancelingWriter) Write(p []byte) (int, error) {
+	w.cancel()
+	return w.buffer.Write(p)
+}
+
+func TestWriteFileContext(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"openapi.yaml": "previous content\n",
+	})
+	path := filepath.Join(dir, "openapi.yaml")
+	err := os.Chmod(path, os.FileMode(0600))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := parseYAML(t, "openapi: 3.0.0\ninfo:\n  title: Pets\n")
+	err = doc.WriteFileContext(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != documentYAML(t, doc) {
+		t.Errorf("expected the file to be replaced with the document, got:\n%s", data)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != os.FileMode(0600) {
+		t.Errorf("expected the mode of the file to be kept, got %v", info.Mode().Perm())
+	}
+
+	assertNoTemporaryFiles(t, dir)
+}
+
+func TestWriteFileContextCanceled(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"openapi.yaml": "previous content\n",
+	})
+	path := filepath.Join(dir, "openapi.yaml")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := parseYAML(t, "openapi: 3.0.0\n").WriteFileContext(ctx, path)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "previous content\n" {
+		t.Errorf("expected the existing file to be left untouched, got:\n%s", data)
+	}
+
+	assertNoTemporaryFiles(t, dir)
+}
+
+func assertNoTemporaryFiles(t *testing.T, dir string) {
+	t.Helper()
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("expected only the written file in the directory, got %d files", len(entries))
+	}
+}
+
+func TestContextWriter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := &cancelingWriter{cancel: cancel}
+	written, err := contextWriter{ctx: ctx, w: w}.Write(make([]byte, 3*contextChunkSize))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+
+	if written != contextChunkSize || w.buffer.Len() != contextChunkSize {
+		t.Errorf("expected writing to stop after the first chunk, got %d bytes written", written)
+	}
+}
+
+func TestReadFileContextCanceled(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"openapi.yaml": "openapi: 3.0.0\n",
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, cfg := range []Config{{}, {Sandbox: &Sandbox{}}} {
+		doc := NewDocument(cfg)
+		err := doc.ReadFileContext(ctx, filepath.Join(dir, "openapi.yaml"))
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v, got %v", context.Canceled, err)
+		}
+	}
+}
+
+func TestResolveReferencesContextCanceledInFlight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requested := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requested)
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	go func() {
+		<-requested
+		cancel()
+	}()
+
+	doc := parseYAML(t, sandboxRoot(server.URL+"/common.yaml#/components/schemas/Pet"))
+	doc.Cfg.Loader = HTTPLoader{}
+	err := doc.ResolveReferencesContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the fetch to be canceled with %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestResolveStreamContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := NewDocument(Config{Loader: streamFiles}).ResolveStreamContext(ctx, strings.NewReader(streamSpec), &bytes.Buffer{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
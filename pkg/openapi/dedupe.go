@@ -0,0 +1,474 @@
+package openapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// NameSelection decides which name is kept when structurally equal components are merged
+type NameSelection string
+
+const (
+	// FirstName keeps the alphabetically first name
+	FirstName NameSelection = "first"
+	// ShortestName keeps the shortest name, alphabetically first among equally long ones
+	ShortestName NameSelection = "shortest"
+	// LongestName keeps the longest name, alphabetically first among equally long ones
+	LongestName NameSelection = "longest"
+
+	schemasItem     = "schemas"
+	emptyObjectYAML = "{}\n"
+)
+
+// DeduplicateConfig specifies how duplicates are found and merged
+type DeduplicateConfig struct {
+	// Naming selects the name kept from names of structurally equal components
+	Naming NameSelection
+	// Preferred names are kept whenever they are among names of structurally equal components, before Naming is applied
+	Preferred []string
+	// IncludeScalars deduplicates schemas without properties, enum or composition too, eg. "type: string".
+	// By default such schemas are kept as they are, since they are equal by accident rather than by design.
+	IncludeScalars bool
+}
+
+// Duplicate describes a component that duplicates were merged into
+type Duplicate struct {
+	// Ref is a local reference to the kept component
+	Ref string
+	// Duplicates hold local references to removed components that were equal to the kept one
+	Duplicates []string
+	// InlineCopies is a number of inline objects that were equal to the kept component and have been replaced with a $ref
+	InlineCopies int
+}
+
+// duplicateSite is a place in the document holding an inline object, which can be replaced with a $ref
+type duplicateSite struct {
+	componentType string
+	object        interface{}
+	hash          string
+	replace       func(value reflect.Value)
+}
+
+// Deduplicate finds structurally equal components and inline objects by canonical hashing and merges them.
+// Equal components of the same type are merged into one, selected by the config, and references to the removed ones are changed to point to the kept one.
+// Inline objects equal to a component are replaced with a $ref to it. Inline schemas with a title that are found more than once, eg. inlined from remote refs, are added to schemas named by their title.
+// Security schemes are never merged, since security requirements refer to them by name.
+// Should be run on a document with resolved references, since remote references are not followed.
+func (doc Document) Deduplicate(cfg DeduplicateConfig) ([]Duplicate, error) {
+	var duplicates []Duplicate
+
+	componentTypes := componentTypesByObjectType()
+
+	// merging changes references, which can make components referencing merged ones equal too, hence merging is repeated until nothing changes
+	allRenamed := make(map[string]string)
+	for {
+		renamed, err := doc.mergeComponents(cfg, &duplicates)
+		if err != nil {
+			return duplicates, err
+		}
+
+		if len(renamed) == 0 {
+			break
+		}
+
+		err = doc.changeReferences(renamed)
+		if err != nil {
+			return duplicates, err
+		}
+
+		for from, to := range renamed {
+			allRenamed[from] = to
+		}
+	}
+	duplicates = foldDuplicates(duplicates, allRenamed)
+
+	refsByHash := make(map[string]string)
+	for _, componentType := range componentTypes {
+		for name, component := range doc.components(componentType) {
+			hash, ok := cfg.hash(component)
+			if ok {
+				refsByHash[hash] = componentReference(componentType, name)
+			}
+		}
+	}
+
+	var sites []duplicateSite
+	collectDuplicateSites(reflect.ValueOf(doc.Root).Elem(), cfg, componentTypes, refsByHash, &sites, make(map[uintptr]bool))
+
+	doc.promoteInlineSchemas(sites, refsByHash)
+
+	inlineCopies := make(map[string]int)
+	for _, site := range sites {
+		ref, ok := refsByHash[site.hash]
+		if !ok {
+			continue
+		}
+
+		replacement := reflect.New(reflect.TypeOf(site.object).Elem())
+		replacement.Elem().FieldByName(Ref).SetString(ref)
+		site.replace(replacement)
+		inlineCopies[ref]++
+	}
+
+	for i := range duplicates {
+		duplicates[i].InlineCopies = inlineCopies[duplicates[i].Ref]
+		delete(inlineCopies, duplicates[i].Ref)
+	}
+
+	for ref, count := range inlineCopies {
+		duplicates = append(duplicates, Duplicate{Ref: ref, InlineCopies: count})
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		return duplicates[i].Ref < duplicates[j].Ref
+	})
+
+	return duplicates, nil
+}
+
+// mergeComponents removes components equal to other components of the same type and returns a map of references to removed components to references of kept ones
+func (doc Document) mergeComponents(cfg DeduplicateConfig, duplicates *[]Duplicate) (map[string]string, error) {
+	renamed := make(map[string]string)
+
+	for _, componentType := range componentTypesByObjectType() {
+		components := doc.components(componentType)
+
+		namesByHash := make(map[string][]string)
+		for name, component := range components {
+			hash, ok := cfg.hash(component)
+			if ok {
+				namesByHash[hash] = append(namesByHash[hash], name)
+			}
+		}
+
+		for _, names := range namesByHash {
+			if len(names) < 2 {
+				continue
+			}
+
+			kept := cfg.selectName(names)
+			duplicate := Duplicate{Ref: componentReference(componentType, kept)}
+			for _, name := range names {
+				if name == kept {
+					continue
+				}
+
+				ref := componentReference(componentType, name)
+				duplicate.Duplicates = append(duplicate.Duplicates, ref)
+				renamed[ref] = duplicate.Ref
+
+				object, err := doc.getOrCreateObjectByPath(ref, false)
+				if err != nil {
+					return renamed, err
+				}
+
+				err = object.Unset()
+				if err != nil {
+					return renamed, err
+				}
+			}
+
+			sort.Strings(duplicate.Duplicates)
+			*duplicates = append(*duplicates, duplicate)
+		}
+	}
+
+	return renamed, nil
+}
+
+// foldDuplicates merges duplicates of components that were removed in later rounds of merging into duplicates of components that were kept
+func foldDuplicates(duplicates []Duplicate, renamed map[string]string) []Duplicate {
+	var folded []Duplicate
+	foldedByRef := make(map[string]int)
+	for _, duplicate := range duplicates {
+		ref := duplicate.Ref
+		for seen := 0; renamed[ref] != "" && seen < len(renamed); seen++ {
+			ref = renamed[ref]
+		}
+
+		idx, ok := foldedByRef[ref]
+		if !ok {
+			idx = len(folded)
+			foldedByRef[ref] = idx
+			folded = append(folded, Duplicate{Ref: ref})
+		}
+
+		folded[idx].Duplicates = append(folded[idx].Duplicates, duplicate.Duplicates...)
+	}
+
+	for i := range folded {
+		sort.Strings(folded[i].Duplicates)
+	}
+
+	return folded
+}
+
+// changeReferences changes references (and references to objects nested in them) according to the map of old references to new ones
+func (doc Document) changeReferences(renamed map[string]string) error {
+	if len(renamed) == 0 {
+		return nil
+	}
+
+	rootObject, err := OasObjectByName(&doc, RootItem, false)
+	if err != nil {
+		return err
+	}
+
+	refs, err := rootObject.references()
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		if !isLocalReference(ref.path) {
+			continue
+		}
+
+		for from, to := range renamed {
+			if ref.path != from && !strings.HasPrefix(ref.path, from+"/") {
+				continue
+			}
+
+			err = ref.object.ChangeRefPath(to + strings.TrimPrefix(ref.path, from))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// promoteInlineSchemas adds inline schemas with a title that are found more than once to schemas, so their copies can be replaced with a $ref
+func (doc Document) promoteInlineSchemas(sites []duplicateSite, refsByHash map[string]string) {
+	counts := make(map[string]int)
+	for _, site := range sites {
+		if _, ok := refsByHash[site.hash]; ok || site.componentType != schemasItem {
+			continue
+		}
+
+		if schema := site.object.(*Schema); schema.Title != "" {
+			counts[site.hash]++
+		}
+	}
+
+	for _, site := range sites {
+		if counts[site.hash] < 2 {
+			continue
+		}
+
+		if _, ok := refsByHash[site.hash]; ok {
+			continue
+		}
+
+		name := doc.uniqueComponentName(schemasItem, site.object.(*Schema).Title)
+		if doc.Root.Components == nil {
+			doc.Root.Components = &Components{}
+		}
+		if doc.Root.Components.Schemas == nil {
+			doc.Root.Components.Schemas = make(map[string]*Schema)
+		}
+
+		doc.Root.Components.Schemas[name] = site.object.(*Schema)
+		refsByHash[site.hash] = componentReference(schemasItem, name)
+	}
+}
+
+// uniqueComponentName returns a name that can be used for a new component of the type, adding a numeric suffix to the candidate when it is already taken
+func (doc Document) uniqueComponentName(componentType, candidate string) string {
+	name := componentName(candidate)
+	for i := 2; doc.componentExists(componentType, name); i++ {
+		name = fmt.Sprintf("%s%d", componentName(candidate), i)
+	}
+
+	return name
+}
+
+// components returns components of the type keyed by name
+func (doc Document) components(componentType string) map[string]interface{} {
+	components := make(map[string]interface{})
+	if doc.Root.Components == nil {
+		return components
+	}
+
+	value := reflect.ValueOf(doc.Root.Components).Elem()
+	fieldName, err := getFieldNameByTag(componentType, value)
+	if err != nil {
+		return components
+	}
+
+	componentsOfType := value.FieldByName(fieldName)
+	for _, key := range componentsOfType.MapKeys() {
+		if component := componentsOfType.MapIndex(key); !component.IsNil() {
+			components[key.String()] = component.Interface()
+		}
+	}
+
+	return components
+}
+
+// collectDuplicateSites walks the value and collects inline objects of component types, which are candidates for a $ref.
+// Objects equal to a component are not walked further, since they are replaced as a whole. Components themselves are not candidates, but their descendants are.
+func collectDuplicateSites(value reflect.Value, cfg DeduplicateConfig, componentTypes map[reflect.Type]string, refsByHash map[string]string, sites *[]duplicateSite, visited map[uintptr]bool) {
+	walkChild := func(child reflect.Value, replace func(value reflect.Value)) {
+		componentType, ok := componentTypes[child.Type()]
+		if !ok || child.IsNil() {
+			collectDuplicateSites(child, cfg, componentTypes, refsByHash, sites, visited)
+			return
+		}
+
+		if hash, ok := cfg.hash(child.Interface()); ok {
+			*sites = append(*sites, duplicateSite{
+				componentType: componentType,
+				object:        child.Interface(),
+				hash:          hash,
+				replace:       replace,
+			})
+
+			if _, ok := refsByHash[hash]; ok {
+				return
+			}
+		}
+
+		collectDuplicateSites(child, cfg, componentTypes, refsByHash, sites, visited)
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() || value.Elem().Kind() != reflect.Struct || visited[value.Pointer()] {
+			return
+		}
+		visited[value.Pointer()] = true
+
+		collectDuplicateSites(value.Elem(), cfg, componentTypes, refsByHash, sites, visited)
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Field(i)
+			if value.Type() == reflect.TypeOf(Components{}) {
+				// components are not replaced themselves, only their descendants
+				collectComponentsSites(field, cfg, componentTypes, refsByHash, sites, visited)
+			} else {
+				walkChild(field, field.Set)
+			}
+		}
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			key := key
+			walkChild(value.MapIndex(key), func(replacement reflect.Value) {
+				value.SetMapIndex(key, replacement)
+			})
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			element := value.Index(i)
+			walkChild(element, element.Set)
+		}
+	}
+}
+
+func collectComponentsSites(componentsOfType reflect.Value, cfg DeduplicateConfig, componentTypes map[reflect.Type]string, refsByHash map[string]string, sites *[]duplicateSite, visited map[uintptr]bool) {
+	if componentsOfType.Kind() != reflect.Map {
+		return
+	}
+
+	for _, key := range componentsOfType.MapKeys() {
+		collectDuplicateSites(componentsOfType.MapIndex(key), cfg, componentTypes, refsByHash, sites, visited)
+	}
+}
+
+// componentTypesByObjectType maps pointer types of objects that can be components to keys of components holding them. Security schemes are skipped, since they are referenced by name.
+func componentTypesByObjectType() map[reflect.Type]string {
+	componentTypes := make(map[reflect.Type]string)
+
+	componentsType := reflect.TypeOf(Components{})
+	for i := 0; i < componentsType.NumField(); i++ {
+		field := componentsType.Field(i)
+		componentType := getYamlKeyFromField(field)
+		if field.Type.Kind() != reflect.Map || field.Name == ExtensionsField || componentType == securitySchemesItem {
+			continue
+		}
+
+		componentTypes[field.Type.Elem()] = componentType
+	}
+
+	return componentTypes
+}
+
+// hash returns a canonical hash of the object. Returned bool is false when the object should not be deduplicated: it is a $ref, it is empty or it is a scalar schema not included by the config.
+// Hashes are computed from YAML representation, which has map keys sorted and struct fields in a fixed order.
+func (cfg DeduplicateConfig) hash(object interface{}) (string, bool) {
+	if _, ok := objectRef(object); ok {
+		return "", false
+	}
+
+	if schema, ok := object.(*Schema); ok && !cfg.IncludeScalars && !isStructuredSchema(schema) {
+		return "", false
+	}
+
+	data, err := yaml.Marshal(object)
+	if err != nil || string(data) == emptyObjectYAML {
+		return "", false
+	}
+
+	sum := sha256.Sum256(append([]byte(reflect.TypeOf(object).String()), data...))
+	return hex.EncodeToString(sum[:]), true
+}
+
+func (cfg DeduplicateConfig) selectName(names []string) string {
+	sort.Strings(names)
+
+	for _, preferred := range cfg.Preferred {
+		for _, name := range names {
+			if name == preferred {
+				return name
+			}
+		}
+	}
+
+	selected := names[0]
+	for _, name := range names[1:] {
+		switch cfg.Naming {
+		case ShortestName:
+			if len(name) < len(selected) {
+				selected = name
+			}
+		case LongestName:
+			if len(name) > len(selected) {
+				selected = name
+			}
+		}
+	}
+
+	return selected
+}
+
+func isStructuredSchema(schema *Schema) bool {
+	return len(schema.Properties) > 0 || len(schema.Enum) > 0 || len(schema.AllOf) > 0 || len(schema.OneOf) > 0 || len(schema.AnyOf) > 0
+}
+
+// componentName turns a text, eg. a title, into a component name consisting of letters, digits, dots, dashes and underscores
+func componentName(text string) string {
+	var name strings.Builder
+	upper := true
+	for _, r := range text {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_':
+			if upper {
+				name.WriteString(strings.ToUpper(string(r)))
+			} else {
+				name.WriteRune(r)
+			}
+			upper = false
+		default:
+			upper = true
+		}
+	}
+
+	return name.String()
+}
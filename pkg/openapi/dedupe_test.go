@@ -0,0 +1,174 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+const duplicatesSpec = `openapi: 3.0.0
+info:
+  title: shop
+  version: "1"
+paths:
+  /orders:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/OrderList'
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                amount:
+                  type: number
+                currency:
+                  type: string
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/CreatedOrder'
+components:
+  schemas:
+    Money:
+      type: object
+      properties:
+        amount:
+          type: number
+        currency:
+          type: string
+    Price:
+      type: object
+      properties:
+        amount:
+          type: number
+        currency:
+          type: string
+    Order:
+      type: object
+      properties:
+        total:
+          $ref: '#/components/schemas/Money'
+    CreatedOrder:
+      type: object
+      properties:
+        total:
+          $ref: '#/components/schemas/Price'
+    OrderList:
+      type: array
+      items:
+        $ref: '#/components/schemas/CreatedOrder'
+    Id:
+      type: string
+    Code:
+      type: string
+`
+
+func TestDeduplicate(t *testing.T) {
+	doc := parseYAML(t, duplicatesSpec)
+
+	duplicates, err := doc.Deduplicate(DeduplicateConfig{Naming: FirstName})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Duplicate{
+		{Ref: "#/components/schemas/CreatedOrder", Duplicates: []string{"#/components/schemas/Order"}},
+		{Ref: "#/components/schemas/Money", Duplicates: []string{"#/components/schemas/Price"}, InlineCopies: 1},
+	}
+	if !reflect.DeepEqual(duplicates, expected) {
+		t.Errorf("expected duplicates %+v, got %+v", expected, duplicates)
+	}
+
+	if schemas := sortedKeys(doc.Root.Components.Schemas); !reflect.DeepEqual(schemas, []string{"Code", "CreatedOrder", "Id", "Money", "OrderList"}) {
+		t.Errorf("expected duplicates to be removed and scalar schemas to be kept, got %v", schemas)
+	}
+
+	if ref := doc.Root.Components.Schemas["CreatedOrder"].Properties["total"].Ref; ref != "#/components/schemas/Money" {
+		t.Errorf("expected references to the removed component to be changed, got %s", ref)
+	}
+
+	if ref := doc.Root.Paths["/orders"].Post.RequestBody.Content["application/json"].Schema.Ref; ref != "#/components/schemas/Money" {
+		t.Errorf("expected the inline copy to be replaced with a reference, got %q", ref)
+	}
+}
+
+func TestDeduplicateConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		cfg      DeduplicateConfig
+		expected []string
+	}{
+		{"shortest", DeduplicateConfig{Naming: ShortestName}, []string{"Code", "Id", "Money", "Order", "OrderList"}},
+		{"longest", DeduplicateConfig{Naming: LongestName}, []string{"Code", "CreatedOrder", "Id", "Money", "OrderList"}},
+		{"preferred", DeduplicateConfig{Naming: FirstName, Preferred: []string{"Order", "Price"}}, []string{"Code", "Id", "Order", "OrderList", "Price"}},
+		{"scalars", DeduplicateConfig{Naming: FirstName, IncludeScalars: true}, []string{"Code", "CreatedOrder", "Money", "OrderList"}},
+	} {
+		doc := parseYAML(t, duplicatesSpec)
+		if _, err := doc.Deduplicate(tc.cfg); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+
+		if schemas := sortedKeys(doc.Root.Components.Schemas); !reflect.DeepEqual(schemas, tc.expected) {
+			t.Errorf("%s: expected schemas %v, got %v", tc.name, tc.expected, schemas)
+		}
+	}
+}
+
+func TestDeduplicatePromotesInlineSchemas(t *testing.T) {
+	doc := parseYAML(t, `openapi: 3.0.0
+info:
+  title: shop
+  version: "1"
+paths:
+  /orders:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                title: order item
+                type: object
+                properties:
+                  sku:
+                    type: string
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              title: order item
+              type: object
+              properties:
+                sku:
+                  type: string
+      responses:
+        "201":
+          description: created
+`)
+
+	duplicates, err := doc.Deduplicate(DeduplicateConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Duplicate{{Ref: "#/components/schemas/OrderItem", InlineCopies: 2}}
+	if !reflect.DeepEqual(duplicates, expected) {
+		t.Errorf("expected duplicates %+v, got %+v", expected, duplicates)
+	}
+
+	if ref := doc.Root.Paths["/orders"].Get.Responses["200"].Content["application/json"].Schema.Ref; ref != "#/components/schemas/OrderItem" {
+		t.Errorf("expected the inline schema to be replaced with a reference, got %q", ref)
+	}
+}
@@ -0,0 +1,616 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeLevel classifies the impact of a change on consumers of the API
+type ChangeLevel string
+
+const (
+	// Breaking change can break existing consumers, eg. a removed endpoint or a new required request property
+	Breaking ChangeLevel = "breaking"
+	// NonBreaking change extends the API in a backwards compatible manner, eg. a new endpoint or a new optional parameter
+	NonBreaking ChangeLevel = "non-breaking"
+	// Informational change does not affect consumers directly, eg. a deprecation or a change of an extension
+	Informational ChangeLevel = "info"
+)
+
+// ChangeKind describes what happened to the changed element
+type ChangeKind string
+
+const (
+	// Added element is present only in the new document
+	Added ChangeKind = "added"
+	// Removed element is present only in the old document
+	Removed ChangeKind = "removed"
+	// Changed element is present in both documents, but differs
+	Changed ChangeKind = "changed"
+	// Deprecated element is present in both documents, but is deprecated only in the new one
+	Deprecated ChangeKind = "deprecated"
+)
+
+// Change is a single difference between two documents.
+// Endpoint is set for changes of operations (eg. "GET /orders"), Component is set for changes of components (eg. "schemas/Money").
+// Location describes the changed element inside of the endpoint or the component.
+type Change struct {
+	Level     ChangeLevel `json:"level"`
+	Kind      ChangeKind  `json:"kind"`
+	Endpoint  string      `json:"endpoint,omitempty"`
+	Component string      `json:"component,omitempty"`
+	Location  string      `json:"location,omitempty"`
+	// Extension is set for changes of a specification extension value, eg. x-internal
+	Extension string `json:"extension,omitempty"`
+	Message   string `json:"message"`
+}
+
+// maxDerefs limits following of references that point to other references, guarding against reference cycles
+const maxDerefs = 32
+
+// direction specifies whether a schema describes data sent by the consumer or returned to it, since the same change can be breaking in one direction and backwards compatible in the other
+type direction int
+
+const (
+	requestDirection direction = iota
+	responseDirection
+	// neutralDirection is used for components, which are classified on the endpoints that use them
+	neutralDirection
+)
+
+// Diff compares two documents and classifies every change of paths, operations and components.
+// Documents should have their references resolved; local references are followed during comparison.
+func Diff(old, new *Document) []Change {
+	d := differ{
+		old:     *old,
+		new:     *new,
+		visited: make(map[schemaPair]bool),
+	}
+
+	d.extensions(scope{}, nil, old.Root.Extensions, new.Root.Extensions)
+	d.paths(old.Root.Paths, new.Root.Paths, "")
+	d.paths(old.Root.Webhooks, new.Root.Webhooks, "webhook ")
+	d.components(old.Root.Components, new.Root.Components)
+
+	return d.changes
+}
+
+// HasBreaking checks whether any of the changes is breaking
+func HasBreaking(changes []Change) bool {
+	for _, change := range changes {
+		if change.Level == Breaking {
+			return true
+		}
+	}
+
+	return false
+}
+
+type schemaPair struct {
+	old       *Schema
+	new       *Schema
+	direction direction
+}
+
+type differ struct {
+	old     Document
+	new     Document
+	changes []Change
+	visited map[schemaPair]bool
+}
+
+// scope identifies the endpoint or the component that changes are reported for
+type scope struct {
+	endpoint  string
+	component string
+}
+
+func (d *differ) add(s scope, level ChangeLevel, kind ChangeKind, location []string, message string, args ...interface{}) {
+	d.changes = append(d.changes, Change{
+		Level:     level,
+		Kind:      kind,
+		Endpoint:  s.endpoint,
+		Component: s.component,
+		Location:  strings.Join(location, pathSeparator),
+		Message:   fmt.Sprintf(message, args...),
+	})
+}
+
+func (d *differ) paths(oldPaths, newPaths map[string]*PathItem, prefix string) {
+	for _, path := range unionKeys(oldPaths, newPaths) {
+		oldItem := d.derefPathItem(d.old, oldPaths[path])
+		newItem := d.derefPathItem(d.new, newPaths[path])
+		if oldItem != nil && newItem != nil {
+			d.extensions(scope{endpoint: prefix + path}, nil, oldItem.Extensions, newItem.Extensions)
+		}
+
+		for _, method := range HTTPMethods {
+			var oldOperation, newOperation *Operation
+			if oldItem != nil {
+				oldOperation = oldItem.Operation(method)
+			}
+			if newItem != nil {
+				newOperation = newItem.Operation(method)
+			}
+
+			s := scope{endpoint: fmt.Sprintf("%s%s %s", prefix, strings.ToUpper(method), path)}
+			switch {
+			case oldOperation == nil && newOperation == nil:
+				continue
+			case oldOperation == nil:
+				d.add(s, NonBreaking, Added, nil, "endpoint added")
+			case newOperation == nil:
+				d.add(s, Breaking, Removed, nil, "endpoint removed")
+			default:
+				d.operation(s, oldItem, newItem, oldOperation, newOperation)
+			}
+		}
+	}
+}
+
+func (d *differ) operation(s scope, oldItem, newItem *PathItem, oldOperation, newOperation *Operation) {
+	if !oldOperation.Deprecated && newOperation.Deprecated {
+		d.add(s, Informational, Deprecated, nil, "endpoint deprecated")
+	}
+
+	if oldOperation.OperationID != newOperation.OperationID {
+		d.add(s, Informational, Changed, []string{"operationId"}, "operationId changed from '%s' to '%s'", oldOperation.OperationID, newOperation.OperationID)
+	}
+
+	d.extensions(s, nil, oldOperation.Extensions, newOperation.Extensions)
+	d.parameters(s, d.operationParameters(d.old, oldItem, oldOperation), d.operationParameters(d.new, newItem, newOperation))
+	d.requestBody(s, d.derefRequestBody(d.old, oldOperation.RequestBody), d.derefRequestBody(d.new, newOperation.RequestBody))
+	d.responses(s, oldOperation.Responses, newOperation.Responses)
+}
+
+// operationParameters returns parameters of the operation along with path item parameters that are not overridden, keyed by location and name
+func (d *differ) operationParameters(doc Document, pathItem *PathItem, operation *Operation) map[string]*Parameter {
+	parameters := make(map[string]*Parameter)
+	for _, parameter := range pathItem.Parameters {
+		parameter = d.derefParameter(doc, parameter)
+		if parameter != nil {
+			parameters[parameterKey(parameter)] = parameter
+		}
+	}
+
+	for _, parameter := range operation.Parameters {
+		parameter = d.derefParameter(doc, parameter)
+		if parameter != nil {
+			parameters[parameterKey(parameter)] = parameter
+		}
+	}
+
+	return parameters
+}
+
+func (d *differ) parameters(s scope, oldParameters, newParameters map[string]*Parameter) {
+	for _, key := range unionKeys(oldParameters, newParameters) {
+		oldParameter := oldParameters[key]
+		newParameter := newParameters[key]
+		location := []string{"parameters", key}
+
+		switch {
+		case oldParameter == nil && newParameter.Required:
+			d.add(s, Breaking, Added, location, "required %s parameter '%s' added", newParameter.In, newParameter.Name)
+		case oldParameter == nil:
+			d.add(s, NonBreaking, Added, location, "optional %s parameter '%s' added", newParameter.In, newParameter.Name)
+		case newParameter == nil:
+			d.add(s, Breaking, Removed, location, "%s parameter '%s' removed", oldParameter.In, oldParameter.Name)
+		default:
+			if !oldParameter.Required && newParameter.Required {
+				d.add(s, Breaking, Changed, location, "%s parameter '%s' became required", newParameter.In, newParameter.Name)
+			} else if oldParameter.Required && !newParameter.Required {
+				d.add(s, NonBreaking, Changed, location, "%s parameter '%s' became optional", newParameter.In, newParameter.Name)
+			}
+
+			if !oldParameter.Deprecated && newParameter.Deprecated {
+				d.add(s, Informational, Deprecated, location, "%s parameter '%s' deprecated", newParameter.In, newParameter.Name)
+			}
+
+			d.extensions(s, location, oldParameter.Extensions, newParameter.Extensions)
+			d.schema(s, append(location, "schema"), oldParameter.Schema, newParameter.Schema, requestDirection)
+		}
+	}
+}
+
+func (d *differ) requestBody(s scope, oldBody, newBody *RequestBody) {
+	location := []string{"requestBody"}
+
+	switch {
+	case oldBody == nil && newBody == nil:
+		return
+	case oldBody == nil && newBody.Required:
+		d.add(s, Breaking, Added, location, "required request body added")
+		return
+	case oldBody == nil:
+		d.add(s, NonBreaking, Added, location, "optional request body added")
+		return
+	case newBody == nil:
+		d.add(s, Breaking, Removed, location, "request body removed")
+		return
+	}
+
+	if !oldBody.Required && newBody.Required {
+		d.add(s, Breaking, Changed, location, "request body became required")
+	} else if oldBody.Required && !newBody.Required {
+		d.add(s, NonBreaking, Changed, location, "request body became optional")
+	}
+
+	d.content(s, location, oldBody.Content, newBody.Content, requestDirection)
+}
+
+func (d *differ) responses(s scope, oldResponses, newResponses map[string]*Response) {
+	for _, code := range unionKeys(oldResponses, newResponses) {
+		oldResponse := d.derefResponse(d.old, oldResponses[code])
+		newResponse := d.derefResponse(d.new, newResponses[code])
+		location := []string{"responses", code}
+
+		switch {
+		case oldResponse == nil && newResponse == nil:
+			continue
+		case oldResponse == nil:
+			d.add(s, NonBreaking, Added, location, "response '%s' added", code)
+		case newResponse == nil:
+			d.add(s, Breaking, Removed, location, "response '%s' removed", code)
+		default:
+			d.extensions(s, location, oldResponse.Extensions, newResponse.Extensions)
+			d.content(s, location, oldResponse.Content, newResponse.Content, responseDirection)
+		}
+	}
+}
+
+func (d *differ) content(s scope, location []string, oldContent, newContent map[string]*MediaType, dir direction) {
+	for _, mediaType := range unionKeys(oldContent, newContent) {
+		oldMediaType := oldContent[mediaType]
+		newMediaType := newContent[mediaType]
+		mediaTypeLocation := append(location[:len(location):len(location)], "content", mediaType)
+
+		switch {
+		case oldMediaType == nil && newMediaType == nil:
+			continue
+		case oldMediaType == nil:
+			d.add(s, NonBreaking, Added, mediaTypeLocation, "media type '%s' added", mediaType)
+		case newMediaType == nil:
+			d.add(s, Breaking, Removed, mediaTypeLocation, "media type '%s' removed", mediaType)
+		default:
+			d.schema(s, append(mediaTypeLocation, "schema"), oldMediaType.Schema, newMediaType.Schema, dir)
+		}
+	}
+}
+
+func (d *differ) schema(s scope, location []string, oldSchema, newSchema *Schema, dir direction) {
+	oldSchema = d.derefSchema(d.old, oldSchema)
+	newSchema = d.derefSchema(d.new, newSchema)
+	if oldSchema == nil || newSchema == nil {
+		if oldSchema != newSchema {
+			d.add(s, levelFor(dir, Breaking, Breaking), Changed, location, "schema %s", presence(newSchema != nil))
+		}
+
+		return
+	}
+
+	// only schemas currently being compared are tracked, so recursive schemas terminate while the same schemas used in many places are reported in each of them
+	pair := schemaPair{old: oldSchema, new: newSchema, direction: dir}
+	if d.visited[pair] {
+		return
+	}
+	d.visited[pair] = true
+	defer delete(d.visited, pair)
+
+	if oldSchema.Type != newSchema.Type {
+		d.add(s, levelFor(dir, Breaking, Breaking), Changed, location, "type changed from '%s' to '%s'", oldSchema.Type, newSchema.Type)
+	}
+
+	if oldSchema.Format != newSchema.Format {
+		d.add(s, levelFor(dir, Breaking, Breaking), Changed, location, "format changed from '%s' to '%s'", oldSchema.Format, newSchema.Format)
+	}
+
+	if !oldSchema.Nullable && newSchema.Nullable {
+		d.add(s, levelFor(dir, NonBreaking, Breaking), Changed, location, "became nullable")
+	} else if oldSchema.Nullable && !newSchema.Nullable {
+		d.add(s, levelFor(dir, Breaking, NonBreaking), Changed, location, "is no longer nullable")
+	}
+
+	if !oldSchema.Deprecated && newSchema.Deprecated {
+		d.add(s, Informational, Deprecated, location, "deprecated")
+	}
+
+	d.enum(s, location, oldSchema.Enum, newSchema.Enum, dir)
+	d.constraints(s, location, oldSchema, newSchema, dir)
+	d.properties(s, location, oldSchema, newSchema, dir)
+	d.extensions(s, location, oldSchema.Extensions, newSchema.Extensions)
+
+	if oldSchema.Items != nil || newSchema.Items != nil {
+		d.schema(s, append(location, "items"), oldSchema.Items, newSchema.Items, dir)
+	}
+
+	d.composition(s, append(location, "allOf"), oldSchema.AllOf, newSchema.AllOf, dir)
+	d.composition(s, append(location, "oneOf"), oldSchema.OneOf, newSchema.OneOf, dir)
+	d.composition(s, append(location, "anyOf"), oldSchema.AnyOf, newSchema.AnyOf, dir)
+}
+
+func (d *differ) enum(s scope, location []string, oldEnum, newEnum []string, dir direction) {
+	if len(oldEnum) == 0 && len(newEnum) == 0 {
+		return
+	}
+
+	if len(oldEnum) == 0 {
+		d.add(s, levelFor(dir, Breaking, NonBreaking), Changed, location, "enum introduced: %s", strings.Join(newEnum, ", "))
+		return
+	}
+
+	if len(newEnum) == 0 {
+		d.add(s, levelFor(dir, NonBreaking, Breaking), Changed, location, "enum removed")
+		return
+	}
+
+	removed := difference(oldEnum, newEnum)
+	if len(removed) > 0 {
+		d.add(s, levelFor(dir, Breaking, NonBreaking), Changed, location, "enum values removed: %s", strings.Join(removed, ", "))
+	}
+
+	added := difference(newEnum, oldEnum)
+	if len(added) > 0 {
+		d.add(s, levelFor(dir, NonBreaking, Breaking), Changed, location, "enum values added: %s", strings.Join(added, ", "))
+	}
+}
+
+// constraints compares limits of values. Narrowing limits breaks consumers sending data, widening limits breaks consumers receiving data.
+func (d *differ) constraints(s scope, location []string, oldSchema, newSchema *Schema, dir direction) {
+	limits := []struct {
+		name     string
+		old      int64
+		new      int64
+		narrower func(old, new int64) bool
+	}{
+		{"maximum", int64(oldSchema.Maximum), int64(newSchema.Maximum), upperLimitNarrower},
+		{"minimum", int64(oldSchema.Minimum), int64(newSchema.Minimum), lowerLimitNarrower},
+		{"maxLength", int64(oldSchema.MaxLength), int64(newSchema.MaxLength), upperLimitNarrower},
+		{"minLength", int64(oldSchema.MinLength), int64(newSchema.MinLength), lowerLimitNarrower},
+		{"maxItems", int64(oldSchema.MaxItems), int64(newSchema.MaxItems), upperLimitNarrower},
+		{"minItems", int64(oldSchema.MinItems), int64(newSchema.MinItems), lowerLimitNarrower},
+		{"maxProperties", int64(oldSchema.MaxProperties), int64(newSchema.MaxProperties), upperLimitNarrower},
+		{"minProperties", int64(oldSchema.MinProperties), int64(newSchema.MinProperties), lowerLimitNarrower},
+	}
+
+	for _, limit := range limits {
+		if limit.old == limit.new {
+			continue
+		}
+
+		if limit.narrower(limit.old, limit.new) {
+			d.add(s, levelFor(dir, Breaking, NonBreaking), Changed, append(location, limit.name), "%s narrowed from %d to %d", limit.name, limit.old, limit.new)
+		} else {
+			d.add(s, levelFor(dir, NonBreaking, Breaking), Changed, append(location, limit.name), "%s widened from %d to %d", limit.name, limit.old, limit.new)
+		}
+	}
+
+	if oldSchema.Pattern != newSchema.Pattern {
+		d.add(s, levelFor(dir, Breaking, Breaking), Changed, append(location, "pattern"), "pattern changed from '%s' to '%s'", oldSchema.Pattern, newSchema.Pattern)
+	}
+}
+
+func (d *differ) properties(s scope, location []string, oldSchema, newSchema *Schema, dir direction) {
+	for _, name := range unionKeys(oldSchema.Properties, newSchema.Properties) {
+		oldProperty := oldSchema.Properties[name]
+		newProperty := newSchema.Properties[name]
+		propertyLocation := append(location[:len(location):len(location)], "properties", name)
+		oldRequired := containsAny([]string{name}, oldSchema.Required)
+		newRequired := containsAny([]string{name}, newSchema.Required)
+
+		switch {
+		case oldProperty == nil && newProperty == nil:
+			continue
+		case oldProperty == nil && newRequired:
+			d.add(s, levelFor(dir, Breaking, NonBreaking), Added, propertyLocation, "required property '%s' added", name)
+		case oldProperty == nil:
+			d.add(s, NonBreaking, Added, propertyLocation, "optional property '%s' added", name)
+		case newProperty == nil:
+			d.add(s, levelFor(dir, Breaking, Breaking), Removed, propertyLocation, "property '%s' removed", name)
+		default:
+			if !oldRequired && newRequired {
+				d.add(s, levelFor(dir, Breaking, NonBreaking), Changed, propertyLocation, "property '%s' became required", name)
+			} else if oldRequired && !newRequired {
+				d.add(s, levelFor(dir, NonBreaking, Breaking), Changed, propertyLocation, "property '%s' became optional", name)
+			}
+
+			d.schema(s, propertyLocation, oldProperty, newProperty, dir)
+		}
+	}
+}
+
+func (d *differ) composition(s scope, location []string, oldSchemas, newSchemas []*Schema, dir direction) {
+	if len(oldSchemas) != len(newSchemas) {
+		d.add(s, levelFor(dir, Breaking, Breaking), Changed, location, "number of subschemas changed from %d to %d", len(oldSchemas), len(newSchemas))
+	}
+
+	for idx := 0; idx < len(oldSchemas) && idx < len(newSchemas); idx++ {
+		d.schema(s, append(location[:len(location):len(location)], fmt.Sprint(idx)), oldSchemas[idx], newSchemas[idx], dir)
+	}
+}
+
+// extensions reports changes of specification extensions (x- fields) as informational, leaving their interpretation to the consumer of changes
+func (d *differ) extensions(s scope, location []string, oldExtensions, newExtensions map[string]interface{}) {
+	for _, name := range unionKeys(oldExtensions, newExtensions) {
+		if !strings.HasPrefix(name, extensionPrefix) {
+			continue
+		}
+
+		oldValue, oldOk := oldExtensions[name]
+		newValue, newOk := newExtensions[name]
+
+		var change Change
+		switch {
+		case !oldOk:
+			change = Change{Kind: Added, Message: fmt.Sprintf("extension %s added with value '%v'", name, newValue)}
+		case !newOk:
+			change = Change{Kind: Removed, Message: fmt.Sprintf("extension %s removed", name)}
+		case !reflect.DeepEqual(oldValue, newValue):
+			change = Change{Kind: Changed, Message: fmt.Sprintf("extension %s changed from '%v' to '%v'", name, oldValue, newValue)}
+		default:
+			continue
+		}
+
+		change.Level = Informational
+		change.Endpoint = s.endpoint
+		change.Component = s.component
+		change.Location = strings.Join(location, pathSeparator)
+		change.Extension = name
+		d.changes = append(d.changes, change)
+	}
+}
+
+// components reports added and removed components, and changes of component schemas.
+// Changes of components are informational, since their impact is classified on the endpoints that use them.
+func (d *differ) components(oldComponents, newComponents *Components) {
+	if oldComponents == nil {
+		oldComponents = &Components{}
+	}
+	if newComponents == nil {
+		newComponents = &Components{}
+	}
+
+	oldValue := reflect.ValueOf(oldComponents).Elem()
+	newValue := reflect.ValueOf(newComponents).Elem()
+	componentsType := oldValue.Type()
+	for i := 0; i < oldValue.NumField(); i++ {
+		if oldValue.Field(i).Kind() != reflect.Map || componentsType.Field(i).Name == ExtensionsField {
+			continue
+		}
+
+		componentType := getYamlKeyFromField(componentsType.Field(i))
+		for _, name := range unionKeys(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			key := reflect.ValueOf(name)
+			oldComponent := oldValue.Field(i).MapIndex(key)
+			newComponent := newValue.Field(i).MapIndex(key)
+			s := scope{component: fmt.Sprintf("%s/%s", componentType, name)}
+
+			switch {
+			case !oldComponent.IsValid() && !newComponent.IsValid():
+				continue
+			case !oldComponent.IsValid():
+				d.add(s, Informational, Added, nil, "component added")
+			case !newComponent.IsValid():
+				d.add(s, Informational, Removed, nil, "component removed")
+			default:
+				oldSchema, isSchema := oldComponent.Interface().(*Schema)
+				if isSchema {
+					d.schema(s, nil, oldSchema, newComponent.Interface().(*Schema), neutralDirection)
+				}
+			}
+		}
+	}
+}
+
+func (d *differ) derefPathItem(doc Document, item *PathItem) *PathItem {
+	for seen := 0; item != nil && item.Ref != "" && seen < maxDerefs; seen++ {
+		object, _ := doc.localObject(item.Ref)
+		item, _ = object.(*PathItem)
+	}
+
+	return item
+}
+
+func (d *differ) derefParameter(doc Document, parameter *Parameter) *Parameter {
+	for seen := 0; parameter != nil && parameter.Ref != "" && seen < maxDerefs; seen++ {
+		object, _ := doc.localObject(parameter.Ref)
+		parameter, _ = object.(*Parameter)
+	}
+
+	return parameter
+}
+
+func (d *differ) derefRequestBody(doc Document, body *RequestBody) *RequestBody {
+	for seen := 0; body != nil && body.Ref != "" && seen < maxDerefs; seen++ {
+		object, _ := doc.localObject(body.Ref)
+		body, _ = object.(*RequestBody)
+	}
+
+	return body
+}
+
+func (d *differ) derefResponse(doc Document, response *Response) *Response {
+	for seen := 0; response != nil && response.Ref != "" && seen < maxDerefs; seen++ {
+		object, _ := doc.localObject(response.Ref)
+		response, _ = object.(*Response)
+	}
+
+	return response
+}
+
+func (d *differ) derefSchema(doc Document, schema *Schema) *Schema {
+	for seen := 0; schema != nil && schema.Ref != "" && seen < maxDerefs; seen++ {
+		object, _ := doc.localObject(schema.Ref)
+		schema, _ = object.(*Schema)
+	}
+
+	return schema
+}
+
+// levelFor selects the level of a change depending on the direction of the schema. In neutral direction every change is informational.
+func levelFor(dir direction, request ChangeLevel, response ChangeLevel) ChangeLevel {
+	switch dir {
+	case requestDirection:
+		return request
+	case responseDirection:
+		return response
+	default:
+		return Informational
+	}
+}
+
+func upperLimitNarrower(old, new int64) bool {
+	return old == 0 || (new != 0 && new < old)
+}
+
+func lowerLimitNarrower(old, new int64) bool {
+	return new > old
+}
+
+func parameterKey(parameter *Parameter) string {
+	return fmt.Sprintf("%s/%s", parameter.In, parameter.Name)
+}
+
+func presence(present bool) string {
+	if present {
+		return "added"
+	}
+
+	return "removed"
+}
+
+// unionKeys returns sorted, unique string keys of provided maps
+func unionKeys(maps ...interface{}) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		value := reflect.ValueOf(m)
+		if value.Kind() != reflect.Map {
+			continue
+		}
+
+		for _, key := range value.MapKeys() {
+			if !seen[key.String()] {
+				seen[key.String()] = true
+				keys = append(keys, key.String())
+			}
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// difference returns values present in the first list and missing from the second one
+func difference(values []string, other []string) []string {
+	var missing []string
+	for _, value := range values {
+		if !containsAny([]string{value}, other) {
+			missing = append(missing, value)
+		}
+	}
+
+	return missing
+}
@@ -0,0 +1,131 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ChangesFormat is an output format of changes found by Diff
+type ChangesFormat string
+
+const (
+	// TextChanges lists changes, one per line
+	TextChanges ChangesFormat = "text"
+	// JSONChanges is a JSON list of changes
+	JSONChanges ChangesFormat = "json"
+	// MarkdownChanges groups changes by level into Markdown tables
+	MarkdownChanges ChangesFormat = "markdown"
+)
+
+// ChangeLevels lists change levels from the most to the least severe
+var ChangeLevels = []ChangeLevel{Breaking, NonBreaking, Informational}
+
+// WriteChanges writes changes to the writer in the specified format
+func WriteChanges(w io.Writer, changes []Change, format ChangesFormat) error {
+	switch format {
+	case TextChanges:
+		return writeTextChanges(w, changes)
+	case JSONChanges:
+		return writeJSONChanges(w, changes)
+	case MarkdownChanges:
+		return writeMarkdownChanges(w, changes)
+	default:
+		return fmt.Errorf("unsupported changes format %s", format)
+	}
+}
+
+// String returns a single line description of the change
+func (c Change) String() string {
+	var subject []string
+	if c.Endpoint != "" {
+		subject = append(subject, c.Endpoint)
+	}
+	if c.Component != "" {
+		subject = append(subject, c.Component)
+	}
+	if c.Location != "" {
+		subject = append(subject, c.Location)
+	}
+
+	if len(subject) == 0 {
+		return fmt.Sprintf("[%s] %s", c.Level, c.Message)
+	}
+
+	return fmt.Sprintf("[%s] %s: %s", c.Level, strings.Join(subject, " "), c.Message)
+}
+
+func writeTextChanges(w io.Writer, changes []Change) error {
+	var out strings.Builder
+	for _, change := range changes {
+		out.WriteString(change.String())
+		out.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func writeJSONChanges(w io.Writer, changes []Change) error {
+	if changes == nil {
+		changes = []Change{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(changes)
+}
+
+func writeMarkdownChanges(w io.Writer, changes []Change) error {
+	var out strings.Builder
+
+	out.WriteString("# API changes\n")
+	if len(changes) == 0 {
+		out.WriteString("\nNo changes\n")
+	}
+
+	titles := map[ChangeLevel]string{
+		Breaking:      "Breaking changes",
+		NonBreaking:   "Non-breaking changes",
+		Informational: "Informational changes",
+	}
+
+	for _, level := range ChangeLevels {
+		var levelChanges []Change
+		for _, change := range changes {
+			if change.Level == level {
+				levelChanges = append(levelChanges, change)
+			}
+		}
+
+		if len(levelChanges) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&out, "\n## %s\n\n| Endpoint / component | Location | Change |\n| --- | --- | --- |\n", titles[level])
+		for _, change := range levelChanges {
+			subject := change.Endpoint
+			if subject == "" {
+				subject = change.Component
+			}
+
+			fmt.Fprintf(&out, "| %s | %s | %s |\n", markdownCode(subject), markdownCode(change.Location), markdownEscape(change.Message))
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func markdownCode(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("`%s`", markdownEscape(value))
+}
+
+func markdownEscape(value string) string {
+	return strings.ReplaceAll(value, "|", "\\|")
+}
@@ -0,0 +1,193 @@
+package openapi
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const diffSpec = `openapi: 3.0.0
+info:
+  title: shop
+  version: "1"
+paths:
+  /orders:
+    get:
+      operationId: listOrders
+      parameters:
+      - name: limit
+        in: query
+        schema:
+          type: integer
+          maximum: 100
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Order'
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Order'
+      responses:
+        "201":
+          description: created
+components:
+  schemas:
+    Order:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: string
+        status:
+          type: string
+          enum: [new, paid]
+`
+
+func TestDiff(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		old      string
+		new      string
+		expected []string
+	}{
+		{
+			name:     "no changes",
+			expected: nil,
+		},
+		{
+			name: "endpoint removed and added",
+			old:  "  /orders:\n    get:",
+			new:  "  /orders:\n    options:",
+			expected: []string{
+				"[breaking] GET /orders: endpoint removed",
+				"[non-breaking] OPTIONS /orders: endpoint added",
+			},
+		},
+		{
+			name: "required parameter added",
+			old:  "        in: query\n",
+			new:  "        in: query\n        required: true\n",
+			expected: []string{
+				"[breaking] GET /orders parameters/query/limit: query parameter 'limit' became required",
+			},
+		},
+		{
+			name: "request limit narrowed",
+			old:  "          maximum: 100",
+			new:  "          maximum: 50",
+			expected: []string{
+				"[breaking] GET /orders parameters/query/limit/schema/maximum: maximum narrowed from 100 to 50",
+			},
+		},
+		{
+			name: "enum value added is breaking only for responses",
+			old:  "enum: [new, paid]",
+			new:  "enum: [new, paid, sent]",
+			expected: []string{
+				"[breaking] GET /orders responses/200/content/application/json/schema/items/properties/status: enum values added: sent",
+				"[non-breaking] POST /orders requestBody/content/application/json/schema/properties/status: enum values added: sent",
+				"[info] schemas/Order properties/status: enum values added: sent",
+			},
+		},
+		{
+			name: "required property added is breaking only for requests",
+			old:  "      required: [id]\n      properties:\n",
+			new:  "      required: [id, total]\n      properties:\n        total:\n          type: number\n",
+			expected: []string{
+				"[non-breaking] GET /orders responses/200/content/application/json/schema/items/properties/total: required property 'total' added",
+				"[breaking] POST /orders requestBody/content/application/json/schema/properties/total: required property 'total' added",
+				"[info] schemas/Order properties/total: required property 'total' added",
+			},
+		},
+		{
+			name: "deprecation and extensions are informational",
+			old:  "      operationId: listOrders\n",
+			new:  "      operationId: listOrders\n      deprecated: true\n      x-internal: true\n",
+			expected: []string{
+				"[info] GET /orders: endpoint deprecated",
+				"[info] GET /orders: extension x-internal added with value 'true'",
+			},
+		},
+		{
+			name: "extensions of the document and path items",
+			old:  "paths:\n  /orders:\n",
+			new:  "x-tenant: shop\npaths:\n  /orders:\n    x-owner: sales\n",
+			expected: []string{
+				"[info] extension x-tenant added with value 'shop'",
+				"[info] /orders: extension x-owner added with value 'sales'",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			newSpec := strings.Replace(diffSpec, tc.old, tc.new, 1)
+			if newSpec == diffSpec && tc.old != "" {
+				t.Fatalf("test case does not change the specification")
+			}
+
+			var changes []string
+			for _, change := range Diff(documentPtr(parseYAML(t, diffSpec)), documentPtr(parseYAML(t, newSpec))) {
+				changes = append(changes, change.String())
+			}
+
+			if !reflect.DeepEqual(changes, tc.expected) {
+				t.Errorf("expected changes:\n%s\ngot:\n%s", strings.Join(tc.expected, "\n"), strings.Join(changes, "\n"))
+			}
+		})
+	}
+}
+
+func TestHasBreaking(t *testing.T) {
+	if HasBreaking([]Change{{Level: NonBreaking}, {Level: Informational}}) {
+		t.Errorf("expected no breaking changes")
+	}
+
+	if !HasBreaking([]Change{{Level: NonBreaking}, {Level: Breaking}}) {
+		t.Errorf("expected breaking changes")
+	}
+}
+
+func TestWriteChanges(t *testing.T) {
+	changes := []Change{
+		{Level: Breaking, Kind: Removed, Endpoint: "GET /orders", Message: "endpoint removed"},
+		{Level: Informational, Kind: Added, Component: "schemas/Money", Location: "properties/a|b", Message: "component added"},
+	}
+
+	for format, expected := range map[ChangesFormat]string{
+		TextChanges: `[breaking] GET /orders: endpoint removed
+[info] schemas/Money properties/a|b: component added
+`,
+		MarkdownChanges: "# API changes\n" +
+			"\n## Breaking changes\n\n| Endpoint / component | Location | Change |\n| --- | --- | --- |\n" +
+			"| `GET /orders` |  | endpoint removed |\n" +
+			"\n## Informational changes\n\n| Endpoint / component | Location | Change |\n| --- | --- | --- |\n" +
+			"| `schemas/Money` | `properties/a\\|b` | component added |\n",
+	} {
+		var out bytes.Buffer
+		if err := WriteChanges(&out, changes, format); err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+
+		if out.String() != expected {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", format, expected, out.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := WriteChanges(&out, nil, JSONChanges); err != nil || out.String() != "[]\n" {
+		t.Errorf("expected an empty JSON list, got %q (%v)", out.String(), err)
+	}
+}
+
+func documentPtr(doc Document) *Document {
+	return &doc
+}
@@ -1,6 +1,7 @@
 package openapi
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 
 	yaml "gopkg.in/yaml.v2"
@@ -39,7 +41,10 @@ type Document struct {
 	RefDirectory        string
 	FileName            string
 	Root                *OpenAPI
-	ReferencedDocuments map[string]*Document
+	ReferencedDocuments *DocumentCache
+
+	// resolutions record references of the root document resolved so far, so they can be resolved again by Reload
+	resolutions *resolutionLog
 }
 
 // reference contains information about OpenAPI object that contains reference and path of reference
@@ -53,38 +58,78 @@ type Config struct {
 	InlineLocalRefs  bool
 	InlineRemoteRefs bool
 	KeepLocalRefs    bool
+	// PruneComponents removes components not reachable from paths, webhooks or security requirements after references are resolved
+	PruneComponents bool
+	// Substitution expands placeholders in the content of the document and documents referenced by it before parsing. Nil disables expansion
+	Substitution *Substitution
+	// Loader reads the document and documents referenced by it. OSLoader is used when nil
+	Loader Loader
+	// Sandbox limits documents that can be read while references are resolved. Nil disables the sandbox
+	Sandbox *Sandbox
+	// ParseCache keeps parsed documents, so documents that did not change are not parsed again. Nil disables the cache
+	ParseCache *ParseCache
+	// Parallelism limits the number of referenced documents loaded at the same time. DefaultParallelism is used when zero
+	Parallelism int
+
+	sandboxState *sandboxState
+	refDepth     int
 }
 
 // NewDocument constructs new Document instance
 func NewDocument(cfg Config) Document {
-	return Document{
+	if cfg.Sandbox != nil && cfg.sandboxState == nil {
+		cfg.sandboxState = newSandboxState()
+	}
+
+	document := Document{
 		Cfg:                 cfg,
 		Root:                &OpenAPI{},
-		ReferencedDocuments: make(map[string]*Document),
+		ReferencedDocuments: NewDocumentCache(),
 	}
+
+	if cfg.refDepth == 0 {
+		document.resolutions = &resolutionLog{}
+	}
+
+	return document
 }
 
 // ParseDocument takes path to the file that should be parsed and have it's references resolved
 func ParseDocument(cfg Config, path string) (Document, error) {
+	return ParseDocumentContext(context.Background(), cfg, path)
+}
+
+// ParseDocumentContext parses the file and resolves its references, stopping when the context is canceled or its deadline is exceeded
+func ParseDocumentContext(ctx context.Context, cfg Config, path string) (Document, error) {
 	referencedDocument := NewDocument(cfg)
 
-	err := referencedDocument.ReadFile(path)
+	err := referencedDocument.ReadFileContext(ctx, path)
 	if err != nil {
 		return Document{}, err
 	}
 
-	err = referencedDocument.ResolveReferences()
+	err = referencedDocument.ResolveReferencesContext(ctx)
 	return referencedDocument, err
 }
 
-// Parse unmarshalls the yaml content
+// Parse unmarshalls the yaml content, expanding placeholders first when substitution is configured. Content parsed before is taken from the parse cache
 func (doc Document) Parse(data []byte) error {
-	return yaml.Unmarshal(data, doc.Root)
+	data, err := doc.Cfg.Substitution.Expand(data)
+	if err != nil {
+		return err
+	}
+
+	return doc.Cfg.ParseCache.parse(data, doc.Root)
 }
 
 // Read takes a Reader and parses the content after encountering EOF
 func (doc Document) Read(r io.Reader) error {
-	data, err := ioutil.ReadAll(r)
+	return doc.ReadContext(context.Background(), r)
+}
+
+// ReadContext reads the content until EOF and parses it, stopping when the context is canceled or its deadline is exceeded
+func (doc Document) ReadContext(ctx context.Context, r io.Reader) error {
+	data, err := ioutil.ReadAll(contextReader{ctx: ctx, r: r})
 	if err != nil {
 		return err
 	}
@@ -92,38 +137,96 @@ func (doc Document) Read(r io.Reader) error {
 	return doc.Parse(data)
 }
 
-// ReadFile attempts to read & parse content of file Document points to
+// ReadFile attempts to read & parse content of file Document points to, using the loader of the config within limits of the sandbox
 func (doc *Document) ReadFile(path string) error {
-	data, err := ioutil.ReadFile(path)
+	return doc.ReadFileContext(context.Background(), path)
+}
+
+// ReadFileContext reads and parses the file, passing the context to the loader of the config
+func (doc *Document) ReadFileContext(ctx context.Context, path string) error {
+	doc.Cfg.setSandboxRoot(documentDirectory(path))
+
+	data, err := doc.Cfg.load(ctx, path)
 	if err != nil {
 		return err
 	}
 
-	doc.RefDirectory = filepath.Dir(path)
-	doc.FileName = filepath.Base(path)
+	doc.RefDirectory = documentDirectory(path)
+	doc.FileName = documentBase(path)
 
-	err = yaml.Unmarshal(data, doc.Root)
-	return err
+	return doc.Parse(data)
 }
 
 // WriteFile writes content of a document to a YAML file pointed by path
 func (doc Document) WriteFile(path string) error {
+	return doc.WriteFileContext(context.Background(), path)
+}
+
+// WriteFileContext writes content of the document to a temporary file in the directory of the YAML file, and renames it to the YAML file once the whole content is written.
+// When the context is done before that, the temporary file is removed and the existing YAML file is left untouched. The mode of the existing YAML file is kept
+func (doc Document) WriteFileContext(ctx context.Context, path string) error {
 	yaml, err := doc.YAML()
 	if err != nil {
 		return err
 	}
 
-	return ioutil.WriteFile(path, yaml, os.FileMode(0777))
+	err = ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	err = writeTempFile(ctx, file, yaml, mode)
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(file.Name())
+	}
+
+	return err
+}
+
+// writeTempFile writes the content to the temporary file and closes it, checking the context before the file can be renamed
+func writeTempFile(ctx context.Context, file *os.File, content []byte, mode os.FileMode) error {
+	_, err := contextWriter{ctx: ctx, w: file}.Write(content)
+	if err == nil {
+		err = file.Chmod(mode)
+	}
+
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	} else if closeErr != nil {
+		return closeErr
+	}
+
+	return ctx.Err()
 }
 
 // Write writes content of a document to a writer
 func (doc Document) Write(w io.Writer) error {
+	return doc.WriteContext(context.Background(), w)
+}
+
+// WriteContext writes content of the document to the writer, stopping when the context is canceled or its deadline is exceeded
+func (doc Document) WriteContext(ctx context.Context, w io.Writer) error {
 	yaml, err := doc.YAML()
 	if err != nil {
 		return err
 	}
 
-	_, err = w.Write(yaml)
+	_, err = contextWriter{ctx: ctx, w: w}.Write(yaml)
 	return err
 }
 
@@ -132,6 +235,11 @@ func (doc Document) YAML() ([]byte, error) {
 	return yaml.Marshal(doc.Root)
 }
 
+// path returns the path of the file of the document, or its reference directory when it was not read from a file
+func (doc Document) path() string {
+	return joinDocumentPath(doc.RefDirectory, doc.FileName)
+}
+
 // SetRefDirectory sets the directory which is used as root for refs relative paths resolution
 func (doc *Document) SetRefDirectory(dir string) {
 	doc.RefDirectory = dir
@@ -140,7 +248,21 @@ func (doc *Document) SetRefDirectory(dir string) {
 // ResolveReferences takes a document and tries to find and resolve all references.
 // After execution all elements that had not empty Ref properties have their contents replaced with referenced content.
 // References are first sorted before resolution/assignment due to use-case where local reference aliases remote one.
+// Referenced documents are loaded concurrently beforehand, unless LoadReferencedDocuments has already been called.
 func (doc Document) ResolveReferences() error {
+	return doc.ResolveReferencesContext(context.Background())
+}
+
+// ResolveReferencesContext resolves references of the document as ResolveReferences does, stopping when the context is canceled or its deadline is exceeded.
+// The context is passed to loaders of referenced documents, so eg. requests fetching them are canceled too.
+func (doc Document) ResolveReferencesContext(ctx context.Context) error {
+	if !doc.ReferencedDocuments.isLoaded() {
+		err := doc.LoadReferencedDocuments(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
 	rootObject, err := OasObjectByName(&doc, RootItem, false)
 	if err != nil {
 		return err
@@ -156,29 +278,38 @@ func (doc Document) ResolveReferences() error {
 	})
 
 	for _, ref := range refs {
-		err := doc.replaceReference(ref)
+		err := ctx.Err()
+		if err != nil {
+			return err
+		}
+
+		err = doc.replaceReference(ctx, ref)
 		if err != nil {
 			return err
 		}
 	}
 
-	return nil
+	if doc.Cfg.PruneComponents {
+		_, err = doc.PruneComponents(false)
+	}
+
+	return err
 }
 
-func (doc Document) replaceReference(ref reference) error { // method on reference instead on document? 'isLocal' could be calculated at creation time, or reference could be an interface that 'local' and 'remote' satisfy by implementing "replace". To be considered
+func (doc Document) replaceReference(ctx context.Context, ref reference) error { // method on reference instead on document? 'isLocal' could be calculated at creation time, or reference could be an interface that 'local' and 'remote' satisfy by implementing "replace". To be considered
 	if !isLocalReference(ref.path) {
-		return doc.replaceRemoteReference(ref)
+		return doc.replaceRemoteReference(ctx, ref)
 	}
 
 	if !doc.Cfg.InlineLocalRefs {
 		return nil
 	}
 
-	return doc.replaceLocalReference(ref)
+	return doc.replaceLocalReference(ctx, ref)
 }
 
-func (doc Document) replaceLocalReference(ref reference) error {
-	referencedDocument, err := doc.getReferencedDocument(ref.path)
+func (doc Document) replaceLocalReference(ctx context.Context, ref reference) error {
+	referencedDocument, err := doc.getReferencedDocument(ctx, ref.path)
 	if err != nil {
 		return fmt.Errorf("could not get reference document: %w", err)
 	}
@@ -193,15 +324,23 @@ func (doc Document) replaceLocalReference(ref reference) error {
 		return err
 	}
 
+	doc.resolutions.add(resolution{target: ref.object, ref: ref.path, instance: referencedObject.instance})
+
 	if doc.Cfg.KeepLocalRefs || !doc.Cfg.InlineLocalRefs {
 		return nil
 	}
 
-	return referencedObject.Unset()
+	err = referencedObject.Unset()
+	if err != nil {
+		return err
+	}
+
+	doc.resolutions.add(resolution{target: referencedObject, ref: ref.path, removed: true})
+	return nil
 }
 
-func (doc Document) replaceRemoteReference(ref reference) error {
-	referencedDocument, err := doc.getReferencedDocument(ref.path)
+func (doc Document) replaceRemoteReference(ctx context.Context, ref reference) error {
+	referencedDocument, err := doc.getReferencedDocument(ctx, ref.path)
 	if err != nil {
 		return fmt.Errorf("could not get reference document: %w", err)
 	}
@@ -228,7 +367,14 @@ func (doc Document) replaceRemoteReference(ref reference) error {
 		targetObject = ref.object
 	}
 
-	return targetObject.Set(refObject.instance)
+	err = targetObject.Set(refObject.instance)
+	if err != nil {
+		return err
+	}
+
+	source := canonicalDocumentPath(joinDocumentPath(doc.RefDirectory, getDocumentPath(ref.path)))
+	doc.resolutions.add(resolution{target: targetObject, ref: ref.path, source: source, instance: refObject.instance})
+	return nil
 }
 
 // getOrCreateObjectByPath walks the provided reference path, trying obtain the oas object and creating it (by changing it to zero value) if it does not exists.
@@ -268,46 +414,77 @@ func (doc Document) getOrCreateObjectByPath(refPath string, forceCreate bool) (O
 	return object, nil
 }
 
-func (doc Document) getReferencedDocument(refPath string) (*Document, error) {
-	var referencedDocument *Document
+// localObject returns the object found under the local reference path, without creating missing objects on the way.
+// Returned bool is false when the object does not exist.
+func (doc Document) localObject(refPath string) (interface{}, bool) {
+	value := reflect.ValueOf(doc.Root)
+
+	for _, itemName := range pointerToItems(getPathToReference(refPath)) {
+		switch value.Kind() {
+		case reflect.Ptr:
+			if value.IsNil() {
+				return nil, false
+			}
 
+			fieldName, err := getFieldNameByTag(itemName, value.Elem())
+			if err != nil {
+				return nil, false
+			}
+
+			value, _ = fieldByName(value.Elem(), fieldName)
+		case reflect.Map:
+			value = value.MapIndex(reflect.ValueOf(itemName))
+		case reflect.Slice:
+			idx, err := strconv.Atoi(itemName)
+			if err != nil || idx < 0 || idx >= value.Len() {
+				return nil, false
+			}
+
+			value = value.Index(idx)
+		default:
+			return nil, false
+		}
+
+		if !value.IsValid() || value.IsZero() {
+			return nil, false
+		}
+	}
+
+	return value.Interface(), true
+}
+
+func (doc Document) getReferencedDocument(ctx context.Context, refPath string) (*Document, error) {
 	if isLocalReference(refPath) {
 		return &doc, nil
 	}
 
 	documentPath := getDocumentPath(refPath)
-	documentFilePath := filepath.Join(doc.RefDirectory, documentPath)
+	documentFilePath := joinDocumentPath(doc.RefDirectory, documentPath)
+	doc.ReferencedDocuments.addDependency(doc.path(), documentFilePath)
 
-	if document, ok := doc.ReferencedDocuments[documentFilePath]; ok {
-		referencedDocument = document
-	} else {
-		cfg := Config{
-			InlineLocalRefs: true,
-		}
-		parsedDocument, err := ParseDocument(cfg, documentFilePath)
-		if err != nil {
-			return nil, err
-		}
+	referencedDocument, ok, err := doc.ReferencedDocuments.resolved(ctx, documentFilePath)
+	if err != nil || ok {
+		return referencedDocument, err
+	}
 
-		referencedDocument = &parsedDocument
-		doc.ReferencedDocuments[documentFilePath] = referencedDocument
+	referencedDocument, err = doc.loadReferencedDocument(ctx, documentPath, documentFilePath)
+	if err != nil {
+		return nil, err
 	}
 
-	return referencedDocument, nil
+	doc.ReferencedDocuments.setDocument(documentFilePath, referencedDocument, documentParsed)
+	referencedDocument, _, err = doc.ReferencedDocuments.resolved(ctx, documentFilePath)
+	return referencedDocument, err
 }
 
 func getFieldNameByTag(tag string, structItem reflect.Value) (string, error) {
-	structItemType := structItem.Type()
-
-	for i := 0; i < structItemType.NumField(); i++ {
-		childField := structItemType.Field(i)
-		yamlKey := getYamlKeyFromField(childField)
-		if yamlKey == tag {
-			return childField.Name, nil
-		}
+	fields := fieldsOf(structItem.Type())
+	idx, ok := fields.byTag[tag]
+	if !ok {
+		return "", ErrNoFieldWithTag
 	}
 
-	return "", ErrNoFieldWithTag
+	return fields.names[idx], nil
 }
 
 func getYamlKeyFromField(field reflect.StructField) string {
@@ -0,0 +1,258 @@
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type documentState int
+
+const (
+	documentParsed documentState = iota
+	documentResolving
+	documentResolved
+)
+
+// DocumentCache holds documents referenced during a resolution session, keyed by their canonical absolute paths or URLs.
+// A single cache is shared by the root document and all documents referenced by it, directly or indirectly, so every document is loaded, parsed and resolved once.
+// It is safe for concurrent use.
+type DocumentCache struct {
+	mu        sync.RWMutex
+	documents map[string]*Document
+	states    map[string]documentState
+	loaded    bool
+	// dependencies are canonical paths of documents referenced by each document, so documents depending on a changed document can be found
+	dependencies map[string]map[string]bool
+}
+
+// NewDocumentCache constructs an empty DocumentCache
+func NewDocumentCache() *DocumentCache {
+	return &DocumentCache{
+		documents:    make(map[string]*Document),
+		states:       make(map[string]documentState),
+		dependencies: make(map[string]map[string]bool),
+	}
+}
+
+// Get returns the document stored under the path
+func (c *DocumentCache) Get(path string) (*Document, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	document, ok := c.documents[canonicalDocumentPath(path)]
+	return document, ok
+}
+
+// Set stores the document, with its references already resolved, under the path, replacing the document stored previously
+func (c *DocumentCache) Set(path string, document *Document) {
+	c.setDocument(path, document, documentResolved)
+}
+
+// Paths returns sorted canonical paths of stored documents
+func (c *DocumentCache) Paths() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	paths := make([]string, 0, len(c.documents))
+	for path := range c.documents {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// Len returns the number of stored documents
+func (c *DocumentCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.documents)
+}
+
+func (c *DocumentCache) setDocument(path string, document *Document, state documentState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := canonicalDocumentPath(path)
+	c.documents[key] = document
+	c.states[key] = state
+}
+
+// resolved returns the stored document, resolving its references on the first use, or false when the document is not stored.
+// A document that failed to resolve, eg. because the context was canceled, is partially resolved, so it is removed to be loaded again on the next use
+func (c *DocumentCache) resolved(ctx context.Context, path string) (*Document, bool, error) {
+	key := canonicalDocumentPath(path)
+
+	c.mu.Lock()
+	document, ok := c.documents[key]
+	state := c.states[key]
+	if ok && state == documentParsed {
+		c.states[key] = documentResolving
+	}
+	c.mu.Unlock()
+
+	switch {
+	case !ok:
+		return nil, false, nil
+	case state == documentResolved:
+		return document, true, nil
+	case state == documentResolving:
+		return nil, true, fmt.Errorf("%w: %s", ErrCircularReference, path)
+	}
+
+	err := document.ResolveReferencesContext(ctx)
+	if err != nil {
+		c.mu.Lock()
+		delete(c.documents, key)
+		delete(c.states, key)
+		c.mu.Unlock()
+
+		return nil, true, err
+	}
+
+	c.mu.Lock()
+	c.states[key] = documentResolved
+	c.mu.Unlock()
+
+	return document, true, nil
+}
+
+// addDependency records that the dependent document references the dependency
+func (c *DocumentCache) addDependency(dependent, dependency string) {
+	dependentKey, dependencyKey := canonicalDocumentPath(dependent), canonicalDocumentPath(dependency)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.dependencies[dependentKey] == nil {
+		c.dependencies[dependentKey] = make(map[string]bool)
+	}
+
+	c.dependencies[dependentKey][dependencyKey] = true
+}
+
+// dependenciesOf returns canonical paths of documents the document depends on, directly or not, including the document itself
+func (c *DocumentCache) dependenciesOf(path string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	seen := map[string]bool{canonicalDocumentPath(path): true}
+	pending := []string{canonicalDocumentPath(path)}
+	for len(pending) > 0 {
+		key := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for dependency := range c.dependencies[key] {
+			if !seen[dependency] {
+				seen[dependency] = true
+				pending = append(pending, dependency)
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(seen))
+	for key := range seen {
+		paths = append(paths, key)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// removeDependencies forgets documents referenced by the document, eg. before its references are resolved again
+func (c *DocumentCache) removeDependencies(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.dependencies, canonicalDocumentPath(path))
+}
+
+// invalidate removes the documents and documents depending on them, directly or not, so they are loaded again on the next use.
+// Canonical paths of the documents and their dependents are returned, whether they were stored or not
+func (c *DocumentCache) invalidate(paths ...string) map[string]bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	affected := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		affected[canonicalDocumentPath(path)] = true
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for dependent, dependencies := range c.dependencies {
+			if affected[dependent] {
+				continue
+			}
+
+			for dependency := range dependencies {
+				if affected[dependency] {
+					affected[dependent] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	for key := range affected {
+		if _, ok := c.documents[key]; !ok { // documents that are not stored, eg. the root document, keep their references, and therefore their dependencies
+			continue
+		}
+
+		delete(c.documents, key)
+		delete(c.states, key)
+		delete(c.dependencies, key)
+	}
+
+	return affected
+}
+
+// markLoaded marks referenced documents as loaded, returning whether they were marked before
+func (c *DocumentCache) markLoaded() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	loaded := c.loaded
+	c.loaded = true
+	return loaded
+}
+
+func (c *DocumentCache) isLoaded() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.loaded
+}
+
+// canonicalDocumentPath returns the absolute, cleaned path of the document, or the URL with a lowercase scheme and host, a cleaned path and without a fragment
+func canonicalDocumentPath(documentPath string) string {
+	if !isURL(documentPath) {
+		absPath, err := filepath.Abs(documentPath)
+		if err != nil {
+			return filepath.Clean(documentPath)
+		}
+
+		return absPath
+	}
+
+	documentURL, err := url.Parse(documentPath)
+	if err != nil {
+		return documentPath
+	}
+
+	documentURL.Scheme = strings.ToLower(documentURL.Scheme)
+	documentURL.Host = strings.ToLower(documentURL.Host)
+	documentURL.Fragment = ""
+	if documentURL.Path != "" {
+		documentURL.Path = path.Clean(documentURL.Path)
+	}
+
+	return documentURL.String()
+}
@@ -0,0 +1,391 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// DefaultSchemaNameTemplate names extracted schemas by their title, by the operation and location for schemas of operations, eg. "CreateOrderRequest" or "GetOrderResponse200",
+	// and by the parent schema and property name for nested schemas, eg. "CreateOrderRequestAddress"
+	DefaultSchemaNameTemplate = `{{ if .Title }}{{ .Title }}{{ else if .Parent }}{{ .Parent }} {{ .Name }}{{ else }}{{ .Operation }} {{ .Name }} {{ .Location }} {{ .StatusCode }}{{ end }}`
+
+	// RequestLocation is a location of a request body schema
+	RequestLocation = "Request"
+	// ResponseLocation is a location of a response schema
+	ResponseLocation = "Response"
+	// ParameterLocation is a location of a parameter schema
+	ParameterLocation = "Parameter"
+	// PropertyLocation is a location of a schema of a property of an extracted schema
+	PropertyLocation = "Property"
+	// ItemsLocation is a location of a schema of items of an array schema
+	ItemsLocation = "Items"
+	// AllOfLocation is a location of a subschema of allOf
+	AllOfLocation = "AllOf"
+	// OneOfLocation is a location of a subschema of oneOf
+	OneOfLocation = "OneOf"
+	// AnyOfLocation is a location of a subschema of anyOf
+	AnyOfLocation = "AnyOf"
+	// NotLocation is a location of a subschema of not
+	NotLocation = "Not"
+	// AdditionalPropertiesLocation is a location of a schema of additionalProperties
+	AdditionalPropertiesLocation = "AdditionalProperties"
+
+	itemsName               = "Item"
+	valuesName              = "Value"
+	fallbackName            = "Schema"
+	schemaFieldName         = "Schema"
+	additionalPropertiesKey = "additionalProperties"
+)
+
+// ExtractConfig specifies how extracted schemas are named
+type ExtractConfig struct {
+	// NameTemplate is a Go template rendering a name of an extracted schema from InlineSchema. Rendered names are turned into component names by removing characters
+	// other than letters, digits, dots, dashes and underscores and capitalizing words, eg. "createOrder request" becomes "CreateOrderRequest". DefaultSchemaNameTemplate is used when empty
+	NameTemplate string
+}
+
+// InlineSchema describes an inline schema for the name template
+type InlineSchema struct {
+	// Operation is an operationId of the operation using the schema, or its method and path when operationId is missing
+	Operation   string
+	OperationID string
+	Method      string
+	Path        string
+	// Location is one of RequestLocation, ResponseLocation, ParameterLocation, PropertyLocation, ItemsLocation, AllOfLocation, OneOfLocation,
+	// AnyOfLocation, NotLocation or AdditionalPropertiesLocation
+	Location   string
+	StatusCode string
+	MediaType  string
+	// Name is a name of the parameter or the property, "Item" for items of an array, "Value" for additionalProperties,
+	// or the location followed by a position starting from 1 for subschemas of allOf, oneOf, anyOf and not, eg. "OneOf2"
+	Name string
+	// Parent is a name of the extracted schema holding a nested schema
+	Parent string
+	Title  string
+}
+
+// schemaExtractor holds the state of schemas extraction
+type schemaExtractor struct {
+	doc       Document
+	template  *template.Template
+	extracted map[*Schema]string
+	refs      []string
+}
+
+// ExtractSchemas lifts inline object schemas with properties used by operations into components/schemas and replaces them with $refs.
+// Schemas of parameters, request bodies and responses are extracted, along with object schemas nested in them: in properties, array items,
+// subschemas of allOf, oneOf, anyOf and not, and additionalProperties.
+// Names are rendered by the naming template, with a numeric suffix added when the name is already taken. Returned list holds local references to extracted schemas.
+// Referenced request bodies, responses and parameters are skipped, since they are components already.
+func (doc Document) ExtractSchemas(cfg ExtractConfig) ([]string, error) {
+	nameTemplate := cfg.NameTemplate
+	if nameTemplate == "" {
+		nameTemplate = DefaultSchemaNameTemplate
+	}
+
+	tmpl, err := template.New("name").Parse(nameTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse naming template: %w", err)
+	}
+
+	extractor := schemaExtractor{
+		doc:       doc,
+		template:  tmpl,
+		extracted: make(map[*Schema]string),
+	}
+
+	for _, pathItems := range []map[string]*PathItem{doc.Root.Paths, doc.Root.Webhooks} {
+		err = extractor.pathItems(pathItems)
+		if err != nil {
+			return extractor.refs, err
+		}
+	}
+
+	return extractor.refs, nil
+}
+
+func (e *schemaExtractor) pathItems(pathItems map[string]*PathItem) error {
+	for _, path := range unionKeys(pathItems) {
+		pathItem := pathItems[path]
+		if pathItem == nil || pathItem.Ref != "" {
+			continue
+		}
+
+		for _, method := range HTTPMethods {
+			operation := pathItem.Operation(method)
+			if operation == nil {
+				continue
+			}
+
+			location := InlineSchema{
+				Operation:   operation.OperationID,
+				OperationID: operation.OperationID,
+				Method:      method,
+				Path:        path,
+			}
+			if location.Operation == "" {
+				location.Operation = fmt.Sprintf("%s %s", method, path)
+			}
+
+			err := e.operation(pathItem, operation, location)
+			if err != nil {
+				return fmt.Errorf("could not extract schemas of %s %s: %w", method, path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *schemaExtractor) operation(pathItem *PathItem, operation *Operation, location InlineSchema) error {
+	for _, parameters := range [][]*Parameter{pathItem.Parameters, operation.Parameters} {
+		for _, parameter := range parameters {
+			if parameter == nil || parameter.Ref != "" {
+				continue
+			}
+
+			parameterLocation := location
+			parameterLocation.Location = ParameterLocation
+			parameterLocation.Name = parameter.Name
+			err := e.field(parameter, parameter.Schema, parameterLocation)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if operation.RequestBody != nil && operation.RequestBody.Ref == "" {
+		requestLocation := location
+		requestLocation.Location = RequestLocation
+		err := e.content(operation.RequestBody.Content, requestLocation)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, code := range unionKeys(operation.Responses) {
+		response := operation.Responses[code]
+		if response == nil || response.Ref != "" {
+			continue
+		}
+
+		responseLocation := location
+		responseLocation.Location = ResponseLocation
+		responseLocation.StatusCode = code
+		err := e.content(response.Content, responseLocation)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *schemaExtractor) content(content map[string]*MediaType, location InlineSchema) error {
+	for _, mediaType := range unionKeys(content) {
+		if content[mediaType] == nil {
+			continue
+		}
+
+		mediaTypeLocation := location
+		mediaTypeLocation.MediaType = mediaType
+		err := e.field(content[mediaType], content[mediaType].Schema, mediaTypeLocation)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// field extracts a schema held by the Schema field of the parent, eg. a media type or a parameter
+func (e *schemaExtractor) field(parent interface{}, schema *Schema, location InlineSchema) error {
+	if schema == nil {
+		return nil
+	}
+
+	object, err := OasObjectByName(parent, schemaFieldName, false)
+	if err != nil {
+		return err
+	}
+
+	return e.schema(object, schema, location)
+}
+
+// schema extracts the schema when it is an object with properties, replacing the object with a $ref, and walks all of its subschemas
+func (e *schemaExtractor) schema(object OasObject, schema *Schema, location InlineSchema) error {
+	if schema.Ref != "" {
+		return nil
+	}
+
+	location.Title = schema.Title
+	name, err := e.name(location)
+	if err != nil {
+		return err
+	}
+
+	if ref, ok := e.extracted[schema]; ok {
+		return object.Set(&Schema{Ref: ref})
+	}
+
+	if len(schema.Properties) > 0 {
+		name = e.doc.uniqueComponentName(schemasItem, name)
+		ref := componentReference(schemasItem, name)
+
+		err = e.createComponent(ref, schema)
+		if err != nil {
+			return err
+		}
+
+		err = object.Set(&Schema{Ref: ref})
+		if err != nil {
+			return err
+		}
+
+		e.extracted[schema] = ref
+		e.refs = append(e.refs, ref)
+	}
+
+	for _, property := range unionKeys(schema.Properties) {
+		if schema.Properties[property] == nil {
+			continue
+		}
+
+		propertyObject, err := OasObjectByName(schema.Properties, property, false)
+		if err != nil {
+			return err
+		}
+
+		err = e.schema(propertyObject, schema.Properties[property], InlineSchema{Location: PropertyLocation, Name: property, Parent: name})
+		if err != nil {
+			return err
+		}
+	}
+
+	if schema.Items != nil {
+		itemsObject, err := OasObjectByName(schema, "Items", false)
+		if err != nil {
+			return err
+		}
+
+		err = e.schema(itemsObject, schema.Items, InlineSchema{Location: ItemsLocation, Name: itemsName, Parent: name})
+		if err != nil {
+			return err
+		}
+	}
+
+	compositions := []struct {
+		location string
+		schemas  []*Schema
+	}{
+		{AllOfLocation, schema.AllOf},
+		{OneOfLocation, schema.OneOf},
+		{AnyOfLocation, schema.AnyOf},
+		{NotLocation, schema.Not},
+	}
+
+	for _, composition := range compositions {
+		for idx, subschema := range composition.schemas {
+			if subschema == nil {
+				continue
+			}
+
+			subschemaObject, err := OasObjectByIdx(composition.schemas, idx)
+			if err != nil {
+				return err
+			}
+
+			err = e.schema(subschemaObject, subschema, InlineSchema{Location: composition.location, Name: fmt.Sprintf("%s%d", composition.location, idx+1), Parent: name})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return e.additionalProperties(schema, name)
+}
+
+// additionalProperties extracts the schema of additionalProperties. The field is not handled by the types, so its schema is held by extensions as a generic value,
+// which is replaced with a typed schema only when the schema or any of its subschemas has been extracted
+func (e *schemaExtractor) additionalProperties(schema *Schema, parent string) error {
+	value, ok := schema.Extensions[additionalPropertiesKey]
+	if !ok {
+		return nil
+	}
+
+	additional, ok := value.(*Schema)
+	if !ok {
+		// additionalProperties can be a boolean too
+		if _, isMap := value.(map[interface{}]interface{}); !isMap {
+			return nil
+		}
+
+		var err error
+		additional, err = schemaFromExtension(value)
+		if err != nil {
+			return fmt.Errorf("could not parse %s: %w", additionalPropertiesKey, err)
+		}
+	}
+
+	object, err := OasObjectByName(schema.Extensions, additionalPropertiesKey, false)
+	if err != nil {
+		return err
+	}
+
+	extracted := len(e.refs)
+	err = e.schema(object, additional, InlineSchema{Location: AdditionalPropertiesLocation, Name: valuesName, Parent: parent})
+	if err != nil {
+		return err
+	}
+
+	if current, _ := schema.Extensions[additionalPropertiesKey].(*Schema); len(e.refs) > extracted && (current == nil || current.Ref == "") {
+		schema.Extensions[additionalPropertiesKey] = additional
+	}
+
+	return nil
+}
+
+func schemaFromExtension(value interface{}) (*Schema, error) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{}
+	err = yaml.Unmarshal(data, schema)
+	return schema, err
+}
+
+// createComponent adds the schema to components under the reference, creating components on the way when needed
+func (e *schemaExtractor) createComponent(ref string, schema *Schema) error {
+	if e.doc.Root.Components == nil {
+		e.doc.Root.Components = &Components{}
+	}
+
+	forceCreate := true
+	component, err := e.doc.getOrCreateObjectByPath(ref, forceCreate)
+	if err != nil {
+		return err
+	}
+
+	return component.Set(schema)
+}
+
+func (e *schemaExtractor) name(location InlineSchema) (string, error) {
+	var rendered strings.Builder
+	err := e.template.Execute(&rendered, location)
+	if err != nil {
+		return "", fmt.Errorf("could not render schema name: %w", err)
+	}
+
+	name := componentName(rendered.String())
+	if name == "" {
+		name = fallbackName
+	}
+
+	return name, nil
+}
@@ -0,0 +1,218 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractSchemas(t *testing.T) {
+	doc := parseYAML(t, `openapi: 3.0.0
+info:
+  title: shop
+  version: "1"
+paths:
+  /orders:
+    post:
+      operationId: createOrder
+      parameters:
+      - name: filter
+        in: query
+        schema:
+          type: object
+          properties:
+            status:
+              type: string
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                address:
+                  type: object
+                  properties:
+                    city:
+                      type: string
+                lines:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      sku:
+                        type: string
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema:
+                title: created order
+                type: object
+                properties:
+                  id:
+                    type: string
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/CreatedOrder'
+components:
+  schemas:
+    CreatedOrder:
+      type: string
+`)
+
+	refs, err := doc.ExtractSchemas(ExtractConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"#/components/schemas/CreateOrderFilterParameter",
+		"#/components/schemas/CreateOrderRequest",
+		"#/components/schemas/CreateOrderRequestAddress",
+		"#/components/schemas/CreateOrderRequestLinesItem",
+		"#/components/schemas/CreatedOrder2",
+	}
+	if !reflect.DeepEqual(refs, expected) {
+		t.Errorf("expected extracted schemas %v, got %v", expected, refs)
+	}
+
+	operation := doc.Root.Paths["/orders"].Post
+	request := doc.Root.Components.Schemas["CreateOrderRequest"]
+	for location, ref := range map[string]string{
+		"parameter":   operation.Parameters[0].Schema.Ref,
+		"request":     operation.RequestBody.Content["application/json"].Schema.Ref,
+		"response":    operation.Responses["201"].Content["application/json"].Schema.Ref,
+		"property":    request.Properties["address"].Ref,
+		"array items": request.Properties["lines"].Items.Ref,
+	} {
+		if ref == "" {
+			t.Errorf("expected the %s schema to be replaced with a $ref", location)
+		}
+	}
+
+	if ref := doc.Root.Paths["/orders"].Get.Responses["200"].Content["application/json"].Schema.Items.Ref; ref != "#/components/schemas/CreatedOrder" {
+		t.Errorf("expected references to be left as they are, got %s", ref)
+	}
+}
+
+func TestExtractSchemasFromSubschemas(t *testing.T) {
+	doc := parseYAML(t, `openapi: 3.0.0
+info:
+  title: shop
+  version: "1"
+paths:
+  /pets:
+    post:
+      operationId: addPet
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                pet:
+                  oneOf:
+                  - type: object
+                    properties:
+                      barks:
+                        type: boolean
+                  - $ref: '#/components/schemas/Cat'
+                base:
+                  allOf:
+                  - type: object
+                    properties:
+                      id:
+                        type: string
+                tags:
+                  anyOf:
+                  - type: object
+                    properties:
+                      name:
+                        type: string
+                notes:
+                  not:
+                  - type: object
+                    properties:
+                      secret:
+                        type: string
+                labels:
+                  type: object
+                  additionalProperties:
+                    type: object
+                    properties:
+                      text:
+                        type: string
+                attributes:
+                  type: object
+                  additionalProperties:
+                    type: array
+                    items:
+                      type: object
+                      properties:
+                        value:
+                          type: string
+                flags:
+                  type: object
+                  additionalProperties: false
+      responses:
+        "201":
+          description: created
+components:
+  schemas:
+    Cat:
+      type: object
+`)
+
+	refs, err := doc.ExtractSchemas(ExtractConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"#/components/schemas/AddPetRequest",
+		"#/components/schemas/AddPetRequestAttributesValueItem",
+		"#/components/schemas/AddPetRequestBaseAllOf1",
+		"#/components/schemas/AddPetRequestLabelsValue",
+		"#/components/schemas/AddPetRequestNotesNot1",
+		"#/components/schemas/AddPetRequestPetOneOf1",
+		"#/components/schemas/AddPetRequestTagsAnyOf1",
+	}
+	if !reflect.DeepEqual(refs, expected) {
+		t.Errorf("expected extracted schemas %v, got %v", expected, refs)
+	}
+
+	properties := doc.Root.Components.Schemas["AddPetRequest"].Properties
+	for location, ref := range map[string]string{
+		"allOf": properties["base"].AllOf[0].Ref,
+		"oneOf": properties["pet"].OneOf[0].Ref,
+		"anyOf": properties["tags"].AnyOf[0].Ref,
+		"not":   properties["notes"].Not[0].Ref,
+	} {
+		if ref == "" {
+			t.Errorf("expected the %s subschema to be replaced with a $ref", location)
+		}
+	}
+
+	if ref := properties["pet"].OneOf[1].Ref; ref != "#/components/schemas/Cat" {
+		t.Errorf("expected references among subschemas to be left as they are, got %s", ref)
+	}
+
+	if labels, ok := properties["labels"].Extensions[additionalPropertiesKey].(*Schema); !ok || labels.Ref != "#/components/schemas/AddPetRequestLabelsValue" {
+		t.Errorf("expected the additionalProperties schema to be replaced with a $ref, got %#v", properties["labels"].Extensions[additionalPropertiesKey])
+	}
+
+	if attributes, ok := properties["attributes"].Extensions[additionalPropertiesKey].(*Schema); !ok || attributes.Items.Ref != "#/components/schemas/AddPetRequestAttributesValueItem" {
+		t.Errorf("expected the schema nested in additionalProperties to be replaced with a $ref, got %#v", properties["attributes"].Extensions[additionalPropertiesKey])
+	}
+
+	if flags := properties["flags"].Extensions[additionalPropertiesKey]; flags != false {
+		t.Errorf("expected boolean additionalProperties to be left as they are, got %#v", flags)
+	}
+}
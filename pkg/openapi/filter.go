@@ -0,0 +1,168 @@
+package openapi
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Filter specifies which operations should be kept in a document.
+// Each not empty criterion has to be met for operation to be kept; an operation meets the criterion when it matches any of its values.
+type Filter struct {
+	// IncludeTags keeps operations that have at least one of the tags
+	IncludeTags []string
+	// ExcludeTags removes operations that have at least one of the tags, even if they have included tags too
+	ExcludeTags []string
+	// IncludePaths keeps operations of paths matching at least one of the glob patterns. A "*" matches any sequence of characters except "/", a "**" matches any sequence of characters.
+	// Webhooks are not paths, so this criterion is not applied to them.
+	IncludePaths []string
+	// IncludeOperations keeps operations with the operationIds
+	IncludeOperations []string
+}
+
+// Empty checks whether filter has no criteria, and therefore would keep every operation
+func (f Filter) Empty() bool {
+	return len(f.IncludeTags) == 0 && len(f.ExcludeTags) == 0 && len(f.IncludePaths) == 0 && len(f.IncludeOperations) == 0
+}
+
+// Filter removes operations of paths and webhooks not matching the filter, along with path items that are left without operations.
+// After that components that are no longer reachable are pruned and top-level tags not used by the remaining operations are removed.
+// Should be run on a document with resolved references, since the pruning does not follow remote references.
+func (doc Document) Filter(filter Filter) error {
+	pathPatterns := make([]*regexp.Regexp, 0, len(filter.IncludePaths))
+	for _, glob := range filter.IncludePaths {
+		pattern, err := globToRegexp(glob)
+		if err != nil {
+			return err
+		}
+
+		pathPatterns = append(pathPatterns, pattern)
+	}
+
+	filterPathItems(doc.Root.Paths, func(path string, operation *Operation) bool {
+		return filter.keepsOperation(operation) && (len(pathPatterns) == 0 || matchesAny(pathPatterns, path))
+	})
+	filterPathItems(doc.Root.Webhooks, func(_ string, operation *Operation) bool {
+		return filter.keepsOperation(operation)
+	})
+
+	_, err := doc.PruneComponents(false)
+	if err != nil {
+		return err
+	}
+
+	doc.trimTags()
+	return nil
+}
+
+func (f Filter) keepsOperation(operation *Operation) bool {
+	if len(f.IncludeTags) > 0 && !containsAny(operation.Tags, f.IncludeTags) {
+		return false
+	}
+
+	if len(f.ExcludeTags) > 0 && containsAny(operation.Tags, f.ExcludeTags) {
+		return false
+	}
+
+	if len(f.IncludeOperations) > 0 && !containsAny([]string{operation.OperationID}, f.IncludeOperations) {
+		return false
+	}
+
+	return true
+}
+
+// filterPathItems removes operations for which keep returns false. Path items left without operations are removed, unless they are references.
+func filterPathItems(pathItems map[string]*PathItem, keep func(path string, operation *Operation) bool) {
+	for path, pathItem := range pathItems {
+		if pathItem == nil {
+			continue
+		}
+
+		for method, operation := range pathItem.Operations() {
+			if !keep(path, operation) {
+				pathItem.SetOperation(method, nil)
+			}
+		}
+
+		if len(pathItem.Operations()) == 0 && pathItem.Ref == "" {
+			delete(pathItems, path)
+		}
+	}
+}
+
+// trimTags removes top-level tags that are not used by any operation
+func (doc Document) trimTags() {
+	if len(doc.Root.Tags) == 0 {
+		return
+	}
+
+	used := make(map[string]bool)
+	for _, pathItems := range []map[string]*PathItem{doc.Root.Paths, doc.Root.Webhooks} {
+		for _, pathItem := range pathItems {
+			if pathItem == nil {
+				continue
+			}
+
+			for _, operation := range pathItem.Operations() {
+				for _, tag := range operation.Tags {
+					used[tag] = true
+				}
+			}
+		}
+	}
+
+	var tags []*Tag
+	for _, tag := range doc.Root.Tags {
+		if tag != nil && used[tag.Name] {
+			tags = append(tags, tag)
+		}
+	}
+
+	doc.Root.Tags = tags
+}
+
+// globToRegexp converts a glob pattern to an anchored regular expression. A "*" matches any sequence of characters except "/", a "**" matches any sequence of characters and a "?" matches a single character other than "/".
+func globToRegexp(glob string) (*regexp.Regexp, error) {
+	var pattern strings.Builder
+
+	pattern.WriteString("^")
+	for idx := 0; idx < len(glob); idx++ {
+		switch glob[idx] {
+		case '*':
+			if idx+1 < len(glob) && glob[idx+1] == '*' {
+				pattern.WriteString(".*")
+				idx++
+			} else {
+				pattern.WriteString("[^/]*")
+			}
+		case '?':
+			pattern.WriteString("[^/]")
+		default:
+			pattern.WriteString(regexp.QuoteMeta(string(glob[idx])))
+		}
+	}
+	pattern.WriteString("$")
+
+	return regexp.Compile(pattern.String())
+}
+
+func matchesAny(patterns []*regexp.Regexp, value string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsAny(values []string, wanted []string) bool {
+	for _, value := range values {
+		for _, wantedValue := range wanted {
+			if value == wantedValue {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,194 @@
+package openapi
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const filterSpec = `openapi: 3.1.0
+info:
+  title: shop
+  version: "1"
+tags:
+- name: orders
+- name: users
+- name: internal
+paths:
+  /orders:
+    get:
+      operationId: listOrders
+      tags: [orders]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Orders'
+    post:
+      operationId: createOrder
+      tags: [orders, internal]
+      responses:
+        "200":
+          description: ok
+  /orders/{id}/items:
+    get:
+      operationId: listItems
+      tags: [orders]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Item'
+  /users:
+    get:
+      operationId: listUsers
+      tags: [users]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+webhooks:
+  orderCreated:
+    post:
+      operationId: orderCreated
+      tags: [orders]
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    Orders:
+      type: array
+      items:
+        $ref: '#/components/schemas/Order'
+    Order:
+      type: object
+    Item:
+      type: object
+    User:
+      type: object
+`
+
+// operationLabels returns sorted labels of operations of paths and webhooks, eg. "GET /orders" or "POST webhook:orderCreated"
+func operationLabels(doc Document) []string {
+	var labels []string
+	for prefix, pathItems := range map[string]map[string]*PathItem{"": doc.Root.Paths, "webhook:": doc.Root.Webhooks} {
+		for path, pathItem := range pathItems {
+			for method := range pathItem.Operations() {
+				labels = append(labels, strings.ToUpper(method)+" "+prefix+path)
+			}
+		}
+	}
+
+	sort.Strings(labels)
+	return labels
+}
+
+func sortedKeys(values interface{}) []string {
+	var keys []string
+	for _, key := range reflect.ValueOf(values).MapKeys() {
+		keys = append(keys, key.String())
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func tagNames(doc Document) []string {
+	var names []string
+	for _, tag := range doc.Root.Tags {
+		names = append(names, tag.Name)
+	}
+
+	return names
+}
+
+func TestFilter(t *testing.T) {
+	for _, tc := range []struct {
+		name               string
+		filter             Filter
+		expectedOperations []string
+		expectedSchemas    []string
+		expectedTags       []string
+	}{
+		{
+			name:               "include tags",
+			filter:             Filter{IncludeTags: []string{"users"}},
+			expectedOperations: []string{"GET /users"},
+			expectedSchemas:    []string{"User"},
+			expectedTags:       []string{"users"},
+		},
+		{
+			name:               "exclude tags",
+			filter:             Filter{ExcludeTags: []string{"internal", "users"}},
+			expectedOperations: []string{"GET /orders", "GET /orders/{id}/items", "POST webhook:orderCreated"},
+			expectedSchemas:    []string{"Item", "Order", "Orders"},
+			expectedTags:       []string{"orders"},
+		},
+		{
+			name:               "include paths",
+			filter:             Filter{IncludePaths: []string{"/orders/**"}},
+			expectedOperations: []string{"GET /orders/{id}/items", "POST webhook:orderCreated"},
+			expectedSchemas:    []string{"Item"},
+			expectedTags:       []string{"orders"},
+		},
+		{
+			name:               "include paths with a single segment wildcard",
+			filter:             Filter{IncludePaths: []string{"/*"}},
+			expectedOperations: []string{"GET /orders", "GET /users", "POST /orders", "POST webhook:orderCreated"},
+			expectedSchemas:    []string{"Order", "Orders", "User"},
+			expectedTags:       []string{"orders", "users", "internal"},
+		},
+		{
+			name:               "include operations along with tags",
+			filter:             Filter{IncludeOperations: []string{"createOrder", "listUsers"}, IncludeTags: []string{"orders"}},
+			expectedOperations: []string{"POST /orders"},
+			expectedSchemas:    nil,
+			expectedTags:       []string{"orders", "internal"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := parseYAML(t, filterSpec)
+			err := doc.Filter(tc.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if operations := operationLabels(doc); !reflect.DeepEqual(operations, tc.expectedOperations) {
+				t.Errorf("expected operations %v, got %v", tc.expectedOperations, operations)
+			}
+
+			if schemas := sortedKeys(doc.Root.Components.Schemas); !reflect.DeepEqual(schemas, tc.expectedSchemas) {
+				t.Errorf("expected schemas %v, got %v", tc.expectedSchemas, schemas)
+			}
+
+			if tags := tagNames(doc); !reflect.DeepEqual(tags, tc.expectedTags) {
+				t.Errorf("expected tags %v, got %v", tc.expectedTags, tags)
+			}
+		})
+	}
+}
+
+func TestFilterRemovesEmptyPathItems(t *testing.T) {
+	doc := parseYAML(t, filterSpec)
+	err := doc.Filter(Filter{IncludeOperations: []string{"listUsers"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if paths := sortedKeys(doc.Root.Paths); !reflect.DeepEqual(paths, []string{"/users"}) {
+		t.Errorf("expected only /users to be left, got %v", paths)
+	}
+
+	if len(doc.Root.Webhooks) != 0 {
+		t.Errorf("expected webhooks without operations to be removed, got %v", sortedKeys(doc.Root.Webhooks))
+	}
+}
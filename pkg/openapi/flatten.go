@@ -0,0 +1,420 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// FlattenConfig specifies how allOf compositions are flattened
+type FlattenConfig struct {
+	// ResolveRefs merges local references among allOf branches (and among properties merged from many branches) by their content.
+	// When false, compositions with referenced branches are left as they are.
+	ResolveRefs bool
+}
+
+// AllOfConflict describes a composition that could not be flattened, since its branches contradict each other
+type AllOfConflict struct {
+	// Location is a JSON pointer of the schema holding the composition
+	Location string
+	// Field is a YAML key of the conflicting field, eg. "type"
+	Field   string
+	Message string
+}
+
+// String returns a human readable description of the conflict
+func (c AllOfConflict) String() string {
+	return fmt.Sprintf("%s: %s: %s", c.Location, c.Field, c.Message)
+}
+
+// allOfFlattener holds the state of flattening
+type allOfFlattener struct {
+	doc       Document
+	cfg       FlattenConfig
+	flattened map[*Schema]bool
+	// inProgress holds schemas being flattened, to detect compositions referencing themselves
+	inProgress map[*Schema]bool
+	conflicts  []AllOfConflict
+}
+
+// FlattenAllOf merges allOf branches of every schema in the document into the schema itself.
+// Properties and required lists are unioned, while constraints are intersected: the lowest maximum, the highest minimum, common enum values and so on.
+// Schemas whose branches contradict each other, eg. by having different types, keep their allOf and are reported as conflicts.
+func (doc Document) FlattenAllOf(cfg FlattenConfig) []AllOfConflict {
+	flattener := allOfFlattener{
+		doc:        doc,
+		cfg:        cfg,
+		flattened:  make(map[*Schema]bool),
+		inProgress: make(map[*Schema]bool),
+	}
+
+	normalizeObjects(reflect.ValueOf(doc.Root), func(object interface{}, location []string) {
+		if schema, ok := object.(*Schema); ok {
+			flattener.flatten(schema, itemsToPointer(location...))
+		}
+	}, nil, make(map[uintptr]bool))
+
+	sort.SliceStable(flattener.conflicts, func(i, j int) bool {
+		return flattener.conflicts[i].Location < flattener.conflicts[j].Location
+	})
+
+	return flattener.conflicts
+}
+
+// flatten replaces the content of the schema with a merge of its own fields and its allOf branches. Returns false when the schema could not be flattened
+func (f *allOfFlattener) flatten(schema *Schema, location string) bool {
+	if len(schema.AllOf) == 0 || f.flattened[schema] {
+		return true
+	}
+
+	if f.inProgress[schema] {
+		f.conflicts = append(f.conflicts, AllOfConflict{Location: location, Field: "allOf", Message: "composition references itself"})
+		return false
+	}
+
+	f.inProgress[schema] = true
+	defer delete(f.inProgress, schema)
+
+	own := *schema
+	own.AllOf = nil
+
+	merger := schemaMerger{flattener: f, location: location}
+	merged := &own
+	for i, branch := range schema.AllOf {
+		branch, ok := f.branch(branch, fmt.Sprintf("%s/allOf/%d", location, i))
+		if !ok {
+			return false
+		}
+
+		merged = merger.merge(merged, branch)
+	}
+
+	if len(merger.conflicts) > 0 {
+		f.conflicts = append(f.conflicts, merger.conflicts...)
+		return false
+	}
+
+	*schema = *merged
+	f.flattened[schema] = true
+	return true
+}
+
+// branch returns a flattened branch of a composition, resolving its reference when configured to
+func (f *allOfFlattener) branch(branch *Schema, location string) (*Schema, bool) {
+	if branch == nil {
+		return &Schema{}, true
+	}
+
+	if branch.Ref != "" {
+		if !f.cfg.ResolveRefs {
+			return nil, false
+		}
+
+		resolved, ok := f.resolve(branch)
+		if !ok {
+			f.conflicts = append(f.conflicts, AllOfConflict{Location: location, Field: RefTag, Message: fmt.Sprintf("could not resolve %s", branch.Ref)})
+			return nil, false
+		}
+
+		// only local references are resolved, so the reference without the hash is a JSON pointer
+		location, branch = strings.TrimPrefix(branch.Ref, string(referenceSeparator)), resolved
+	}
+
+	return branch, f.flatten(branch, location)
+}
+
+// resolve returns a schema referenced locally by the schema
+func (f *allOfFlattener) resolve(schema *Schema) (*Schema, bool) {
+	if !isLocalReference(schema.Ref) {
+		return nil, false
+	}
+
+	object, ok := f.doc.localObject(schema.Ref)
+	if !ok {
+		return nil, false
+	}
+
+	resolved, ok := object.(*Schema)
+	return resolved, ok
+}
+
+// schemaMerger merges schemas, collecting conflicts found on the way
+type schemaMerger struct {
+	flattener *allOfFlattener
+	location  string
+	conflicts []AllOfConflict
+}
+
+// merge returns a new schema that is an intersection of both schemas. Neither of the schemas is changed
+func (m *schemaMerger) merge(a, b *Schema) *Schema {
+	merged := *a
+
+	merged.Title = firstString(a.Title, b.Title)
+	merged.Example = firstString(a.Example, b.Example)
+	merged.Type = m.sameString("type", a.Type, b.Type)
+	merged.Format = m.sameString("format", a.Format, b.Format)
+	merged.Pattern = m.sameString("pattern", a.Pattern, b.Pattern)
+
+	merged.Nullable = a.Nullable && b.Nullable
+	merged.ReadOnly = a.ReadOnly || b.ReadOnly
+	merged.WriteOnly = a.WriteOnly || b.WriteOnly
+	merged.Deprecated = a.Deprecated || b.Deprecated
+	merged.UniqueItems = a.UniqueItems || b.UniqueItems
+
+	merged.Maximum, merged.ExclusiveMaximum = narrowerMaximum(a.Maximum, a.ExclusiveMaximum, b.Maximum, b.ExclusiveMaximum)
+	merged.Minimum, merged.ExclusiveMinimum = narrowerMinimum(a.Minimum, a.ExclusiveMinimum, b.Minimum, b.ExclusiveMinimum)
+	merged.MultipleOf = leastCommonMultiple(a.MultipleOf, b.MultipleOf)
+	merged.MaxLength = lowerLimit(a.MaxLength, b.MaxLength)
+	merged.MinLength = higherLimit(a.MinLength, b.MinLength)
+	merged.MaxItems = lowerLimit(a.MaxItems, b.MaxItems)
+	merged.MinItems = higherLimit(a.MinItems, b.MinItems)
+	merged.MaxProperties = lowerLimit(a.MaxProperties, b.MaxProperties)
+	merged.MinProperties = higherLimit(a.MinProperties, b.MinProperties)
+
+	if merged.Maximum != 0 && merged.Minimum > merged.Maximum {
+		m.conflict("minimum", fmt.Sprintf("minimum %d is greater than maximum %d", merged.Minimum, merged.Maximum))
+	}
+
+	merged.Required = unionStrings(a.Required, b.Required)
+	merged.Enum = m.enum(a.Enum, b.Enum)
+	merged.Properties = m.properties(a.Properties, b.Properties)
+	merged.Items = m.subschema("items", a.Items, b.Items)
+
+	if a.Discriminator == nil {
+		merged.Discriminator = b.Discriminator
+	}
+	if a.ExternalDocs == nil {
+		merged.ExternalDocs = b.ExternalDocs
+	}
+	if len(a.XML.Extensions) == 0 {
+		merged.XML = b.XML
+	}
+
+	merged.OneOf = m.composition("oneOf", a.OneOf, b.OneOf)
+	merged.AnyOf = m.composition("anyOf", a.AnyOf, b.AnyOf)
+	merged.Not = append(append([]*Schema{}, a.Not...), b.Not...)
+	if len(merged.Not) == 0 {
+		merged.Not = nil
+	}
+
+	merged.Extensions = unionExtensions(a.Extensions, b.Extensions)
+	return &merged
+}
+
+// properties unions properties of both schemas, merging schemas of properties present in both
+func (m *schemaMerger) properties(a, b map[string]*Schema) map[string]*Schema {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+
+	properties := make(map[string]*Schema)
+	for name, property := range a {
+		properties[name] = property
+	}
+
+	for name, property := range b {
+		properties[name] = m.subschema(itemsToPointer("properties", name), properties[name], property)
+	}
+
+	return properties
+}
+
+// subschema merges schemas nested in both schemas, eg. items or properties with the same name.
+// Field is a key or a JSON pointer of the nested schema, relative to the merged schemas
+func (m *schemaMerger) subschema(field string, a, b *Schema) *Schema {
+	if a == nil || a == b || equalObjects(a, b) {
+		return b
+	} else if b == nil {
+		return a
+	}
+
+	if a.Ref != "" || b.Ref != "" {
+		if !m.flattener.cfg.ResolveRefs {
+			m.conflict(field, "different schemas that are references can not be merged without resolving references")
+			return a
+		}
+
+		var ok bool
+		if a, ok = m.resolved(field, a); !ok {
+			return b
+		}
+		if b, ok = m.resolved(field, b); !ok {
+			return a
+		}
+	}
+
+	location := m.location
+	m.location = fmt.Sprintf("%s/%s", location, strings.TrimPrefix(field, "/"))
+	defer func() { m.location = location }()
+
+	return m.merge(a, b)
+}
+
+func (m *schemaMerger) resolved(field string, schema *Schema) (*Schema, bool) {
+	if schema.Ref == "" {
+		return schema, true
+	}
+
+	resolved, ok := m.flattener.resolve(schema)
+	if !ok {
+		m.conflict(field, fmt.Sprintf("could not resolve %s", schema.Ref))
+	}
+
+	return resolved, ok
+}
+
+// enum returns values common to both enums. An empty enum allows any value
+func (m *schemaMerger) enum(a, b []string) []string {
+	if len(a) == 0 {
+		return b
+	} else if len(b) == 0 {
+		return a
+	}
+
+	var common []string
+	for _, value := range a {
+		if containsAny([]string{value}, b) {
+			common = append(common, value)
+		}
+	}
+
+	if len(common) == 0 {
+		m.conflict("enum", fmt.Sprintf("enums %v and %v have no common values", a, b))
+	}
+
+	return common
+}
+
+// composition keeps oneOf or anyOf of a single branch. Compositions of many branches can not be expressed as one
+func (m *schemaMerger) composition(field string, a, b []*Schema) []*Schema {
+	if len(a) == 0 {
+		return b
+	} else if len(b) == 0 || equalObjects(a, b) {
+		return a
+	}
+
+	m.conflict(field, "more than one branch defines "+field)
+	return a
+}
+
+func (m *schemaMerger) sameString(field string, a, b string) string {
+	if a != "" && b != "" && a != b {
+		m.conflict(field, fmt.Sprintf("%s and %s are different", a, b))
+	}
+
+	return firstString(a, b)
+}
+
+func (m *schemaMerger) conflict(field, message string) {
+	m.conflicts = append(m.conflicts, AllOfConflict{Location: m.location, Field: field, Message: message})
+}
+
+// narrowerMaximum returns the lower of maximums. Zero means no maximum, following the Schema representation
+func narrowerMaximum(a int, exclusiveA bool, b int, exclusiveB bool) (int, bool) {
+	switch {
+	case a == 0:
+		return b, exclusiveB
+	case b == 0 || a < b:
+		return a, exclusiveA
+	case b < a:
+		return b, exclusiveB
+	default:
+		return a, exclusiveA || exclusiveB
+	}
+}
+
+// narrowerMinimum returns the higher of minimums. Zero means no minimum, following the Schema representation
+func narrowerMinimum(a int, exclusiveA bool, b int, exclusiveB bool) (int, bool) {
+	switch {
+	case a == 0:
+		return b, exclusiveB
+	case b == 0 || a > b:
+		return a, exclusiveA
+	case b > a:
+		return b, exclusiveB
+	default:
+		return a, exclusiveA || exclusiveB
+	}
+}
+
+func lowerLimit(a, b uint) uint {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+
+	return a
+}
+
+func higherLimit(a, b uint) uint {
+	if b > a {
+		return b
+	}
+
+	return a
+}
+
+func leastCommonMultiple(a, b int) int {
+	if a == 0 {
+		return b
+	} else if b == 0 {
+		return a
+	}
+
+	x, y := a, b
+	for y != 0 {
+		x, y = y, x%y
+	}
+
+	return a / x * b
+}
+
+func firstString(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// unionStrings returns values of both lists without duplicates, keeping their order
+func unionStrings(a, b []string) []string {
+	var union []string
+	for _, value := range append(append([]string{}, a...), b...) {
+		if !containsAny([]string{value}, union) {
+			union = append(union, value)
+		}
+	}
+
+	return union
+}
+
+// unionExtensions returns extensions of both objects. Extensions of the first object take precedence
+func unionExtensions(a, b map[string]interface{}) map[string]interface{} {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+
+	union := make(map[string]interface{})
+	for key, value := range b {
+		union[key] = value
+	}
+
+	for key, value := range a {
+		union[key] = value
+	}
+
+	return union
+}
+
+// equalObjects checks whether YAML representations of objects are equal
+func equalObjects(a, b interface{}) bool {
+	dataA, errA := yaml.Marshal(a)
+	dataB, errB := yaml.Marshal(b)
+
+	return errA == nil && errB == nil && string(dataA) == string(dataB)
+}
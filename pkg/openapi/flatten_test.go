@@ -0,0 +1,141 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+const allOfSpec = `openapi: 3.0.0
+info:
+  title: shop
+  version: "1"
+paths: {}
+components:
+  schemas:
+    Base:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: string
+          maxLength: 64
+    Order:
+      allOf:
+      - $ref: '#/components/schemas/Base'
+      - type: object
+        required: [total]
+        properties:
+          id:
+            type: string
+            maxLength: 32
+          total:
+            type: integer
+            minimum: 1
+            maximum: 1000
+      - properties:
+          total:
+            type: integer
+            maximum: 100
+          status:
+            type: string
+            enum: [new, paid, sent]
+      - properties:
+          status:
+            enum: [paid, sent, cancelled]
+`
+
+func TestFlattenAllOf(t *testing.T) {
+	doc := parseYAML(t, allOfSpec)
+
+	conflicts := doc.FlattenAllOf(FlattenConfig{ResolveRefs: true})
+	if len(conflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %v", conflicts)
+	}
+
+	order := doc.Root.Components.Schemas["Order"]
+	if len(order.AllOf) != 0 || order.Type != "object" {
+		t.Errorf("expected allOf to be merged into the schema, got %+v", order)
+	}
+
+	if !reflect.DeepEqual(order.Required, []string{"id", "total"}) {
+		t.Errorf("expected required lists to be unioned, got %v", order.Required)
+	}
+
+	if properties := sortedKeys(order.Properties); !reflect.DeepEqual(properties, []string{"id", "status", "total"}) {
+		t.Errorf("expected properties to be unioned, got %v", properties)
+	}
+
+	if id := order.Properties["id"]; id.MaxLength != 32 {
+		t.Errorf("expected the lower maxLength, got %d", id.MaxLength)
+	}
+
+	if total := order.Properties["total"]; total.Maximum != 100 || total.Minimum != 1 {
+		t.Errorf("expected constraints to be intersected, got minimum %d and maximum %d", total.Minimum, total.Maximum)
+	}
+
+	if status := order.Properties["status"]; !reflect.DeepEqual(status.Enum, []string{"paid", "sent"}) {
+		t.Errorf("expected common enum values, got %v", status.Enum)
+	}
+
+	if base := doc.Root.Components.Schemas["Base"]; base.Properties["id"].MaxLength != 64 {
+		t.Errorf("expected referenced branches to be left unchanged, got %d", base.Properties["id"].MaxLength)
+	}
+}
+
+func TestFlattenAllOfWithoutResolvingRefs(t *testing.T) {
+	doc := parseYAML(t, allOfSpec)
+
+	conflicts := doc.FlattenAllOf(FlattenConfig{})
+	if len(conflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %v", conflicts)
+	}
+
+	if order := doc.Root.Components.Schemas["Order"]; len(order.AllOf) != 4 {
+		t.Errorf("expected the composition with a referenced branch to be kept, got %+v", order)
+	}
+}
+
+func TestFlattenAllOfConflicts(t *testing.T) {
+	doc := parseYAML(t, `openapi: 3.0.0
+info:
+  title: shop
+  version: "1"
+paths: {}
+components:
+  schemas:
+    Id:
+      allOf:
+      - type: string
+      - type: integer
+    Range:
+      allOf:
+      - minimum: 10
+      - maximum: 5
+    Status:
+      allOf:
+      - enum: [new]
+      - enum: [paid]
+    Loop:
+      allOf:
+      - $ref: '#/components/schemas/Loop'
+`)
+
+	var got []string
+	for _, conflict := range doc.FlattenAllOf(FlattenConfig{ResolveRefs: true}) {
+		got = append(got, conflict.String())
+	}
+
+	expected := []string{
+		"/components/schemas/Id: type: string and integer are different",
+		"/components/schemas/Loop: allOf: composition references itself",
+		"/components/schemas/Range: minimum: minimum 10 is greater than maximum 5",
+		"/components/schemas/Status: enum: enums [new] and [paid] have no common values",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected conflicts:\n%v\ngot:\n%v", expected, got)
+	}
+
+	if id := doc.Root.Components.Schemas["Id"]; len(id.AllOf) != 2 || id.Type != "" {
+		t.Errorf("expected conflicting compositions to be kept, got %+v", id)
+	}
+}
@@ -0,0 +1,807 @@
+package openapi
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	gitDirName           = ".git"
+	gitHeadRef           = "HEAD"
+	gitPackedRefsFile    = "packed-refs"
+	gitCommonDirFile     = "commondir"
+	gitSymbolicRefPrefix = "ref: "
+	gitDirFilePrefix     = "gitdir: "
+	gitHashSize          = 20
+	gitMinHashPrefix     = 4
+	gitPackIndexMagic    = 0xff744f63
+	gitPackIndexVersion  = 2
+	gitLargeOffsetFlag   = 0x80000000
+	gitDirMode           = "40000"
+	gitSymlinkMode       = "120000"
+	gitSubmoduleMode     = "160000"
+
+	// maxGitSymbolicRefs limits chains of symbolic refs, eg. HEAD -> refs/heads/main
+	maxGitSymbolicRefs = 10
+	// maxGitSymlinks limits symbolic links followed when resolving a single path, the same as git does
+	maxGitSymlinks = 40
+	// maxGitDeltaChain limits chains of deltas in packfiles, guarding against cycles in corrupted packs
+	maxGitDeltaChain = 4096
+)
+
+var (
+	// ErrRepositoryNotFound occurs when neither the path nor any of its parent directories is a git repository
+	ErrRepositoryNotFound = errors.New("git repository not found")
+	// ErrRevisionNotFound occurs when the revision is neither a ref nor a hash of an object in the repository
+	ErrRevisionNotFound = errors.New("git revision not found")
+	// ErrAmbiguousRevision occurs when an abbreviated hash matches more than one object
+	ErrAmbiguousRevision = errors.New("git revision is ambiguous")
+	// ErrObjectNotFound occurs when an object is missing from the object database
+	ErrObjectNotFound = errors.New("git object not found")
+	// ErrInvalidObject occurs when an object, a pack or a pack index is malformed or unsupported
+	ErrInvalidObject = errors.New("invalid git object")
+)
+
+type gitObjectType int
+
+const (
+	gitCommitObject   gitObjectType = 1
+	gitTreeObject     gitObjectType = 2
+	gitBlobObject     gitObjectType = 3
+	gitTagObject      gitObjectType = 4
+	gitOfsDeltaObject gitObjectType = 6
+	gitRefDeltaObject gitObjectType = 7
+)
+
+var gitObjectTypes = map[string]gitObjectType{
+	"commit": gitCommitObject,
+	"tree":   gitTreeObject,
+	"blob":   gitBlobObject,
+	"tag":    gitTagObject,
+}
+
+type gitHash [gitHashSize]byte
+
+func (h gitHash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// GitLoader reads files as they are at a revision of a git repository, directly from its object database, without checking the revision out.
+// Both loose objects and packfiles are read. Paths are either relative to the root of the repository or absolute paths inside of its working tree.
+type GitLoader struct {
+	workTree  string
+	gitDir    string
+	commonDir string
+	packs     []*gitPackIndex
+	tree      gitHash
+}
+
+// NewGitLoader constructs a loader reading files at the revision - a branch, a tag, a ref, a (abbreviated) commit hash or HEAD when empty.
+// The repository is the first of the path and its parent directories that is a git repository, so the path can be any file or directory inside of the working tree.
+func NewGitLoader(repositoryPath, revision string) (*GitLoader, error) {
+	workTree, gitDir, err := findGitRepository(repositoryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	loader := &GitLoader{
+		workTree:  workTree,
+		gitDir:    gitDir,
+		commonDir: gitDir,
+	}
+
+	commonDir, err := ioutil.ReadFile(filepath.Join(gitDir, gitCommonDirFile))
+	if err == nil {
+		loader.commonDir = strings.TrimSpace(string(commonDir))
+		if !filepath.IsAbs(loader.commonDir) {
+			loader.commonDir = filepath.Join(gitDir, loader.commonDir)
+		}
+	}
+
+	indexes, err := filepath.Glob(filepath.Join(loader.commonDir, "objects", "pack", "*.idx"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, indexPath := range indexes {
+		index, err := readGitPackIndex(indexPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not read pack index %s: %w", indexPath, err)
+		}
+
+		loader.packs = append(loader.packs, index)
+	}
+
+	commit, err := loader.resolve(revision)
+	if err != nil {
+		return nil, err
+	}
+
+	loader.tree, err = loader.peelToTree(commit)
+	if err != nil {
+		return nil, fmt.Errorf("could not read revision %s: %w", revision, err)
+	}
+
+	return loader, nil
+}
+
+// Load reads the file as it is at the revision
+func (l *GitLoader) Load(filePath string) ([]byte, error) {
+	relativePath := filePath
+	if filepath.IsAbs(filePath) {
+		rel, err := filepath.Rel(l.workTree, filePath)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, &fs.PathError{Op: "open", Path: filePath, Err: fs.ErrNotExist}
+		}
+
+		relativePath = rel
+	}
+
+	data, err := l.file(fsPath(relativePath))
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: filePath, Err: err}
+	}
+
+	return data, nil
+}
+
+// file looks the slash-separated path up in the tree of the revision, following symbolic links that stay inside of the tree
+func (l *GitLoader) file(filePath string) ([]byte, error) {
+	items := strings.Split(filePath, pathSeparator)
+	tree := l.tree
+	symlinks := 0
+	for idx := 0; idx < len(items); idx++ {
+		mode, hash, err := l.treeEntry(tree, items[idx])
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case mode == gitSymlinkMode:
+			symlinks++
+			if symlinks > maxGitSymlinks {
+				return nil, fmt.Errorf("too many levels of symbolic links")
+			}
+
+			target, err := l.objectOfType(hash, gitBlobObject)
+			if err != nil {
+				return nil, err
+			}
+
+			if path.IsAbs(string(target)) {
+				return nil, fs.ErrNotExist
+			}
+
+			resolved := path.Join(append(append(items[:idx:idx], string(target)), items[idx+1:]...)...)
+			if resolved == ".." || strings.HasPrefix(resolved, "../") {
+				return nil, fs.ErrNotExist
+			}
+
+			items = strings.Split(resolved, pathSeparator)
+			tree = l.tree
+			idx = -1
+		case mode == gitDirMode:
+			if idx == len(items)-1 {
+				return nil, fmt.Errorf("is a directory")
+			}
+
+			tree = hash
+		case mode == gitSubmoduleMode:
+			return nil, fs.ErrNotExist
+		case idx < len(items)-1:
+			return nil, fs.ErrNotExist
+		default:
+			return l.objectOfType(hash, gitBlobObject)
+		}
+	}
+
+	return nil, fs.ErrNotExist
+}
+
+// treeEntry returns the mode and the hash of the entry of the tree with the name
+func (l *GitLoader) treeEntry(tree gitHash, name string) (string, gitHash, error) {
+	data, err := l.objectOfType(tree, gitTreeObject)
+	if err != nil {
+		return "", gitHash{}, err
+	}
+
+	for len(data) > 0 {
+		modeEnd := bytes.IndexByte(data, ' ')
+		nameEnd := bytes.IndexByte(data, 0)
+		if modeEnd < 0 || nameEnd < modeEnd || len(data) < nameEnd+1+gitHashSize {
+			return "", gitHash{}, fmt.Errorf("%w: malformed tree %s", ErrInvalidObject, tree)
+		}
+
+		var hash gitHash
+		copy(hash[:], data[nameEnd+1:])
+		if string(data[modeEnd+1:nameEnd]) == name {
+			return string(data[:modeEnd]), hash, nil
+		}
+
+		data = data[nameEnd+1+gitHashSize:]
+	}
+
+	return "", gitHash{}, fs.ErrNotExist
+}
+
+// resolve returns the hash the revision points to, looking refs up in the same order as git does
+func (l *GitLoader) resolve(revision string) (gitHash, error) {
+	if revision == "" {
+		revision = gitHeadRef
+	}
+
+	if strings.Contains(revision, "..") {
+		return gitHash{}, fmt.Errorf("%w: %s", ErrRevisionNotFound, revision)
+	}
+
+	candidates := []string{
+		revision,
+		"refs/" + revision,
+		"refs/tags/" + revision,
+		"refs/heads/" + revision,
+		"refs/remotes/" + revision,
+		"refs/remotes/" + revision + "/" + gitHeadRef,
+	}
+
+	for _, name := range candidates {
+		hash, found, err := l.ref(name, 0)
+		if err != nil {
+			return gitHash{}, fmt.Errorf("could not read ref %s: %w", name, err)
+		}
+
+		if found {
+			return hash, nil
+		}
+	}
+
+	return l.abbreviatedHash(revision)
+}
+
+// ref reads a loose or a packed ref, following symbolic refs
+func (l *GitLoader) ref(name string, depth int) (gitHash, bool, error) {
+	if depth > maxGitSymbolicRefs {
+		return gitHash{}, false, fmt.Errorf("too many levels of symbolic refs")
+	}
+
+	for _, dir := range []string{l.gitDir, l.commonDir} {
+		refPath := filepath.Join(dir, filepath.FromSlash(name))
+		info, err := os.Stat(refPath)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(refPath)
+		if err != nil {
+			return gitHash{}, false, err
+		}
+
+		content := strings.TrimSpace(string(data))
+		if strings.HasPrefix(content, gitSymbolicRefPrefix) {
+			return l.ref(strings.TrimPrefix(content, gitSymbolicRefPrefix), depth+1)
+		}
+
+		hash, err := parseGitHash(content)
+		return hash, err == nil, err
+	}
+
+	packedRefs, err := ioutil.ReadFile(filepath.Join(l.commonDir, gitPackedRefsFile))
+	if os.IsNotExist(err) {
+		return gitHash{}, false, nil
+	} else if err != nil {
+		return gitHash{}, false, err
+	}
+
+	for _, line := range strings.Split(string(packedRefs), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[1] != name {
+			continue
+		}
+
+		hash, err := parseGitHash(fields[0])
+		return hash, err == nil, err
+	}
+
+	return gitHash{}, false, nil
+}
+
+// abbreviatedHash returns the hash of the only object, loose or packed, whose hash starts with the prefix
+func (l *GitLoader) abbreviatedHash(prefix string) (gitHash, error) {
+	prefix = strings.ToLower(prefix)
+	if len(prefix) < gitMinHashPrefix || len(prefix) > 2*gitHashSize || strings.Trim(prefix, "0123456789abcdef") != "" {
+		return gitHash{}, fmt.Errorf("%w: %s", ErrRevisionNotFound, prefix)
+	}
+
+	matches := make(map[gitHash]bool)
+
+	entries, _ := ioutil.ReadDir(filepath.Join(l.commonDir, "objects", prefix[:2]))
+	for _, entry := range entries {
+		name := prefix[:2] + entry.Name()
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		if hash, err := parseGitHash(name); err == nil {
+			matches[hash] = true
+		}
+	}
+
+	for _, pack := range l.packs {
+		for _, hash := range pack.withPrefix(prefix) {
+			matches[hash] = true
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return gitHash{}, fmt.Errorf("%w: %s", ErrRevisionNotFound, prefix)
+	case 1:
+		for hash := range matches {
+			return hash, nil
+		}
+	}
+
+	return gitHash{}, fmt.Errorf("%w: %s matches %d objects", ErrAmbiguousRevision, prefix, len(matches))
+}
+
+// peelToTree follows annotated tags and commits down to the tree of the revision
+func (l *GitLoader) peelToTree(hash gitHash) (gitHash, error) {
+	for {
+		objectType, data, err := l.object(hash, 0)
+		if err != nil {
+			return gitHash{}, err
+		}
+
+		switch objectType {
+		case gitTreeObject:
+			return hash, nil
+		case gitCommitObject:
+			hash, err = gitHeaderHash(data, "tree")
+		case gitTagObject:
+			hash, err = gitHeaderHash(data, "object")
+		default:
+			return gitHash{}, fmt.Errorf("%w: %s is neither a commit nor a tag", ErrInvalidObject, hash)
+		}
+
+		if err != nil {
+			return gitHash{}, err
+		}
+	}
+}
+
+func (l *GitLoader) objectOfType(hash gitHash, expected gitObjectType) ([]byte, error) {
+	objectType, data, err := l.object(hash, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if objectType != expected {
+		return nil, fmt.Errorf("%w: unexpected type of %s", ErrInvalidObject, hash)
+	}
+
+	return data, nil
+}
+
+// object reads the object from packfiles or from the loose objects directory
+func (l *GitLoader) object(hash gitHash, depth int) (gitObjectType, []byte, error) {
+	for _, pack := range l.packs {
+		offset, ok := pack.offset(hash)
+		if !ok {
+			continue
+		}
+
+		packFile, err := os.Open(pack.packPath)
+		if err != nil {
+			return 0, nil, err
+		}
+		defer packFile.Close()
+
+		return l.packedObject(packFile, offset, depth)
+	}
+
+	return l.looseObject(hash)
+}
+
+func (l *GitLoader) looseObject(hash gitHash) (gitObjectType, []byte, error) {
+	name := hash.String()
+	objectFile, err := os.Open(filepath.Join(l.commonDir, "objects", name[:2], name[2:]))
+	if os.IsNotExist(err) {
+		return 0, nil, fmt.Errorf("%w: %s", ErrObjectNotFound, name)
+	} else if err != nil {
+		return 0, nil, err
+	}
+	defer objectFile.Close()
+
+	reader, err := zlib.NewReader(objectFile)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %s: %v", ErrInvalidObject, name, err)
+	}
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %s: %v", ErrInvalidObject, name, err)
+	}
+
+	headerEnd := bytes.IndexByte(data, 0)
+	if headerEnd < 0 {
+		return 0, nil, fmt.Errorf("%w: %s has no header", ErrInvalidObject, name)
+	}
+
+	header := strings.SplitN(string(data[:headerEnd]), " ", 2)
+	objectType, ok := gitObjectTypes[header[0]]
+	if !ok || len(header) != 2 {
+		return 0, nil, fmt.Errorf("%w: %s has malformed header", ErrInvalidObject, name)
+	}
+
+	size, err := strconv.Atoi(header[1])
+	if err != nil || size != len(data)-headerEnd-1 {
+		return 0, nil, fmt.Errorf("%w: %s has unexpected size", ErrInvalidObject, name)
+	}
+
+	return objectType, data[headerEnd+1:], nil
+}
+
+// packedObject reads the object at the offset of the packfile, applying deltas to their base objects
+func (l *GitLoader) packedObject(packFile *os.File, offset int64, depth int) (gitObjectType, []byte, error) {
+	if depth > maxGitDeltaChain {
+		return 0, nil, fmt.Errorf("%w: delta chain is too long", ErrInvalidObject)
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(packFile, offset, math.MaxInt64-offset))
+	header, err := reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	objectType := gitObjectType((header >> 4) & 0x07)
+	size := int64(header & 0x0f)
+	for shift := uint(4); header&0x80 != 0; shift += 7 {
+		header, err = reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		size |= int64(header&0x7f) << shift
+	}
+
+	var baseType gitObjectType
+	var base []byte
+	switch objectType {
+	case gitCommitObject, gitTreeObject, gitBlobObject, gitTagObject:
+	case gitOfsDeltaObject:
+		distance, err := readGitOffset(reader)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if distance <= 0 || distance > offset {
+			return 0, nil, fmt.Errorf("%w: delta base offset out of pack", ErrInvalidObject)
+		}
+
+		baseType, base, err = l.packedObject(packFile, offset-distance, depth+1)
+		if err != nil {
+			return 0, nil, err
+		}
+	case gitRefDeltaObject:
+		var baseHash gitHash
+		_, err = io.ReadFull(reader, baseHash[:])
+		if err != nil {
+			return 0, nil, err
+		}
+
+		baseType, base, err = l.object(baseHash, depth+1)
+		if err != nil {
+			return 0, nil, err
+		}
+	default:
+		return 0, nil, fmt.Errorf("%w: unknown packed object type %d", ErrInvalidObject, objectType)
+	}
+
+	inflater, err := zlib.NewReader(reader)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", ErrInvalidObject, err)
+	}
+
+	data, err := ioutil.ReadAll(inflater)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", ErrInvalidObject, err)
+	}
+
+	if int64(len(data)) != size {
+		return 0, nil, fmt.Errorf("%w: packed object has unexpected size", ErrInvalidObject)
+	}
+
+	if base == nil {
+		return objectType, data, nil
+	}
+
+	data, err = applyGitDelta(base, data)
+	return baseType, data, err
+}
+
+// gitPackIndex is a version 2 index of a packfile: hashes of objects, sorted, and offsets of the objects in the packfile
+type gitPackIndex struct {
+	packPath     string
+	fanout       [256]uint32
+	hashes       []byte
+	offsets      []byte
+	largeOffsets []byte
+}
+
+func readGitPackIndex(indexPath string) (*gitPackIndex, error) {
+	data, err := ioutil.ReadFile(indexPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fanoutEnd := 8 + 256*4
+	if len(data) < fanoutEnd || binary.BigEndian.Uint32(data) != gitPackIndexMagic || binary.BigEndian.Uint32(data[4:]) != gitPackIndexVersion {
+		return nil, fmt.Errorf("%w: only version 2 pack indexes are supported", ErrInvalidObject)
+	}
+
+	index := &gitPackIndex{
+		packPath: strings.TrimSuffix(indexPath, ".idx") + ".pack",
+	}
+
+	for idx := range index.fanout {
+		index.fanout[idx] = binary.BigEndian.Uint32(data[8+idx*4:])
+	}
+
+	count := int(index.fanout[255])
+	hashesEnd := fanoutEnd + count*gitHashSize
+	offsetsStart := hashesEnd + count*4
+	offsetsEnd := offsetsStart + count*4
+	if len(data) < offsetsEnd {
+		return nil, fmt.Errorf("%w: truncated pack index", ErrInvalidObject)
+	}
+
+	index.hashes = data[fanoutEnd:hashesEnd]
+	index.offsets = data[offsetsStart:offsetsEnd]
+	index.largeOffsets = data[offsetsEnd:]
+
+	return index, nil
+}
+
+// bounds returns the range of positions of hashes starting with the byte
+func (idx *gitPackIndex) bounds(first byte) (int, int) {
+	start := 0
+	if first > 0 {
+		start = int(idx.fanout[first-1])
+	}
+
+	return start, int(idx.fanout[first])
+}
+
+func (idx *gitPackIndex) hash(position int) gitHash {
+	var hash gitHash
+	copy(hash[:], idx.hashes[position*gitHashSize:])
+	return hash
+}
+
+func (idx *gitPackIndex) offset(hash gitHash) (int64, bool) {
+	start, end := idx.bounds(hash[0])
+	position := start + sort.Search(end-start, func(i int) bool {
+		return bytes.Compare(idx.hashes[(start+i)*gitHashSize:(start+i+1)*gitHashSize], hash[:]) >= 0
+	})
+
+	if position >= end || idx.hash(position) != hash {
+		return 0, false
+	}
+
+	offset := binary.BigEndian.Uint32(idx.offsets[position*4:])
+	if offset&gitLargeOffsetFlag == 0 {
+		return int64(offset), true
+	}
+
+	largeIdx := int(offset&^gitLargeOffsetFlag) * 8
+	if len(idx.largeOffsets) < largeIdx+8 {
+		return 0, false
+	}
+
+	return int64(binary.BigEndian.Uint64(idx.largeOffsets[largeIdx:])), true
+}
+
+func (idx *gitPackIndex) withPrefix(prefix string) []gitHash {
+	first, err := strconv.ParseUint(prefix[:2], 16, 8)
+	if err != nil {
+		return nil
+	}
+
+	var hashes []gitHash
+	start, end := idx.bounds(byte(first))
+	for position := start; position < end; position++ {
+		hash := idx.hash(position)
+		if strings.HasPrefix(hash.String(), prefix) {
+			hashes = append(hashes, hash)
+		}
+	}
+
+	return hashes
+}
+
+// applyGitDelta rebuilds an object from its base and a delta made of copy and insert instructions
+func applyGitDelta(base, delta []byte) ([]byte, error) {
+	reader := bytes.NewReader(delta)
+
+	baseSize, err := binary.ReadUvarint(reader)
+	if err != nil || baseSize != uint64(len(base)) {
+		return nil, fmt.Errorf("%w: delta base has unexpected size", ErrInvalidObject)
+	}
+
+	resultSize, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed delta", ErrInvalidObject)
+	}
+
+	result := make([]byte, 0, resultSize)
+	for {
+		instruction, err := reader.ReadByte()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if instruction&0x80 == 0 {
+			if instruction == 0 {
+				return nil, fmt.Errorf("%w: reserved delta instruction", ErrInvalidObject)
+			}
+
+			inserted := make([]byte, instruction)
+			_, err = io.ReadFull(reader, inserted)
+			if err != nil {
+				return nil, fmt.Errorf("%w: truncated delta", ErrInvalidObject)
+			}
+
+			result = append(result, inserted...)
+			continue
+		}
+
+		var copyOffset, copySize uint64
+		for bit := uint(0); bit < 7; bit++ {
+			if instruction&(1<<bit) == 0 {
+				continue
+			}
+
+			value, err := reader.ReadByte()
+			if err != nil {
+				return nil, fmt.Errorf("%w: truncated delta", ErrInvalidObject)
+			}
+
+			if bit < 4 {
+				copyOffset |= uint64(value) << (8 * bit)
+			} else {
+				copySize |= uint64(value) << (8 * (bit - 4))
+			}
+		}
+
+		if copySize == 0 {
+			copySize = 0x10000
+		}
+
+		if copyOffset+copySize > uint64(len(base)) {
+			return nil, fmt.Errorf("%w: delta copies out of base", ErrInvalidObject)
+		}
+
+		result = append(result, base[copyOffset:copyOffset+copySize]...)
+	}
+
+	if uint64(len(result)) != resultSize {
+		return nil, fmt.Errorf("%w: delta result has unexpected size", ErrInvalidObject)
+	}
+
+	return result, nil
+}
+
+// readGitOffset reads a negative offset of the base of a delta, encoded as in packfiles
+func readGitOffset(reader io.ByteReader) (int64, error) {
+	value, err := reader.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	offset := int64(value & 0x7f)
+	for value&0x80 != 0 {
+		value, err = reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		offset = ((offset + 1) << 7) | int64(value&0x7f)
+	}
+
+	return offset, nil
+}
+
+// gitHeaderHash returns the hash from a header line of a commit or a tag, eg. "tree <hash>"
+func gitHeaderHash(data []byte, header string) (gitHash, error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			break
+		}
+
+		if strings.HasPrefix(line, header+" ") {
+			return parseGitHash(strings.TrimPrefix(line, header+" "))
+		}
+	}
+
+	return gitHash{}, fmt.Errorf("%w: missing %s header", ErrInvalidObject, header)
+}
+
+func parseGitHash(text string) (gitHash, error) {
+	var hash gitHash
+	decoded, err := hex.DecodeString(strings.TrimSpace(text))
+	if err != nil || len(decoded) != gitHashSize {
+		return hash, fmt.Errorf("%w: malformed hash %q", ErrInvalidObject, text)
+	}
+
+	copy(hash[:], decoded)
+	return hash, nil
+}
+
+// findGitRepository returns the working tree and the git directory of the repository the path is in.
+// A .git file pointing to the git directory, as in linked worktrees and submodules, and bare repositories are supported.
+func findGitRepository(repositoryPath string) (string, string, error) {
+	dir, err := filepath.Abs(repositoryPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	for {
+		candidate := filepath.Join(dir, gitDirName)
+		info, err := os.Stat(candidate)
+		if err == nil && info.IsDir() {
+			return dir, candidate, nil
+		}
+
+		if err == nil {
+			data, err := ioutil.ReadFile(candidate)
+			if err != nil {
+				return "", "", err
+			}
+
+			gitDir := strings.TrimSpace(strings.TrimPrefix(string(data), gitDirFilePrefix))
+			if !filepath.IsAbs(gitDir) {
+				gitDir = filepath.Join(dir, gitDir)
+			}
+
+			return dir, gitDir, nil
+		}
+
+		if isGitDirectory(dir) {
+			return dir, dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", fmt.Errorf("%w: %s", ErrRepositoryNotFound, repositoryPath)
+		}
+
+		dir = parent
+	}
+}
+
+// isGitDirectory checks whether the directory is a git directory itself, as in bare repositories
+func isGitDirectory(dir string) bool {
+	head, err := os.Stat(filepath.Join(dir, gitHeadRef))
+	if err != nil || !head.Mode().IsRegular() {
+		return false
+	}
+
+	objects, err := os.Stat(filepath.Join(dir, "objects"))
+	return err == nil && objects.IsDir()
+}
@@ -0,0 +1,145 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRepository creates a repository with two commits of a specification: the first one tagged "v1", the second one on the "main" branch.
+// Tests are skipped when git is not installed.
+func gitRepository(t *testing.T) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"specs/openapi.yaml": `openapi: 3.0.0
+components:
+  schemas:
+    Pet:
+      $ref: 'common.yaml#/components/schemas/Pet'
+`,
+		"specs/common.yaml": petSchema("v1"),
+	})
+
+	err := os.Symlink("common.yaml", filepath.Join(dir, "specs", "link.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runGit(t, dir, "init", "-q", "-b", "main")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "v1")
+	runGit(t, dir, "tag", "v1")
+
+	err = ioutil.WriteFile(filepath.Join(dir, "specs", "common.yaml"), []byte(petSchema("v2")), os.FileMode(0644))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runGit(t, dir, "commit", "-q", "-a", "-m", "v2")
+	return dir
+}
+
+func petSchema(title string) string {
+	return fmt.Sprintf("components:\n  schemas:\n    Pet:\n      type: object\n      title: %s\n", title)
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %v: %s", strings.Join(args, " "), err, out)
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+func TestGitLoader(t *testing.T) {
+	dir := gitRepository(t)
+	firstCommit := runGit(t, dir, "rev-parse", "v1")
+
+	for _, storage := range []string{"loose objects", "packfiles"} {
+		if storage == "packfiles" {
+			runGit(t, dir, "gc", "-q", "--aggressive")
+			if objects := runGit(t, dir, "count-objects", "-v"); !strings.HasPrefix(objects, "count: 0\n") {
+				t.Fatalf("expected all objects to be packed, got %s", objects)
+			}
+		}
+
+		tests := []struct {
+			revision string
+			expected string
+		}{
+			{revision: "", expected: "v2"},
+			{revision: "main", expected: "v2"},
+			{revision: "v1", expected: "v1"},
+			{revision: "refs/tags/v1", expected: "v1"},
+			{revision: firstCommit, expected: "v1"},
+			{revision: firstCommit[:8], expected: "v1"},
+		}
+
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s at %q", storage, tt.revision), func(t *testing.T) {
+				loader, err := NewGitLoader(filepath.Join(dir, "specs"), tt.revision)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				doc, err := ParseDocument(Config{Loader: loader}, filepath.Join(dir, "specs", "openapi.yaml"))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if title := doc.Root.Components.Schemas["Pet"].Title; title != tt.expected {
+					t.Errorf("expected the schema at %s, got %s", tt.expected, title)
+				}
+
+				data, err := loader.Load("specs/link.yaml")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(data) != petSchema(tt.expected) {
+					t.Errorf("expected the symlink to be followed inside of the revision, got %q", data)
+				}
+			})
+		}
+	}
+}
+
+func TestGitLoaderErrors(t *testing.T) {
+	dir := gitRepository(t)
+
+	_, err := NewGitLoader(dir, "v3")
+	if !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("unknown revision: expected %v, got %v", ErrRevisionNotFound, err)
+	}
+
+	_, err = NewGitLoader(t.TempDir(), "")
+	if !errors.Is(err, ErrRepositoryNotFound) {
+		t.Errorf("not a repository: expected %v, got %v", ErrRepositoryNotFound, err)
+	}
+
+	loader, err := NewGitLoader(dir, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = loader.Load("specs/missing.yaml")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: expected %v, got %v", fs.ErrNotExist, err)
+	}
+}
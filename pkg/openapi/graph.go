@@ -0,0 +1,333 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// GraphNodeKind describes what kind of specification element a node of the reference graph represents
+type GraphNodeKind string
+
+const (
+	// FileNode represents a source file. Refs placed outside of components and operations, as well as refs to whole files, are attributed to file nodes
+	FileNode GraphNodeKind = "file"
+	// ComponentNode represents a single component, eg. #/components/schemas/Money
+	ComponentNode GraphNodeKind = "component"
+	// OperationNode represents a single operation of a path or a webhook, eg. POST /orders
+	OperationNode GraphNodeKind = "operation"
+)
+
+const (
+	pathsKey    = "paths"
+	webhooksKey = "webhooks"
+)
+
+var (
+	// ErrNoMatchingNode occurs when a graph query does not match any node
+	ErrNoMatchingNode = errors.New("no node matches the query")
+)
+
+// GraphNode is a component, an operation or a file of the specification.
+// ID of the node is a reference to the element relative to the root file of the specification.
+type GraphNode struct {
+	ID    string        `json:"id"`
+	Kind  GraphNodeKind `json:"kind"`
+	File  string        `json:"file"`
+	Label string        `json:"label"`
+	// OperationID is set only for operation nodes that have an operationId
+	OperationID string `json:"operationId,omitempty"`
+}
+
+// GraphEdge is a $ref found in the node identified by From, pointing to the node identified by To
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Graph holds references between components, operations and files of the specification, including all referenced files
+type Graph struct {
+	Nodes map[string]*GraphNode
+	Edges []GraphEdge
+}
+
+// BuildGraph reads the root file along with all referenced files and creates a graph of their references.
+// References are not resolved, so the graph reflects the structure of source files.
+func BuildGraph(rootPath string) (*Graph, error) {
+	rootPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := readRawDocuments(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := graphBuilder{
+		rootDirectory: filepath.Dir(rootPath),
+		graph: &Graph{
+			Nodes: make(map[string]*GraphNode),
+		},
+		edges: make(map[GraphEdge]bool),
+	}
+
+	for _, document := range documents {
+		builder.addDocumentNodes(document)
+	}
+
+	for _, document := range documents {
+		builder.addDocumentEdges(document)
+	}
+
+	sort.Slice(builder.graph.Edges, func(i, j int) bool {
+		return edgeLess(builder.graph.Edges[i], builder.graph.Edges[j])
+	})
+
+	return builder.graph, nil
+}
+
+// Find returns IDs of nodes matching the query. The query can be an exact node ID, a label of an operation (eg. "POST /orders"),
+// an operationId or a suffix of a component ID (eg. "schemas/Money" or "Money").
+func (g *Graph) Find(query string) ([]string, error) {
+	var ids []string
+
+	for id, node := range g.Nodes {
+		if g.matches(node, query) {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return ids, fmt.Errorf("%w: %s", ErrNoMatchingNode, query)
+	}
+
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Dependencies returns a subgraph of nodes reachable from provided nodes by following refs, including provided nodes
+func (g *Graph) Dependencies(ids ...string) *Graph {
+	return g.subgraph(ids, func(edge GraphEdge) (string, string) {
+		return edge.From, edge.To
+	})
+}
+
+// Dependents returns a subgraph of nodes that reach provided nodes through refs, directly or indirectly, including provided nodes
+func (g *Graph) Dependents(ids ...string) *Graph {
+	return g.subgraph(ids, func(edge GraphEdge) (string, string) {
+		return edge.To, edge.From
+	})
+}
+
+// SortedNodes returns nodes of the graph sorted by their IDs
+func (g *Graph) SortedNodes() []*GraphNode {
+	var nodes []*GraphNode
+	for _, node := range g.Nodes {
+		nodes = append(nodes, node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+
+	return nodes
+}
+
+func (g *Graph) matches(node *GraphNode, query string) bool {
+	if node.ID == query {
+		return true
+	}
+
+	switch node.Kind {
+	case OperationNode:
+		return strings.EqualFold(node.Label, query) || node.OperationID == query
+	case ComponentNode:
+		return node.Label == query || strings.HasSuffix(node.Label, pathSeparator+query)
+	default:
+		return false
+	}
+}
+
+// subgraph walks edges from provided nodes in the direction specified by the direction func, which returns the start and the end of the edge.
+func (g *Graph) subgraph(ids []string, direction func(edge GraphEdge) (string, string)) *Graph {
+	adjacent := make(map[string][]string)
+	for _, edge := range g.Edges {
+		from, to := direction(edge)
+		adjacent[from] = append(adjacent[from], to)
+	}
+
+	visited := make(map[string]bool)
+	pending := append([]string{}, ids...)
+	for len(pending) > 0 {
+		id := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if visited[id] {
+			continue
+		}
+
+		visited[id] = true
+		pending = append(pending, adjacent[id]...)
+	}
+
+	subgraph := &Graph{
+		Nodes: make(map[string]*GraphNode),
+	}
+
+	for id := range visited {
+		if node, ok := g.Nodes[id]; ok {
+			subgraph.Nodes[id] = node
+		}
+	}
+
+	for _, edge := range g.Edges {
+		if visited[edge.From] && visited[edge.To] {
+			subgraph.Edges = append(subgraph.Edges, edge)
+		}
+	}
+
+	return subgraph
+}
+
+type graphBuilder struct {
+	rootDirectory string
+	graph         *Graph
+	edges         map[GraphEdge]bool
+}
+
+func (b *graphBuilder) addDocumentNodes(document *rawDocument) {
+	b.node(rawLocation{file: document.path})
+
+	components, _ := rawNodeByPointer(document.content, itemsToPointer(ComponentsItem)).(yaml.MapSlice)
+	for _, componentsOfType := range components {
+		componentType := fmt.Sprint(componentsOfType.Key)
+		named, _ := componentsOfType.Value.(yaml.MapSlice)
+		for _, component := range named {
+			b.node(rawLocation{
+				file:    document.path,
+				pointer: itemsToPointer(ComponentsItem, componentType, fmt.Sprint(component.Key)),
+			})
+		}
+	}
+
+	for _, key := range []string{pathsKey, webhooksKey} {
+		pathItems, _ := rawNodeByPointer(document.content, itemsToPointer(key)).(yaml.MapSlice)
+		for _, pathItem := range pathItems {
+			operations, _ := pathItem.Value.(yaml.MapSlice)
+			for _, operation := range operations {
+				method := fmt.Sprint(operation.Key)
+				if !isHTTPMethod(method) {
+					continue
+				}
+
+				node := b.node(rawLocation{
+					file:    document.path,
+					pointer: itemsToPointer(key, fmt.Sprint(pathItem.Key), method),
+				})
+
+				operationFields, _ := operation.Value.(yaml.MapSlice)
+				if operationID, ok := mapSliceGet(operationFields, "operationId"); ok {
+					node.OperationID = fmt.Sprint(operationID)
+				}
+			}
+		}
+	}
+}
+
+func (b *graphBuilder) addDocumentEdges(document *rawDocument) {
+	document.walkRefs(func(path []string, ref string) (string, bool) {
+		from := b.node(rawLocation{
+			file:    document.path,
+			pointer: itemsToPointer(graphNodeItems(path)...),
+		})
+		to := b.node(document.locate(ref))
+
+		edge := GraphEdge{From: from.ID, To: to.ID}
+		if !b.edges[edge] {
+			b.edges[edge] = true
+			b.graph.Edges = append(b.graph.Edges, edge)
+		}
+
+		return ref, false
+	})
+}
+
+// node returns a graph node of the component, operation or file containing the location, creating the node if it does not exist yet.
+func (b *graphBuilder) node(location rawLocation) *GraphNode {
+	file, err := filepath.Rel(b.rootDirectory, location.file)
+	if err != nil {
+		file = location.file
+	}
+	file = filepath.ToSlash(file)
+
+	items := graphNodeItems(pointerToItems(location.pointer))
+	id := file
+	if len(items) > 0 {
+		id = fmt.Sprintf("%s%c%s", file, referenceSeparator, itemsToPointer(items...))
+	}
+
+	if node, ok := b.graph.Nodes[id]; ok {
+		return node
+	}
+
+	node := &GraphNode{
+		ID:    id,
+		Kind:  FileNode,
+		File:  file,
+		Label: file,
+	}
+
+	switch {
+	case len(items) == 3 && items[0] == ComponentsItem:
+		node.Kind = ComponentNode
+		node.Label = fmt.Sprintf("%s/%s", items[1], items[2])
+	case len(items) == 3:
+		node.Kind = OperationNode
+		node.Label = fmt.Sprintf("%s %s", strings.ToUpper(items[2]), items[1])
+	}
+
+	b.graph.Nodes[id] = node
+	return node
+}
+
+// graphNodeItems shortens the path to an object to the path of the component or the operation containing it.
+// Empty result means that the object belongs directly to the file.
+func graphNodeItems(items []string) []string {
+	if len(items) < 3 {
+		return nil
+	}
+
+	switch items[0] {
+	case ComponentsItem:
+		return items[:3]
+	case pathsKey, webhooksKey:
+		if isHTTPMethod(items[2]) {
+			return items[:3]
+		}
+	}
+
+	return nil
+}
+
+func isHTTPMethod(name string) bool {
+	for _, method := range HTTPMethods {
+		if method == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func edgeLess(edgeI, edgeJ GraphEdge) bool {
+	if edgeI.From != edgeJ.From {
+		return edgeI.From < edgeJ.From
+	}
+
+	return edgeI.To < edgeJ.To
+}
@@ -0,0 +1,154 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// GraphFormat is an output format of the reference graph
+type GraphFormat string
+
+const (
+	// DOTFormat is a Graphviz DOT language
+	DOTFormat GraphFormat = "dot"
+	// MermaidFormat is a Mermaid flowchart
+	MermaidFormat GraphFormat = "mermaid"
+	// JSONFormat is a JSON object with lists of nodes and edges
+	JSONFormat GraphFormat = "json"
+)
+
+// Write writes the graph to the writer in the specified format
+func (g *Graph) Write(w io.Writer, format GraphFormat) error {
+	switch format {
+	case DOTFormat:
+		return g.WriteDOT(w)
+	case MermaidFormat:
+		return g.WriteMermaid(w)
+	case JSONFormat:
+		return g.WriteJSON(w)
+	default:
+		return fmt.Errorf("unsupported graph format %s", format)
+	}
+}
+
+// WriteDOT writes the graph in Graphviz DOT language. Nodes are grouped in clusters by their files
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var out strings.Builder
+
+	out.WriteString("digraph openapi {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for idx, file := range g.files() {
+		fmt.Fprintf(&out, "\tsubgraph cluster_%d {\n\t\tlabel=%s;\n", idx, dotQuote(file))
+		for _, node := range g.fileNodes(file) {
+			shape := "box"
+			switch node.Kind {
+			case FileNode:
+				shape = "note"
+			case OperationNode:
+				shape = "ellipse"
+			}
+
+			fmt.Fprintf(&out, "\t\t%s [label=%s, shape=%s];\n", dotQuote(node.ID), dotQuote(node.Label), shape)
+		}
+		out.WriteString("\t}\n")
+	}
+
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&out, "\t%s -> %s;\n", dotQuote(edge.From), dotQuote(edge.To))
+	}
+	out.WriteString("}\n")
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart. Nodes are grouped in subgraphs by their files
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	var out strings.Builder
+
+	// mermaid identifiers cannot contain most of the characters used in refs, hence nodes are given generated identifiers
+	identifiers := make(map[string]string)
+	out.WriteString("flowchart LR\n")
+	for fileIdx, file := range g.files() {
+		fmt.Fprintf(&out, "\tsubgraph f%d[%s]\n", fileIdx, mermaidQuote(file))
+		for _, node := range g.fileNodes(file) {
+			identifier := fmt.Sprintf("n%d", len(identifiers))
+			identifiers[node.ID] = identifier
+
+			switch node.Kind {
+			case OperationNode:
+				fmt.Fprintf(&out, "\t\t%s([%s])\n", identifier, mermaidQuote(node.Label))
+			case FileNode:
+				fmt.Fprintf(&out, "\t\t%s[/%s/]\n", identifier, mermaidQuote(node.Label))
+			default:
+				fmt.Fprintf(&out, "\t\t%s[%s]\n", identifier, mermaidQuote(node.Label))
+			}
+		}
+		out.WriteString("\tend\n")
+	}
+
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&out, "\t%s --> %s\n", identifiers[edge.From], identifiers[edge.To])
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// WriteJSON writes the graph as a JSON object with "nodes" and "edges" lists
+func (g *Graph) WriteJSON(w io.Writer) error {
+	nodes := g.SortedNodes()
+	if nodes == nil {
+		nodes = []*GraphNode{}
+	}
+
+	edges := g.Edges
+	if edges == nil {
+		edges = []GraphEdge{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Nodes []*GraphNode `json:"nodes"`
+		Edges []GraphEdge  `json:"edges"`
+	}{
+		Nodes: nodes,
+		Edges: edges,
+	})
+}
+
+func (g *Graph) files() []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, node := range g.Nodes {
+		if !seen[node.File] {
+			seen[node.File] = true
+			files = append(files, node.File)
+		}
+	}
+
+	sort.Strings(files)
+	return files
+}
+
+func (g *Graph) fileNodes(file string) []*GraphNode {
+	var nodes []*GraphNode
+	for _, node := range g.SortedNodes() {
+		if node.File == file {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes
+}
+
+func dotQuote(value string) string {
+	return fmt.Sprintf("\"%s\"", strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(value))
+}
+
+func mermaidQuote(value string) string {
+	return fmt.Sprintf("\"%s\"", strings.ReplaceAll(value, "\"", "#quot;"))
+}
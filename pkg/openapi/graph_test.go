@@ -0,0 +1,231 @@
+package openapi
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var graphFiles = map[string]string{
+	"openapi.yaml": `openapi: 3.0.0
+info:
+  title: orders
+  version: "1"
+paths:
+  /orders:
+    post:
+      operationId: createOrder
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Order'
+      responses:
+        "200":
+          description: ok
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: 'common.yaml#/components/schemas/Money'
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        total:
+          $ref: 'common.yaml#/components/schemas/Money'
+    Unused:
+      type: string
+`,
+	"common.yaml": `components:
+  schemas:
+    Money:
+      type: object
+      properties:
+        currency:
+          $ref: '#/components/schemas/Currency'
+    Currency:
+      type: string
+`,
+}
+
+func buildTestGraph(t *testing.T) *Graph {
+	t.Helper()
+
+	dir := writeFiles(t, graphFiles)
+	graph, err := BuildGraph(filepath.Join(dir, "openapi.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return graph
+}
+
+func graphNodeIDs(graph *Graph) []string {
+	var ids []string
+	for id := range graph.Nodes {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+	return ids
+}
+
+func TestBuildGraph(t *testing.T) {
+	graph := buildTestGraph(t)
+
+	expectedEdges := []GraphEdge{
+		{From: "common.yaml#/components/schemas/Money", To: "common.yaml#/components/schemas/Currency"},
+		{From: "openapi.yaml#/components/schemas/Order", To: "common.yaml#/components/schemas/Money"},
+		{From: "openapi.yaml#/paths/~1orders/get", To: "common.yaml#/components/schemas/Money"},
+		{From: "openapi.yaml#/paths/~1orders/post", To: "openapi.yaml#/components/schemas/Order"},
+	}
+	if !reflect.DeepEqual(graph.Edges, expectedEdges) {
+		t.Errorf("expected edges %v, got %v", expectedEdges, graph.Edges)
+	}
+
+	post := graph.Nodes["openapi.yaml#/paths/~1orders/post"]
+	if post == nil || post.Kind != OperationNode || post.Label != "POST /orders" || post.OperationID != "createOrder" {
+		t.Errorf("unexpected operation node %+v", post)
+	}
+
+	money := graph.Nodes["common.yaml#/components/schemas/Money"]
+	if money == nil || money.Kind != ComponentNode || money.File != "common.yaml" {
+		t.Errorf("unexpected component node %+v", money)
+	}
+
+	if _, ok := graph.Nodes["openapi.yaml#/components/schemas/Unused"]; !ok {
+		t.Errorf("expected components without refs to be nodes of the graph")
+	}
+}
+
+func TestGraphFind(t *testing.T) {
+	graph := buildTestGraph(t)
+
+	for query, expected := range map[string][]string{
+		"POST /orders":                     {"openapi.yaml#/paths/~1orders/post"},
+		"post /orders":                     {"openapi.yaml#/paths/~1orders/post"},
+		"createOrder":                      {"openapi.yaml#/paths/~1orders/post"},
+		"schemas/Money":                    {"common.yaml#/components/schemas/Money"},
+		"Order":                            {"openapi.yaml#/components/schemas/Order"},
+		"openapi.yaml#/paths/~1orders/get": {"openapi.yaml#/paths/~1orders/get"},
+	} {
+		ids, err := graph.Find(query)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", query, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(ids, expected) {
+			t.Errorf("%s: expected %v, got %v", query, expected, ids)
+		}
+	}
+
+	_, err := graph.Find("DELETE /orders")
+	if !errors.Is(err, ErrNoMatchingNode) {
+		t.Errorf("expected %v, got %v", ErrNoMatchingNode, err)
+	}
+}
+
+func TestGraphDependenciesAndDependents(t *testing.T) {
+	graph := buildTestGraph(t)
+
+	dependencies := graph.Dependencies("openapi.yaml#/paths/~1orders/post")
+	expected := []string{
+		"common.yaml#/components/schemas/Currency",
+		"common.yaml#/components/schemas/Money",
+		"openapi.yaml#/components/schemas/Order",
+		"openapi.yaml#/paths/~1orders/post",
+	}
+	if ids := graphNodeIDs(dependencies); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("dependencies: expected %v, got %v", expected, ids)
+	}
+	if len(dependencies.Edges) != 3 {
+		t.Errorf("dependencies: expected 3 edges, got %v", dependencies.Edges)
+	}
+
+	dependents := graph.Dependents("common.yaml#/components/schemas/Money")
+	expected = []string{
+		"common.yaml#/components/schemas/Money",
+		"openapi.yaml#/components/schemas/Order",
+		"openapi.yaml#/paths/~1orders/get",
+		"openapi.yaml#/paths/~1orders/post",
+	}
+	if ids := graphNodeIDs(dependents); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("dependents: expected %v, got %v", expected, ids)
+	}
+}
+
+func TestGraphWrite(t *testing.T) {
+	graph := buildTestGraph(t).Dependencies("openapi.yaml#/components/schemas/Order")
+
+	for format, expected := range map[GraphFormat]string{
+		DOTFormat: `digraph openapi {
+	rankdir=LR;
+	node [shape=box];
+	subgraph cluster_0 {
+		label="common.yaml";
+		"common.yaml#/components/schemas/Currency" [label="schemas/Currency", shape=box];
+		"common.yaml#/components/schemas/Money" [label="schemas/Money", shape=box];
+	}
+	subgraph cluster_1 {
+		label="openapi.yaml";
+		"openapi.yaml#/components/schemas/Order" [label="schemas/Order", shape=box];
+	}
+	"common.yaml#/components/schemas/Money" -> "common.yaml#/components/schemas/Currency";
+	"openapi.yaml#/components/schemas/Order" -> "common.yaml#/components/schemas/Money";
+}
+`,
+		MermaidFormat: `flowchart LR
+	subgraph f0["common.yaml"]
+		n0["schemas/Currency"]
+		n1["schemas/Money"]
+	end
+	subgraph f1["openapi.yaml"]
+		n2["schemas/Order"]
+	end
+	n1 --> n0
+	n2 --> n1
+`,
+	} {
+		var out strings.Builder
+		err := graph.Write(&out, format)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", format, err)
+			continue
+		}
+
+		if out.String() != expected {
+			t.Errorf("%s: unexpected output\ngot:\n%s\nexpected:\n%s", format, out.String(), expected)
+		}
+	}
+
+	var out strings.Builder
+	err := graph.Write(&out, JSONFormat)
+	if err != nil {
+		t.Fatalf("json: unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"from": "openapi.yaml#/components/schemas/Order",`) || !strings.Contains(out.String(), `"kind": "component"`) {
+		t.Errorf("json: unexpected output\n%s", out.String())
+	}
+}
+
+func TestGraphWriteJSONEmpty(t *testing.T) {
+	var out strings.Builder
+	err := (&Graph{Nodes: make(map[string]*GraphNode)}).WriteJSON(&out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "{\n  \"nodes\": [],\n  \"edges\": []\n}\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
@@ -0,0 +1,109 @@
+package openapi
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files keyed by slash-separated paths into a temporary directory and returns the directory
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(path), os.FileMode(0755))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ioutil.WriteFile(path, []byte(content), os.FileMode(0644))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// readFile returns the content of the file, failing the test when it cannot be read
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+// parseFiles writes the files and parses the root one with its references resolved
+func parseFiles(t *testing.T, cfg Config, files map[string]string, root string) Document {
+	t.Helper()
+
+	dir := writeFiles(t, files)
+	doc, err := ParseDocument(cfg, filepath.Join(dir, filepath.FromSlash(root)))
+	if err != nil {
+		t.Fatalf("could not parse %s: %v", root, err)
+	}
+
+	return doc
+}
+
+// parseYAML parses the document without resolving its references
+func parseYAML(t testing.TB, content string) Document {
+	t.Helper()
+
+	doc := NewDocument(Config{})
+	err := doc.Read(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("could not parse the document: %v", err)
+	}
+
+	return doc
+}
+
+// documentYAML returns the document marshaled to YAML
+func documentYAML(t *testing.T, doc Document) string {
+	t.Helper()
+
+	data, err := doc.YAML()
+	if err != nil {
+		t.Fatalf("could not marshal the document: %v", err)
+	}
+
+	return string(data)
+}
+
+// assertFileContent compares the content of the file with the expected one
+func assertFileContent(t *testing.T, path string, expected string) {
+	t.Helper()
+
+	if got := readFile(t, path); got != expected {
+		t.Errorf("%s: unexpected content\ngot:\n%s\nexpected:\n%s", path, got, expected)
+	}
+}
+
+// containsLine checks whether the text has the line
+func containsLine(text string, line string) bool {
+	for _, textLine := range strings.Split(text, "\n") {
+		if textLine == line {
+			return true
+		}
+	}
+
+	return false
+}
+
+// assertYAML compares YAML outputs, reporting both of them when they differ
+func assertYAML(t *testing.T, name string, got string, expected string) {
+	t.Helper()
+
+	if got != expected {
+		t.Errorf("%s: unexpected YAML\ngot:\n%s\nexpected:\n%s", name, got, expected)
+	}
+}
@@ -0,0 +1,266 @@
+package openapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultHTTPTimeout limits the time of fetching a single document when HTTPLoader has no client
+	DefaultHTTPTimeout = 30 * time.Second
+
+	maxRedirects      = 10
+	etagHeader        = "ETag"
+	ifNoneMatchHeader = "If-None-Match"
+	cachedBodySuffix  = ".yaml"
+	cachedETagSuffix  = ".etag"
+)
+
+var (
+	// ErrHostNotAllowed occurs when a document is referenced by an URL with a host that is not on the allow-list
+	ErrHostNotAllowed = errors.New("host is not allowed")
+	// ErrNotCached occurs in offline mode, when a document referenced by an URL has not been cached
+	ErrNotCached = errors.New("document is not cached")
+	// ErrUnexpectedStatus occurs when fetching a document ends with a status other than 200 OK or 304 Not Modified
+	ErrUnexpectedStatus = errors.New("unexpected response status")
+	// ErrResponseTooLarge occurs when a fetched document is larger than MaxBytes of HTTPLoader
+	ErrResponseTooLarge = errors.New("response exceeds the size limit")
+	// ErrTooManyRedirects occurs when fetching a document is redirected more times than the default client allows
+	ErrTooManyRedirects = errors.New("too many redirects")
+)
+
+// HTTPClient sends HTTP requests. *http.Client satisfies it, so a client with a custom transport can be used to fetch documents
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// HTTPLoader fetches documents referenced by HTTP(S) URLs, eg. "https://schemas.example.com/common.yaml#/components/schemas/Money".
+// Paths that are not URLs are read by the fallback loader. Relative references inside fetched documents are resolved against their URLs.
+type HTTPLoader struct {
+	// Client fetches documents. When nil, http.Client with Timeout is used. Redirects of *http.Client are checked against AllowedHosts as well
+	Client HTTPClient
+	// Timeout limits the time of fetching a single document by the default client. DefaultHTTPTimeout is used when zero
+	Timeout time.Duration
+	// AllowedHosts limits hosts documents can be fetched from, eg. "schemas.example.com", "localhost:8080" or "*.example.com". All hosts are allowed when empty
+	AllowedHosts []string
+	// MaxBytes limits the size of a fetched document, in bytes. Not limited when zero
+	MaxBytes int64
+	// CacheDir is a directory where fetched documents are cached along with their ETags, which are used to revalidate cached documents. Documents are not cached when empty
+	CacheDir string
+	// Offline makes documents read only from the cache, without any requests
+	Offline bool
+	// Fallback reads paths that are not URLs. OSLoader is used when nil
+	Fallback Loader
+}
+
+// Load fetches the document when the path is an URL, or reads it with the fallback loader otherwise
+func (l HTTPLoader) Load(path string) ([]byte, error) {
+	return l.LoadContext(context.Background(), path)
+}
+
+// LoadContext fetches the document with a request canceled along with the context when the path is an URL, or reads it with the fallback loader otherwise
+func (l HTTPLoader) LoadContext(ctx context.Context, path string) ([]byte, error) {
+	if !isURL(path) {
+		if l.Fallback == nil {
+			return loadContext(ctx, OSLoader{}, path)
+		}
+
+		return loadContext(ctx, l.Fallback, path)
+	}
+
+	documentURL, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !l.allowed(documentURL) {
+		return nil, fmt.Errorf("%w: %s", ErrHostNotAllowed, documentURL.Host)
+	}
+
+	cachedBody, cachedETag, cached := l.cached(path)
+	if l.Offline {
+		if !cached {
+			return nil, fmt.Errorf("%w: %s", ErrNotCached, path)
+		}
+
+		return cachedBody, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached && cachedETag != "" {
+		req.Header.Set(ifNoneMatchHeader, cachedETag)
+	}
+
+	res, err := l.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch %s: %w", path, err)
+	}
+	defer res.Body.Close()
+
+	if res.Request != nil && !l.allowed(res.Request.URL) {
+		return nil, fmt.Errorf("%w: %s redirected to %s", ErrHostNotAllowed, path, res.Request.URL.Host)
+	}
+
+	switch {
+	case res.StatusCode == http.StatusNotModified && cached:
+		return cachedBody, nil
+	case res.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%w: %s responded with %s", ErrUnexpectedStatus, path, res.Status)
+	}
+
+	body, err := l.read(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch %s: %w", path, err)
+	}
+
+	err = l.cache(path, body, res.Header.Get(etagHeader))
+	if err != nil {
+		return nil, fmt.Errorf("could not cache %s: %w", path, err)
+	}
+
+	return body, nil
+}
+
+// client returns the client fetching documents, which checks hosts of redirects against the allow-list
+func (l HTTPLoader) client() HTTPClient {
+	if l.Client == nil {
+		timeout := l.Timeout
+		if timeout == 0 {
+			timeout = DefaultHTTPTimeout
+		}
+
+		return &http.Client{Timeout: timeout, CheckRedirect: l.checkRedirect(nil)}
+	}
+
+	client, ok := l.Client.(*http.Client)
+	if !ok {
+		return l.Client
+	}
+
+	checkedClient := *client
+	checkedClient.CheckRedirect = l.checkRedirect(client.CheckRedirect)
+	return &checkedClient
+}
+
+// checkRedirect returns a redirect policy rejecting hosts that are not on the allow-list, before the next policy or the default limit of redirects is applied
+func (l HTTPLoader) checkRedirect(next func(req *http.Request, via []*http.Request) error) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if !l.allowed(req.URL) {
+			return fmt.Errorf("%w: redirected to %s", ErrHostNotAllowed, req.URL.Host)
+		}
+
+		if next != nil {
+			return next(req, via)
+		}
+
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, maxRedirects)
+		}
+
+		return nil
+	}
+}
+
+// read reads the body of the response, up to MaxBytes when it is set
+func (l HTTPLoader) read(body io.Reader) ([]byte, error) {
+	if l.MaxBytes <= 0 {
+		return ioutil.ReadAll(body)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(body, l.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > l.MaxBytes {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, l.MaxBytes)
+	}
+
+	return data, nil
+}
+
+// allowed checks the host of the URL, with and without the port, against the allow-list. Patterns starting with "*." match subdomains
+func (l HTTPLoader) allowed(documentURL *url.URL) bool {
+	if len(l.AllowedHosts) == 0 {
+		return true
+	}
+
+	for _, allowed := range l.AllowedHosts {
+		for _, host := range []string{documentURL.Host, documentURL.Hostname()} {
+			if strings.EqualFold(host, allowed) {
+				return true
+			}
+
+			if strings.HasPrefix(allowed, "*.") && strings.HasSuffix(strings.ToLower(host), strings.ToLower(allowed[1:])) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// cached returns the cached body of the document and its ETag
+func (l HTTPLoader) cached(path string) ([]byte, string, bool) {
+	if l.CacheDir == "" {
+		return nil, "", false
+	}
+
+	cachePath := l.cachePath(path)
+	body, err := ioutil.ReadFile(cachePath + cachedBodySuffix)
+	if err != nil {
+		return nil, "", false
+	}
+
+	etag, _ := ioutil.ReadFile(cachePath + cachedETagSuffix)
+	return body, string(etag), true
+}
+
+func (l HTTPLoader) cache(path string, body []byte, etag string) error {
+	if l.CacheDir == "" {
+		return nil
+	}
+
+	err := os.MkdirAll(l.CacheDir, os.FileMode(0755))
+	if err != nil {
+		return err
+	}
+
+	cachePath := l.cachePath(path)
+	err = ioutil.WriteFile(cachePath+cachedBodySuffix, body, os.FileMode(0644))
+	if err != nil {
+		return err
+	}
+
+	if etag == "" {
+		err = os.Remove(cachePath + cachedETagSuffix)
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	return ioutil.WriteFile(cachePath+cachedETagSuffix, []byte(etag), os.FileMode(0644))
+}
+
+// cachePath returns a path of cache files of the URL, without an extension
+func (l HTTPLoader) cachePath(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(l.CacheDir, hex.EncodeToString(sum[:]))
+}
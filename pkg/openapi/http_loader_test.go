@@ -0,0 +1,233 @@
+package openapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+const (
+	rootSpec = `openapi: 3.0.0
+info:
+  title: root
+  version: "1"
+paths: {}
+components:
+  schemas:
+    Order:
+      $ref: "schemas/common.yaml#/components/schemas/Money"
+`
+	commonSpec = `components:
+  schemas:
+    Money:
+      type: object
+      properties:
+        currency:
+          $ref: "currency.yaml#/components/schemas/Currency"
+`
+	currencySpec = `components:
+  schemas:
+    Currency:
+      type: string
+`
+)
+
+func newSpecServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(rootSpec))
+	})
+	mux.HandleFunc("/schemas/common.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(commonSpec))
+	})
+	mux.HandleFunc("/schemas/currency.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(currencySpec))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// hostURL returns the URL of the server with "localhost" instead of the IP, so it is seen as another host by the allow-list
+func hostURL(t *testing.T, server *httptest.Server, host string) string {
+	t.Helper()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return "http://" + host + ":" + serverURL.Port()
+}
+
+func TestHTTPLoaderAllowedHosts(t *testing.T) {
+	server := newSpecServer(t)
+	loader := HTTPLoader{AllowedHosts: []string{"127.0.0.1"}}
+
+	_, err := loader.Load(server.URL + "/openapi.yaml")
+	if err != nil {
+		t.Fatalf("allowed host: unexpected error: %v", err)
+	}
+
+	_, err = loader.Load(hostURL(t, server, "localhost") + "/openapi.yaml")
+	if !errors.Is(err, ErrHostNotAllowed) {
+		t.Fatalf("not allowed host: expected %v, got %v", ErrHostNotAllowed, err)
+	}
+}
+
+func TestHTTPLoaderRedirectToNotAllowedHost(t *testing.T) {
+	target := newSpecServer(t)
+
+	var fetched int32
+	redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetched, 1)
+		http.Redirect(w, r, hostURL(t, target, "localhost")+r.URL.Path, http.StatusFound)
+	}))
+	t.Cleanup(redirecting.Close)
+
+	for name, loader := range map[string]HTTPLoader{
+		"default client": {AllowedHosts: []string{"127.0.0.1"}},
+		"custom client":  {AllowedHosts: []string{"127.0.0.1"}, Client: &http.Client{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := loader.Load(redirecting.URL + "/openapi.yaml")
+			if !errors.Is(err, ErrHostNotAllowed) {
+				t.Fatalf("expected %v, got %v", ErrHostNotAllowed, err)
+			}
+		})
+	}
+
+	if atomic.LoadInt32(&fetched) != 2 {
+		t.Fatalf("expected the redirecting server to be requested twice, got %d", fetched)
+	}
+
+	loader := HTTPLoader{AllowedHosts: []string{"127.0.0.1", "localhost"}}
+	data, err := loader.Load(redirecting.URL + "/openapi.yaml")
+	if err != nil {
+		t.Fatalf("redirect to allowed host: unexpected error: %v", err)
+	}
+
+	if string(data) != rootSpec {
+		t.Fatalf("redirect to allowed host: unexpected body %q", data)
+	}
+}
+
+func TestHTTPLoaderRevalidatesWithETag(t *testing.T) {
+	const etag = `"v1"`
+
+	var requests, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get(ifNoneMatchHeader) == etag {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set(etagHeader, etag)
+		w.Write([]byte(currencySpec))
+	}))
+	t.Cleanup(server.Close)
+
+	loader := HTTPLoader{CacheDir: t.TempDir()}
+	for i := 0; i < 2; i++ {
+		data, err := loader.Load(server.URL + "/currency.yaml")
+		if err != nil {
+			t.Fatalf("fetch %d: unexpected error: %v", i+1, err)
+		}
+
+		if string(data) != currencySpec {
+			t.Fatalf("fetch %d: unexpected body %q", i+1, data)
+		}
+	}
+
+	if requests != 2 || notModified != 1 {
+		t.Fatalf("expected 2 requests with 1 revalidated, got %d requests with %d revalidated", requests, notModified)
+	}
+}
+
+func TestHTTPLoaderOffline(t *testing.T) {
+	server := newSpecServer(t)
+	cacheDir := t.TempDir()
+	documentURL := server.URL + "/schemas/currency.yaml"
+
+	_, err := HTTPLoader{CacheDir: cacheDir, Offline: true}.Load(documentURL)
+	if !errors.Is(err, ErrNotCached) {
+		t.Fatalf("not cached: expected %v, got %v", ErrNotCached, err)
+	}
+
+	_, err = HTTPLoader{CacheDir: cacheDir}.Load(documentURL)
+	if err != nil {
+		t.Fatalf("online: unexpected error: %v", err)
+	}
+
+	server.Close()
+
+	data, err := HTTPLoader{CacheDir: cacheDir, Offline: true}.Load(documentURL)
+	if err != nil {
+		t.Fatalf("cached: unexpected error: %v", err)
+	}
+
+	if string(data) != currencySpec {
+		t.Fatalf("cached: unexpected body %q", data)
+	}
+}
+
+func TestHTTPLoaderMaxBytes(t *testing.T) {
+	server := newSpecServer(t)
+	documentURL := server.URL + "/openapi.yaml"
+
+	_, err := HTTPLoader{MaxBytes: 16}.Load(documentURL)
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("expected %v, got %v", ErrResponseTooLarge, err)
+	}
+
+	_, err = HTTPLoader{MaxBytes: int64(len(rootSpec))}.Load(documentURL)
+	if err != nil {
+		t.Fatalf("document within limit: unexpected error: %v", err)
+	}
+}
+
+func TestHTTPLoaderResolvesRelativeRefsAgainstURL(t *testing.T) {
+	server := newSpecServer(t)
+
+	var mu sync.Mutex
+	requested := make(map[string]bool)
+	recording := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested[r.URL.Path] = true
+		mu.Unlock()
+
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(recording.Close)
+
+	cfg := Config{
+		Loader: HTTPLoader{AllowedHosts: []string{"127.0.0.1"}},
+	}
+	doc, err := ParseDocument(cfg, recording.URL+"/openapi.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, path := range []string{"/openapi.yaml", "/schemas/common.yaml", "/schemas/currency.yaml"} {
+		if !requested[path] {
+			t.Errorf("expected %s to be fetched, fetched: %v", path, requested)
+		}
+	}
+
+	money := doc.Root.Components.Schemas["Money"]
+	if money == nil || money.Properties["currency"] == nil {
+		var out strings.Builder
+		doc.Write(&out)
+		t.Fatalf("expected Money resolved from the referenced document, got:\n%s", out.String())
+	}
+}
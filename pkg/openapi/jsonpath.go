@@ -0,0 +1,393 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidJSONPath occurs when a JSONPath expression can not be parsed
+	ErrInvalidJSONPath = errors.New("invalid JSONPath expression")
+)
+
+// jsonPathSegment selects children of nodes: by names, by indexes, all of them (wildcard) or the ones matching a filter.
+// A descendant segment applies the selection to the nodes and all of their descendants, like "..name".
+type jsonPathSegment struct {
+	descendant bool
+	wildcard   bool
+	names      []string
+	indexes    []int
+	filter     *jsonPathFilter
+}
+
+// jsonPathFilter matches nodes by a value under a relative path, eg. "?(@.name == 'id')". Without an operator nodes having the value are matched, eg. "?(@.x-internal)"
+type jsonPathFilter struct {
+	path     []string
+	operator string
+	literal  interface{}
+}
+
+// jsonPath is a parsed JSONPath expression. A subset of JSONPath is supported: child names (".name", "['name']"), indexes ("[0]", "[-1]"), unions ("['a','b']"),
+// wildcards (".*", "[*]"), recursive descent ("..name") and filters comparing a relative path with a literal ("[?(@.in == 'query')]", "[?(@.deprecated)]").
+type jsonPath []jsonPathSegment
+
+// rawMatch is a node matched by a JSONPath along with its parent and the key (or index) under which it is stored in the parent. Root has no parent
+type rawMatch struct {
+	parent interface{}
+	key    interface{}
+	value  interface{}
+}
+
+func parseJSONPath(expression string) (jsonPath, error) {
+	var path jsonPath
+
+	rest := strings.TrimSpace(expression)
+	if !strings.HasPrefix(rest, "$") {
+		return path, fmt.Errorf("%w: %s: expression needs to start with $", ErrInvalidJSONPath, expression)
+	}
+	rest = rest[1:]
+
+	for rest != "" {
+		var segment jsonPathSegment
+		var err error
+
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			segment.descendant = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				rest, err = parseJSONPathBracket(rest, &segment)
+			} else {
+				rest = parseJSONPathName(rest, &segment)
+			}
+		case strings.HasPrefix(rest, "."):
+			rest = parseJSONPathName(rest[1:], &segment)
+		case strings.HasPrefix(rest, "["):
+			rest, err = parseJSONPathBracket(rest, &segment)
+		default:
+			err = fmt.Errorf("unexpected %q", rest)
+		}
+
+		if err != nil {
+			return path, fmt.Errorf("%w: %s: %v", ErrInvalidJSONPath, expression, err)
+		}
+
+		if !segment.wildcard && segment.names == nil && segment.indexes == nil && segment.filter == nil {
+			return path, fmt.Errorf("%w: %s: empty segment", ErrInvalidJSONPath, expression)
+		}
+
+		path = append(path, segment)
+	}
+
+	return path, nil
+}
+
+func parseJSONPathName(rest string, segment *jsonPathSegment) string {
+	end := strings.IndexAny(rest, ".[")
+	if end < 0 {
+		end = len(rest)
+	}
+
+	name := rest[:end]
+	if name == "*" {
+		segment.wildcard = true
+	} else if name != "" {
+		segment.names = []string{name}
+	}
+
+	return rest[end:]
+}
+
+// parseJSONPathBracket parses a bracketed selector, eg. "['name']", "[0,1]", "[*]" or "[?(@.in == 'query')]", returning the rest of the expression
+func parseJSONPathBracket(rest string, segment *jsonPathSegment) (string, error) {
+	end := closingBracket(rest)
+	if end < 0 {
+		return rest, fmt.Errorf("missing ] in %q", rest)
+	}
+
+	content := strings.TrimSpace(rest[1:end])
+	rest = rest[end+1:]
+
+	switch {
+	case content == "*":
+		segment.wildcard = true
+	case strings.HasPrefix(content, "?"):
+		filter, err := parseJSONPathFilter(strings.TrimSpace(content[1:]))
+		if err != nil {
+			return rest, err
+		}
+
+		segment.filter = &filter
+	default:
+		for _, item := range splitOutsideQuotes(content, ',') {
+			item = strings.TrimSpace(item)
+			if name, ok := unquote(item); ok {
+				segment.names = append(segment.names, name)
+				continue
+			}
+
+			idx, err := strconv.Atoi(item)
+			if err != nil {
+				return rest, fmt.Errorf("%q is neither a quoted name nor an index", item)
+			}
+
+			segment.indexes = append(segment.indexes, idx)
+		}
+	}
+
+	return rest, nil
+}
+
+func parseJSONPathFilter(expression string) (jsonPathFilter, error) {
+	var filter jsonPathFilter
+
+	if !strings.HasPrefix(expression, "(") || !strings.HasSuffix(expression, ")") {
+		return filter, fmt.Errorf("filter %q needs to be enclosed in parentheses", expression)
+	}
+	expression = strings.TrimSpace(expression[1 : len(expression)-1])
+
+	operand := expression
+	for _, operator := range []string{"==", "!="} {
+		if idx := strings.Index(expression, operator); idx >= 0 {
+			filter.operator = operator
+			operand = strings.TrimSpace(expression[:idx])
+			filter.literal = parseJSONPathLiteral(strings.TrimSpace(expression[idx+len(operator):]))
+			break
+		}
+	}
+
+	if !strings.HasPrefix(operand, "@") {
+		return filter, fmt.Errorf("filter operand %q needs to start with @", operand)
+	}
+
+	relativePath, err := parseJSONPath("$" + operand[1:])
+	if err != nil {
+		return filter, err
+	}
+
+	for _, segment := range relativePath {
+		if segment.descendant || len(segment.names) != 1 {
+			return filter, fmt.Errorf("filter operand %q can only select children by name", operand)
+		}
+
+		filter.path = append(filter.path, segment.names[0])
+	}
+
+	return filter, nil
+}
+
+func parseJSONPathLiteral(literal string) interface{} {
+	if value, ok := unquote(literal); ok {
+		return value
+	}
+
+	switch literal {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+
+	if number, err := strconv.ParseFloat(literal, 64); err == nil {
+		return number
+	}
+
+	return literal
+}
+
+// matches returns nodes of the root matched by the path, in document order
+func (p jsonPath) matches(root interface{}) []rawMatch {
+	current := []rawMatch{{value: root}}
+
+	for _, segment := range p {
+		var next []rawMatch
+		for _, match := range current {
+			candidates := []rawMatch{match}
+			if segment.descendant {
+				candidates = append(candidates, rawDescendants(match.value)...)
+			}
+
+			for _, candidate := range candidates {
+				next = append(next, segment.children(candidate.value)...)
+			}
+		}
+
+		current = next
+	}
+
+	return current
+}
+
+func (s jsonPathSegment) children(node interface{}) []rawMatch {
+	var children []rawMatch
+
+	switch node := node.(type) {
+	case map[interface{}]interface{}:
+		for _, key := range sortedRawKeys(node) {
+			name := fmt.Sprint(key)
+			if s.wildcard || containsAny([]string{name}, s.names) || (s.filter != nil && s.filter.matches(node[key])) {
+				children = append(children, rawMatch{parent: node, key: key, value: node[key]})
+			}
+		}
+	case []interface{}:
+		if s.indexes != nil {
+			for _, idx := range s.indexes {
+				if idx < 0 {
+					idx += len(node)
+				}
+
+				if idx >= 0 && idx < len(node) {
+					children = append(children, rawMatch{parent: node, key: idx, value: node[idx]})
+				}
+			}
+
+			break
+		}
+
+		for idx, child := range node {
+			if s.wildcard || (s.filter != nil && s.filter.matches(child)) {
+				children = append(children, rawMatch{parent: node, key: idx, value: child})
+			}
+		}
+	}
+
+	return children
+}
+
+func (f jsonPathFilter) matches(node interface{}) bool {
+	value, ok := node, true
+	for _, name := range f.path {
+		mapNode, isMap := value.(map[interface{}]interface{})
+		if !isMap {
+			ok = false
+			break
+		}
+
+		value, ok = rawMapGet(mapNode, name)
+		if !ok {
+			break
+		}
+	}
+
+	switch f.operator {
+	case "==":
+		return ok && equalLiteral(value, f.literal)
+	case "!=":
+		return !ok || !equalLiteral(value, f.literal)
+	default:
+		return ok && value != nil && value != false
+	}
+}
+
+func equalLiteral(value interface{}, literal interface{}) bool {
+	if value == nil || literal == nil {
+		return value == literal
+	}
+
+	if number, ok := literal.(float64); ok {
+		valueNumber, err := strconv.ParseFloat(fmt.Sprint(value), 64)
+		return err == nil && valueNumber == number
+	}
+
+	return fmt.Sprint(value) == fmt.Sprint(literal)
+}
+
+// rawDescendants returns all descendants of the node, in document order
+func rawDescendants(node interface{}) []rawMatch {
+	var descendants []rawMatch
+
+	wildcard := jsonPathSegment{wildcard: true}
+	for _, child := range wildcard.children(node) {
+		descendants = append(descendants, child)
+		descendants = append(descendants, rawDescendants(child.value)...)
+	}
+
+	return descendants
+}
+
+func rawMapGet(node map[interface{}]interface{}, name string) (interface{}, bool) {
+	for key, value := range node {
+		if fmt.Sprint(key) == name {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+func sortedRawKeys(node map[interface{}]interface{}) []interface{} {
+	var keys []interface{}
+	for key := range node {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+
+	return keys
+}
+
+// closingBracket returns an index of the bracket closing the one at the beginning of the text, skipping quoted text and nested brackets
+func closingBracket(text string) int {
+	depth := 0
+	var quote rune
+	for idx, r := range text {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '[' || r == '(':
+			depth++
+		case r == ']' || r == ')':
+			depth--
+			if depth == 0 {
+				return idx
+			}
+		}
+	}
+
+	return -1
+}
+
+func splitOutsideQuotes(text string, separator rune) []string {
+	var parts []string
+	var quote rune
+	start := 0
+	for idx, r := range text {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == separator:
+			parts = append(parts, text[start:idx])
+			start = idx + 1
+		}
+	}
+
+	return append(parts, text[start:])
+}
+
+func unquote(text string) (string, bool) {
+	if len(text) < 2 {
+		return text, false
+	}
+
+	first, last := text[0], text[len(text)-1]
+	if (first != '\'' && first != '"') || first != last {
+		return text, false
+	}
+
+	return text[1 : len(text)-1], true
+}
@@ -0,0 +1,76 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestJSONPathMatches(t *testing.T) {
+	var root interface{}
+	err := yaml.Unmarshal([]byte(`paths:
+  /orders:
+    get:
+      parameters:
+      - name: limit
+        in: query
+      - name: tenant
+        in: header
+        x-internal: true
+    post:
+      deprecated: true
+  /customers:
+    get:
+      parameters:
+      - name: id
+        in: path
+`), &root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for expression, expected := range map[string][]string{
+		"$.paths['/orders'].get.parameters[0].name":            {"limit"},
+		"$.paths['/orders'].get.parameters[-1].name":           {"tenant"},
+		"$.paths.*.get.parameters[*].name":                     {"id", "limit", "tenant"},
+		"$..parameters[?(@.in == 'query')].name":               {"limit"},
+		"$..parameters[?(@.in != 'query')].name":               {"id", "tenant"},
+		"$..parameters[?(@.x-internal)].name":                  {"tenant"},
+		"$.paths['/orders','/customers'].get.parameters[0].in": {"path", "query"},
+		"$.paths..[?(@.deprecated == true)].deprecated":        {"true"},
+		"$.paths['/missing']":                                  nil,
+	} {
+		path, err := parseJSONPath(expression)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", expression, err)
+		}
+
+		var values []string
+		for _, match := range path.matches(root) {
+			values = append(values, fmt.Sprint(match.value))
+		}
+
+		if !reflect.DeepEqual(values, expected) {
+			t.Errorf("%s: expected %v, got %v", expression, expected, values)
+		}
+	}
+}
+
+func TestParseJSONPathErrors(t *testing.T) {
+	for _, expression := range []string{
+		"paths",
+		"$.paths[0",
+		"$.paths[abc]",
+		"$.paths[?@.in == 'query']",
+		"$.paths[?(in == 'query')]",
+		"$.paths[?(@..in == 'query')]",
+		"$.paths.",
+	} {
+		if _, err := parseJSONPath(expression); !errors.Is(err, ErrInvalidJSONPath) {
+			t.Errorf("%s: expected ErrInvalidJSONPath, got %v", expression, err)
+		}
+	}
+}
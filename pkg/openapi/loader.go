@@ -0,0 +1,161 @@
+package openapi
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedArchive occurs when an archive is neither a zip nor a (gzipped) tar archive
+	ErrUnsupportedArchive = errors.New("unsupported archive format")
+)
+
+// Loader reads content of files of documents, eg. from the OS filesystem, an fs.FS or an archive.
+// Paths are the ones documents are read from and the ones references are resolved to - joined with the directory of the referencing document.
+type Loader interface {
+	Load(path string) ([]byte, error)
+}
+
+// LoaderContext is implemented by loaders that can stop reading a file when the context is canceled or its deadline is exceeded, eg. HTTPLoader.
+// Other loaders are called only when the context is not done yet.
+type LoaderContext interface {
+	LoadContext(ctx context.Context, path string) ([]byte, error)
+}
+
+// OSLoader reads files from the OS filesystem. It is used when Config has no Loader
+type OSLoader struct{}
+
+// Load reads the file from the OS filesystem
+func (l OSLoader) Load(path string) ([]byte, error) {
+	return ioutil.ReadFile(path)
+}
+
+// FSLoader reads files from an fs.FS, eg. embed.FS, a zip.Reader or fstest.MapFS.
+// Paths are converted to slash-separated paths relative to the root of the FS, so "/specs/openapi.yaml" and "specs/openapi.yaml" point to the same file.
+type FSLoader struct {
+	FS fs.FS
+}
+
+// Load reads the file from the FS
+func (l FSLoader) Load(path string) ([]byte, error) {
+	return fs.ReadFile(l.FS, fsPath(path))
+}
+
+// MapLoader reads files from memory, keyed by slash-separated paths relative to the root, eg. "specs/openapi.yaml"
+type MapLoader map[string][]byte
+
+// Load returns content of the file
+func (l MapLoader) Load(path string) ([]byte, error) {
+	data, ok := l[fsPath(path)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+
+	return data, nil
+}
+
+// NewArchiveLoader reads a zip archive or a tar archive, optionally gzipped, into memory.
+// The format is detected by the extension of the archive: .zip, .tar, .tar.gz or .tgz.
+func NewArchiveLoader(archivePath string) (Loader, error) {
+	data, err := ioutil.ReadFile(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return NewZipLoader(bytes.NewReader(data), int64(len(data)))
+	case strings.HasSuffix(name, ".tar"):
+		return NewTarLoader(bytes.NewReader(data))
+	case strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz"):
+		archive, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		return NewTarLoader(archive)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedArchive, archivePath)
+	}
+}
+
+// NewZipLoader constructs a loader reading files from a zip archive
+func NewZipLoader(r io.ReaderAt, size int64) (Loader, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return FSLoader{FS: archive}, nil
+}
+
+// NewTarLoader reads regular files of a tar archive into memory
+func NewTarLoader(r io.Reader) (Loader, error) {
+	loader := make(MapLoader)
+
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return loader, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(archive)
+		if err != nil {
+			return nil, err
+		}
+
+		loader[fsPath(header.Name)] = data
+	}
+}
+
+// loader returns the configured loader, or OSLoader when none is configured
+func (cfg Config) loader() Loader {
+	if cfg.Loader == nil {
+		return OSLoader{}
+	}
+
+	return cfg.Loader
+}
+
+// loadContext reads the file with the loader, passing the context to loaders implementing LoaderContext
+func loadContext(ctx context.Context, loader Loader, path string) ([]byte, error) {
+	if contextLoader, ok := loader.(LoaderContext); ok {
+		return contextLoader.LoadContext(ctx, path)
+	}
+
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return loader.Load(path)
+}
+
+// fsPath converts a path to a slash-separated path relative to the root, as expected by fs.FS
+func fsPath(filePath string) string {
+	cleaned := strings.TrimPrefix(path.Clean(filepath.ToSlash(filePath)), "/")
+	if cleaned == "" {
+		return "."
+	}
+
+	return cleaned
+}
@@ -0,0 +1,227 @@
+package openapi
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+var loaderFiles = map[string]string{
+	"specs/openapi.yaml": `openapi: 3.0.0
+components:
+  schemas:
+    Pet:
+      $ref: 'common/pet.yaml#/components/schemas/Pet'
+`,
+	"specs/common/pet.yaml": `components:
+  schemas:
+    Pet:
+      type: object
+      title: Pet
+`,
+}
+
+func TestLoaders(t *testing.T) {
+	mapFS := make(fstest.MapFS)
+	mapLoader := make(MapLoader)
+	for name, content := range loaderFiles {
+		mapFS[name] = &fstest.MapFile{Data: []byte(content)}
+		mapLoader[name] = []byte(content)
+	}
+
+	tests := []struct {
+		name   string
+		loader Loader
+		root   string
+	}{
+		{
+			name:   "fs.FS",
+			loader: FSLoader{FS: mapFS},
+			root:   "/specs/openapi.yaml",
+		},
+		{
+			name:   "memory",
+			loader: mapLoader,
+			root:   "specs/openapi.yaml",
+		},
+		{
+			name:   "zip archive",
+			loader: archiveLoader(t, "specs.zip", zipArchive(t, loaderFiles)),
+			root:   "specs/openapi.yaml",
+		},
+		{
+			name:   "tar archive",
+			loader: archiveLoader(t, "specs.tar", tarArchive(t, loaderFiles)),
+			root:   "specs/openapi.yaml",
+		},
+		{
+			name:   "gzipped tar archive",
+			loader: archiveLoader(t, "specs.tgz", gzipped(t, tarArchive(t, loaderFiles))),
+			root:   "./specs/openapi.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument(Config{Loader: tt.loader}, tt.root)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pet := doc.Root.Components.Schemas["Pet"]
+			if pet == nil || pet.Title != "Pet" {
+				t.Errorf("expected the referenced schema to be loaded, got %+v", pet)
+			}
+		})
+	}
+}
+
+func TestOSLoader(t *testing.T) {
+	dir := writeFiles(t, loaderFiles)
+	doc, err := ParseDocument(Config{}, filepath.Join(dir, "specs", "openapi.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.Root.Components.Schemas["Pet"].Title != "Pet" {
+		t.Errorf("expected the referenced schema to be loaded from the filesystem")
+	}
+}
+
+func TestMapLoaderMissingFile(t *testing.T) {
+	_, err := MapLoader{}.Load("missing.yaml")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected %v, got %v", fs.ErrNotExist, err)
+	}
+}
+
+func TestTarLoaderSkipsOtherEntries(t *testing.T) {
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	for _, header := range []*tar.Header{
+		{Name: "specs/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "specs/link.yaml", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd", Mode: 0644},
+	} {
+		err := writer.WriteHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loader, err := NewTarLoader(&archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = loader.Load("specs/link.yaml")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected symlinks in the archive to be skipped, got %v", err)
+	}
+}
+
+func TestNewArchiveLoaderUnsupportedFormat(t *testing.T) {
+	path := filepath.Join(writeFiles(t, map[string]string{"specs.rar": "data"}), "specs.rar")
+	_, err := NewArchiveLoader(path)
+	if !errors.Is(err, ErrUnsupportedArchive) {
+		t.Errorf("expected %v, got %v", ErrUnsupportedArchive, err)
+	}
+}
+
+// archiveLoader writes the archive into a temporary directory under the name and constructs a loader reading it
+func archiveLoader(t *testing.T, name string, data []byte) Loader {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	err := ioutil.WriteFile(path, data, os.FileMode(0644))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loader, err := NewArchiveLoader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return loader
+}
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for name, content := range files {
+		file, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = file.Write([]byte(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return archive.Bytes()
+}
+
+func tarArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	for name, content := range files {
+		err := writer.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = writer.Write([]byte(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return archive.Bytes()
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return compressed.Bytes()
+}
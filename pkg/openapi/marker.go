@@ -0,0 +1,313 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	// ExtensionsField is a name of the field holding specification extensions (x- fields) of an object.
+	// Since the field is inlined, it also holds any other field of the object that is not part of its type - $refs inside of such fields are resolved as any other.
+	ExtensionsField = "Extensions"
+	// extensionPrefix starts names of specification extensions
+	extensionPrefix = "x-"
+	// markerValueSeparator separates the extension from its values in the textual form of the Marker
+	markerValueSeparator = "="
+	// markerValuesSeparator separates values in the textual form of the Marker
+	markerValuesSeparator = ","
+)
+
+// Marker identifies objects marked by a specification extension, eg. "x-internal: true" or "x-audience: [partner]"
+type Marker struct {
+	Extension string
+	// Values are compared with the textual form of the extension value. For extensions holding a list, it is enough for one element to match.
+	// Empty Values match the extension set to true.
+	Values []string
+}
+
+// ParseMarker creates a Marker from the textual form "x-extension" or "x-extension=value1,value2"
+func ParseMarker(marker string) Marker {
+	parts := strings.SplitN(marker, markerValueSeparator, 2)
+	if len(parts) == 1 {
+		return Marker{Extension: parts[0]}
+	}
+
+	return Marker{
+		Extension: parts[0],
+		Values:    strings.Split(parts[1], markerValuesSeparator),
+	}
+}
+
+// String returns the textual form of the Marker accepted by ParseMarker
+func (m Marker) String() string {
+	if len(m.Values) == 0 {
+		return m.Extension
+	}
+
+	return fmt.Sprintf("%s%s%s", m.Extension, markerValueSeparator, strings.Join(m.Values, markerValuesSeparator))
+}
+
+// Marks checks whether an OpenAPI object (a pointer to one of the types) is marked
+func (m Marker) Marks(object interface{}) bool {
+	value := reflect.ValueOf(object)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return false
+	}
+
+	extensionsField, ok := fieldByName(value.Elem(), ExtensionsField)
+	if !ok {
+		return false
+	}
+
+	extensions, _ := extensionsField.Interface().(map[string]interface{})
+	extension, ok := extensions[m.Extension]
+	if !ok {
+		return false
+	}
+
+	values := m.Values
+	if len(values) == 0 {
+		values = []string{"true"}
+	}
+
+	if list, ok := extension.([]interface{}); ok {
+		for _, element := range list {
+			if containsAny([]string{fmt.Sprint(element)}, values) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return containsAny([]string{fmt.Sprint(extension)}, values)
+}
+
+// RemoveMarked removes operations, parameters, schema properties, components and any other objects marked by at least one of the markers.
+// Names of removed properties are removed from required lists of their schemas, path items left without operations are removed.
+// Objects referencing removed components are removed as well, until no reference is left dangling.
+// Should be run on a document with resolved references. Returns local references of removed components, in order of removal.
+func (doc Document) RemoveMarked(markers ...Marker) []string {
+	var removedComponents []string
+
+	marked := func(object interface{}) bool {
+		for _, marker := range markers {
+			if marker.Marks(object) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	removedRefs := make(map[string]bool)
+	dangling := func(object interface{}) bool {
+		refPath, ok := objectRef(object)
+		if !ok || !isLocalReference(refPath) {
+			return false
+		}
+
+		items := referencePathToItems(refPath)
+		return len(items) >= 3 && items[0] == ComponentsItem && removedRefs[componentReference(items[1], items[2])]
+	}
+
+	removable := func(object interface{}) bool {
+		return marked(object) || dangling(object)
+	}
+
+	// components are removed first, since a component referencing a removed one leaves dangling refs after its own removal
+	for {
+		removed := doc.removeComponents(removable)
+		if len(removed) == 0 {
+			break
+		}
+
+		for _, ref := range removed {
+			removedRefs[ref] = true
+		}
+		removedComponents = append(removedComponents, removed...)
+	}
+
+	removeObjects(reflect.ValueOf(doc.Root), removable, make(map[uintptr]bool))
+
+	keepAll := func(string, *Operation) bool { return true }
+	filterPathItems(doc.Root.Paths, keepAll)
+	filterPathItems(doc.Root.Webhooks, keepAll)
+
+	return removedComponents
+}
+
+// removeComponents removes components for which remove returns true and returns their local references
+func (doc Document) removeComponents(remove func(object interface{}) bool) []string {
+	var removed []string
+
+	if doc.Root.Components == nil {
+		return removed
+	}
+
+	components := reflect.ValueOf(doc.Root.Components).Elem()
+	componentsType := components.Type()
+	for i := 0; i < components.NumField(); i++ {
+		componentsOfType := components.Field(i)
+		if componentsOfType.Kind() != reflect.Map || componentsType.Field(i).Name == ExtensionsField {
+			continue
+		}
+
+		componentType := getYamlKeyFromField(componentsType.Field(i))
+		for _, key := range componentsOfType.MapKeys() {
+			if !remove(componentsOfType.MapIndex(key).Interface()) {
+				continue
+			}
+
+			removed = append(removed, componentReference(componentType, key.String()))
+			componentsOfType.SetMapIndex(key, reflect.Value{})
+		}
+	}
+
+	return removed
+}
+
+// removeObjects walks the value and removes descendant objects for which remove returns true: map entries are deleted, slice elements are dropped and pointer fields are set to nil.
+// Whenever a property of a schema is removed, its name is removed from the required list of the schema.
+// Visited holds pointers that were already walked, since inlined references can make the same object appear in many places or even form cycles.
+func removeObjects(value reflect.Value, remove func(object interface{}) bool, visited map[uintptr]bool) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() || value.Elem().Kind() != reflect.Struct || visited[value.Pointer()] {
+			return
+		}
+		visited[value.Pointer()] = true
+
+		schema, isSchema := value.Interface().(*Schema)
+		var properties []string
+		if isSchema {
+			for name := range schema.Properties {
+				properties = append(properties, name)
+			}
+		}
+
+		item := value.Elem()
+		for i := 0; i < item.NumField(); i++ {
+			field := item.Field(i)
+			if field.Kind() == reflect.Ptr && !field.IsNil() && remove(field.Interface()) {
+				field.Set(reflect.Zero(field.Type()))
+				continue
+			}
+
+			if item.Type().Field(i).Name == ExtensionsField {
+				removeMapEntries(field, remove, visited, isExtension)
+				continue
+			}
+
+			removeObjects(field, remove, visited)
+		}
+
+		if isSchema {
+			for _, name := range properties {
+				if _, ok := schema.Properties[name]; !ok {
+					schema.Required = removeString(schema.Required, name)
+				}
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			removeObjects(value.Field(i), remove, visited)
+		}
+	case reflect.Interface:
+		removeObjects(value.Elem(), remove, visited)
+	case reflect.Map:
+		removeMapEntries(value, remove, visited, nil)
+	case reflect.Slice:
+		if elemKind := value.Type().Elem().Kind(); elemKind != reflect.Ptr && elemKind != reflect.Interface {
+			for i := 0; i < value.Len(); i++ {
+				removeObjects(value.Index(i), remove, visited)
+			}
+
+			return
+		}
+
+		kept := reflect.MakeSlice(value.Type(), 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			child := value.Index(i)
+			if removable(child, remove) {
+				continue
+			}
+
+			removeObjects(child, remove, visited)
+			kept = reflect.Append(kept, child)
+		}
+
+		if kept.Len() != value.Len() && value.CanSet() {
+			value.Set(kept)
+		}
+	}
+}
+
+// removeMapEntries removes entries of the map holding objects for which remove returns true and walks the remaining ones. Entries with keys for which skip returns true are left untouched
+func removeMapEntries(value reflect.Value, remove func(object interface{}) bool, visited map[uintptr]bool, skip func(key string) bool) {
+	if value.Kind() != reflect.Map {
+		return
+	}
+
+	for _, key := range value.MapKeys() {
+		if skip != nil && skip(mapKeyString(key)) {
+			continue
+		}
+
+		child := value.MapIndex(key)
+		if removable(child, remove) {
+			value.SetMapIndex(key, reflect.Value{})
+			continue
+		}
+
+		removeObjects(child, remove, visited)
+	}
+}
+
+// removable checks whether the value holds an object, either typed or generic, for which remove returns true
+func removable(value reflect.Value, remove func(object interface{}) bool) bool {
+	value = rawValue(value)
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Map:
+		return !value.IsNil() && remove(value.Interface())
+	default:
+		return false
+	}
+}
+
+// objectRef returns the $ref of an OpenAPI object, if it has one. Generic maps holding $ref, eg. in fields not handled by the types, are objects as well
+func objectRef(object interface{}) (string, bool) {
+	value := reflect.ValueOf(object)
+	if value.Kind() == reflect.Map {
+		_, refValue, err := itemFromMapByName(value, RefTag)
+		if err != nil {
+			return "", false
+		}
+
+		ref, ok := rawValue(refValue).Interface().(string)
+		return ref, ok && ref != ""
+	}
+
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return "", false
+	}
+
+	refField, ok := fieldByName(value.Elem(), Ref)
+	if !ok || refField.Kind() != reflect.String || refField.String() == "" {
+		return "", false
+	}
+
+	return refField.String(), true
+}
+
+func removeString(values []string, removed string) []string {
+	var kept []string
+	for _, value := range values {
+		if value != removed {
+			kept = append(kept, value)
+		}
+	}
+
+	return kept
+}
@@ -0,0 +1,294 @@
+package openapi
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const markedSpec = `openapi: 3.0.0
+info:
+  title: shop
+  version: "1"
+  x-logo: logo.png
+paths:
+  /orders:
+    get:
+      parameters:
+      - name: limit
+        in: query
+      - name: debug
+        in: query
+        x-internal: true
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Order'
+    delete:
+      x-internal: true
+      responses:
+        "204":
+          description: deleted
+  /audit:
+    get:
+      x-audience: [internal, ops]
+      responses:
+        "200":
+          description: ok
+  /partners:
+    get:
+      x-audience: partner
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Partner'
+components:
+  schemas:
+    Order:
+      type: object
+      required: [id, cost, notes]
+      properties:
+        id:
+          type: string
+        cost:
+          type: number
+          x-internal: true
+        notes:
+          type: string
+        secret:
+          $ref: '#/components/schemas/Secret'
+        lines:
+          type: object
+          additionalProperties:
+            $ref: '#/components/schemas/Secret'
+    Secret:
+      type: string
+      x-internal: true
+    Partner:
+      type: object
+`
+
+func TestParseMarker(t *testing.T) {
+	for text, expected := range map[string]Marker{
+		"x-internal":                 {Extension: "x-internal"},
+		"x-audience=partner":         {Extension: "x-audience", Values: []string{"partner"}},
+		"x-audience=partner,private": {Extension: "x-audience", Values: []string{"partner", "private"}},
+	} {
+		marker := ParseMarker(text)
+		if !reflect.DeepEqual(marker, expected) {
+			t.Errorf("%s: expected %+v, got %+v", text, expected, marker)
+		}
+
+		if marker.String() != text {
+			t.Errorf("%s: unexpected textual form %s", text, marker.String())
+		}
+	}
+}
+
+func TestMarkerMarks(t *testing.T) {
+	for _, tc := range []struct {
+		marker     string
+		extensions map[string]interface{}
+		expected   bool
+	}{
+		{"x-internal", map[string]interface{}{"x-internal": true}, true},
+		{"x-internal", map[string]interface{}{"x-internal": false}, false},
+		{"x-internal", nil, false},
+		{"x-audience=partner", map[string]interface{}{"x-audience": "partner"}, true},
+		{"x-audience=partner", map[string]interface{}{"x-audience": []interface{}{"internal", "partner"}}, true},
+		{"x-audience=partner", map[string]interface{}{"x-audience": []interface{}{"internal"}}, false},
+	} {
+		marks := ParseMarker(tc.marker).Marks(&Operation{Extensions: tc.extensions})
+		if marks != tc.expected {
+			t.Errorf("%s on %v: expected %t, got %t", tc.marker, tc.extensions, tc.expected, marks)
+		}
+	}
+}
+
+func TestRemoveMarked(t *testing.T) {
+	doc := parseYAML(t, markedSpec)
+	removed := doc.RemoveMarked(ParseMarker("x-internal"), ParseMarker("x-audience=internal"))
+	if !reflect.DeepEqual(removed, []string{"#/components/schemas/Secret"}) {
+		t.Errorf("expected removed components [#/components/schemas/Secret], got %v", removed)
+	}
+
+	if operations := operationLabels(doc); !reflect.DeepEqual(operations, []string{"GET /orders", "GET /partners"}) {
+		t.Errorf("expected marked operations and empty path items to be removed, got %v", operations)
+	}
+
+	parameters := doc.Root.Paths["/orders"].Get.Parameters
+	if len(parameters) != 1 || parameters[0].Name != "limit" {
+		t.Errorf("expected the marked parameter to be removed, got %+v", parameters)
+	}
+
+	order := doc.Root.Components.Schemas["Order"]
+	if properties := sortedKeys(order.Properties); !reflect.DeepEqual(properties, []string{"id", "lines", "notes"}) {
+		t.Errorf("expected marked properties and properties referencing removed components to be removed, got %v", properties)
+	}
+	if !reflect.DeepEqual(order.Required, []string{"id", "notes"}) {
+		t.Errorf("expected removed properties to be removed from required, got %v", order.Required)
+	}
+	if _, ok := order.Properties["lines"].Extensions["additionalProperties"]; ok {
+		t.Errorf("expected a field not handled by the types, referencing a removed component, to be removed")
+	}
+
+	if doc.Root.Info.Extensions["x-logo"] != "logo.png" {
+		t.Errorf("expected unmarked extensions to be kept, got %v", doc.Root.Info.Extensions)
+	}
+}
+
+func TestExtensionsArePreserved(t *testing.T) {
+	const spec = `openapi: 3.0.0
+info:
+  title: shop
+  version: "1"
+  x-logo:
+    url: logo.png
+paths:
+  /orders:
+    x-controller: orders
+    get:
+      x-rate-limit: 10
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    Order:
+      type: object
+      additionalProperties: false
+  x-gateway: keepme
+x-tenant: shop
+`
+
+	// extensions are encoded after the fields of the types
+	assertYAML(t, "extensions", documentYAML(t, parseYAML(t, spec)), `openapi: 3.0.0
+info:
+  title: shop
+  version: "1"
+  x-logo:
+    url: logo.png
+paths:
+  /orders:
+    get:
+      responses:
+        "200":
+          description: ok
+      x-rate-limit: 10
+    x-controller: orders
+components:
+  schemas:
+    Order:
+      type: object
+      additionalProperties: false
+  x-gateway: keepme
+x-tenant: shop
+`)
+}
+
+func TestReferencesInFieldsNotHandledByTypes(t *testing.T) {
+	files := map[string]string{
+		"openapi.yaml": `openapi: 3.0.0
+info:
+  title: shop
+  version: "1"
+paths:
+  /orders:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                additionalProperties:
+                  $ref: common.yaml#/components/schemas/Line
+                x-docs:
+                  $ref: docs.yaml#/missing
+components:
+  schemas:
+    Unused:
+      type: string
+  x-gateway: keepme
+`,
+		"common.yaml": `components:
+  schemas:
+    Line:
+      type: object
+      additionalProperties:
+        $ref: '#/components/schemas/Money'
+    Money:
+      type: number
+`,
+	}
+
+	doc := parseFiles(t, Config{PruneComponents: true}, files, "openapi.yaml")
+	assertYAML(t, "remote refs", documentYAML(t, doc), `openapi: 3.0.0
+info:
+  title: shop
+  version: "1"
+paths:
+  /orders:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                type: object
+                additionalProperties:
+                  $ref: '#/components/schemas/Line'
+                x-docs:
+                  $ref: docs.yaml#/missing
+          description: ok
+components:
+  schemas:
+    Line:
+      type: object
+      additionalProperties:
+        type: number
+  x-gateway: keepme
+`)
+
+	doc = parseFiles(t, Config{InlineRemoteRefs: true}, files, "openapi.yaml")
+	schema := doc.Root.Paths["/orders"].Get.Responses["200"].Content["application/json"].Schema
+	line, ok := schema.Extensions["additionalProperties"].(*Schema)
+	if !ok || line.Type != "object" {
+		t.Errorf("expected the remote ref to be inlined, got %#v", schema.Extensions["additionalProperties"])
+	}
+}
+
+func TestPruneKeepsComponentsExtensions(t *testing.T) {
+	doc := parseYAML(t, `openapi: 3.0.0
+info:
+  title: shop
+  version: "1"
+paths: {}
+components:
+  schemas:
+    Unused:
+      type: string
+  x-gateway: keepme
+`)
+
+	pruned, err := doc.PruneComponents(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sort.Strings(pruned)
+	if !reflect.DeepEqual(pruned, []string{"#/components/schemas/Unused"}) {
+		t.Errorf("expected only the unused schema to be pruned, got %v", pruned)
+	}
+
+	if doc.Root.Components.Extensions["x-gateway"] != "keepme" {
+		t.Errorf("expected extensions of components to be kept, got %v", doc.Root.Components.Extensions)
+	}
+}
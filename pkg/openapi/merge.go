@@ -0,0 +1,471 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// PathConflictPolicy decides what happens when merged documents define the same operation
+type PathConflictPolicy string
+
+const (
+	// FailOnConflict makes merging fail when the same operation is defined by more than one document
+	FailOnConflict PathConflictPolicy = "error"
+	// OverrideOnConflict keeps the operation of the document merged last
+	OverrideOnConflict PathConflictPolicy = "override"
+)
+
+// MergeConflictKind is a kind of object that more than one merged document defines differently
+type MergeConflictKind string
+
+const (
+	// OperationConflict occurs when documents define the same method of the same path
+	OperationConflict MergeConflictKind = "operation"
+	// OperationIDConflict occurs when documents define different operations with the same operationId
+	OperationIDConflict MergeConflictKind = "operationId"
+	// ComponentConflict occurs when documents define different components of the same type and name
+	ComponentConflict MergeConflictKind = "component"
+	// TagConflict occurs when documents define tags with the same name and different descriptions
+	TagConflict MergeConflictKind = "tag"
+)
+
+var (
+	// ErrPathConflict occurs when merged documents define the same operation and the policy is FailOnConflict
+	ErrPathConflict = errors.New("operation is defined by more than one document")
+)
+
+// MergeSource is a root document merged with other documents
+type MergeSource struct {
+	Document Document
+	// Name identifies the document in the conflict report and prefixes names of its components that collide with components of other documents.
+	// When empty, info.title of the document or its file name is used
+	Name string
+	// PathPrefix is prepended to paths of the document, eg. "/billing"
+	PathPrefix string
+}
+
+// MergeConfig specifies how documents are merged
+type MergeConfig struct {
+	OnPathConflict PathConflictPolicy
+}
+
+// MergeConflict describes an object defined differently by more than one merged document and how the conflict has been resolved
+type MergeConflict struct {
+	Kind MergeConflictKind `json:"kind"`
+	// Name identifies the object, eg. "GET /orders" or "#/components/schemas/Money"
+	Name       string   `json:"name"`
+	Sources    []string `json:"sources"`
+	Resolution string   `json:"resolution"`
+}
+
+// String returns a human readable description of the conflict
+func (c MergeConflict) String() string {
+	return fmt.Sprintf("%s %s defined by %s: %s", c.Kind, c.Name, strings.Join(c.Sources, ", "), c.Resolution)
+}
+
+// documentMerger holds the state of merging
+type documentMerger struct {
+	cfg       MergeConfig
+	merged    Document
+	conflicts []MergeConflict
+	// operationSources holds names of sources of merged operations
+	operationSources map[string]string
+	// operationIDSources holds names of sources of merged operationIds
+	operationIDSources map[string]string
+}
+
+// Merge merges root documents into a single document. Documents should have their references resolved, with remote references converted to local ones.
+// Paths are unioned - operations defined by more than one document fail the merge or are overridden by the document merged last, depending on the config.
+// Components are unioned - equal components are kept once, while colliding ones are renamed by prefixing them with the source name, along with their references.
+// Tags, servers and security requirements are unioned. Info and the OpenAPI version are taken from the first document.
+func Merge(cfg MergeConfig, sources ...MergeSource) (Document, []MergeConflict, error) {
+	merger := documentMerger{
+		cfg:                cfg,
+		merged:             NewDocument(Config{}),
+		operationSources:   make(map[string]string),
+		operationIDSources: make(map[string]string),
+	}
+
+	for idx, source := range sources {
+		if source.Name == "" {
+			source.Name = sourceName(source.Document)
+		}
+
+		if idx == 0 {
+			merger.merged.Root.Version = source.Document.Root.Version
+			merger.merged.Root.Info = source.Document.Root.Info
+			merger.merged.RefDirectory = source.Document.RefDirectory
+			merger.merged.FileName = source.Document.FileName
+		}
+
+		err := merger.merge(source)
+		if err != nil {
+			return merger.merged, merger.conflicts, err
+		}
+	}
+
+	sort.SliceStable(merger.conflicts, func(i, j int) bool {
+		if merger.conflicts[i].Kind != merger.conflicts[j].Kind {
+			return merger.conflicts[i].Kind < merger.conflicts[j].Kind
+		}
+
+		return merger.conflicts[i].Name < merger.conflicts[j].Name
+	})
+
+	if cfg.OnPathConflict != OverrideOnConflict {
+		var conflicting []string
+		for _, conflict := range merger.conflicts {
+			if conflict.Kind == OperationConflict {
+				conflicting = append(conflicting, conflict.Name)
+			}
+		}
+
+		if len(conflicting) > 0 {
+			return merger.merged, merger.conflicts, fmt.Errorf("%w: %s", ErrPathConflict, strings.Join(conflicting, ", "))
+		}
+	}
+
+	return merger.merged, merger.conflicts, nil
+}
+
+func (m *documentMerger) merge(source MergeSource) error {
+	root := source.Document.Root
+
+	err := m.mergeComponents(source)
+	if err != nil {
+		return err
+	}
+
+	if m.merged.Root.Paths == nil && len(root.Paths) > 0 {
+		m.merged.Root.Paths = make(map[string]*PathItem)
+	}
+	for path, pathItem := range root.Paths {
+		m.mergePathItem(m.merged.Root.Paths, prefixedPath(source.PathPrefix, path), pathItem, source.Name)
+	}
+
+	if m.merged.Root.Webhooks == nil && len(root.Webhooks) > 0 {
+		m.merged.Root.Webhooks = make(map[string]*PathItem)
+	}
+	for name, pathItem := range root.Webhooks {
+		m.mergePathItem(m.merged.Root.Webhooks, name, pathItem, source.Name)
+	}
+
+	m.mergeTags(root.Tags, source.Name)
+
+	for _, server := range root.Servers {
+		if !containsObject(m.merged.Root.Servers, server) {
+			m.merged.Root.Servers = append(m.merged.Root.Servers, server)
+		}
+	}
+
+	for _, requirement := range root.Security {
+		if !containsObject(m.merged.Root.Security, requirement) {
+			m.merged.Root.Security = append(m.merged.Root.Security, requirement)
+		}
+	}
+
+	if root.ExternalDocs != nil && m.merged.Root.ExternalDocs == nil {
+		m.merged.Root.ExternalDocs = root.ExternalDocs
+	}
+
+	m.merged.Root.Extensions = unionExtensions(m.merged.Root.Extensions, root.Extensions)
+	return nil
+}
+
+// mergeComponents moves components of the source to the merged document, renaming the ones colliding with different components of other sources.
+// References of the source are changed after every round of renaming and components are compared again, since a component referencing a renamed one
+// no longer equals the component of the same name in the merged document.
+func (m *documentMerger) mergeComponents(source MergeSource) error {
+	renamedNames := make(map[string]string)
+	renamedSchemes := make(map[string]string)
+	for {
+		renamed := make(map[string]string)
+		for _, componentType := range unionKeys(componentTypeNames()) {
+			components := source.Document.components(componentType)
+			for _, name := range unionKeys(components) {
+				ref := componentReference(componentType, name)
+				if _, ok := renamedNames[ref]; ok {
+					continue
+				}
+
+				existing, ok := m.merged.localObject(ref)
+				if !ok || equalObjects(existing, components[name]) {
+					continue
+				}
+
+				newName := m.merged.uniqueComponentName(componentType, source.Name+" "+name)
+				newRef := componentReference(componentType, newName)
+				renamed[ref] = newRef
+				renamedNames[ref] = newName
+				if componentType == securitySchemesItem {
+					renamedSchemes[name] = newName
+				}
+
+				m.conflicts = append(m.conflicts, MergeConflict{
+					Kind:       ComponentConflict,
+					Name:       ref,
+					Sources:    []string{source.Name},
+					Resolution: fmt.Sprintf("renamed to %s", newRef),
+				})
+			}
+		}
+
+		if len(renamed) == 0 {
+			break
+		}
+
+		err := source.Document.changeReferences(renamed)
+		if err != nil {
+			return fmt.Errorf("could not rename components of %s: %w", source.Name, err)
+		}
+	}
+	source.Document.renameSecurityRequirements(renamedSchemes)
+
+	if m.merged.Root.Components == nil {
+		m.merged.Root.Components = &Components{}
+	}
+
+	for componentType := range componentTypeNames() {
+		for name, component := range source.Document.components(componentType) {
+			if newName, ok := renamedNames[componentReference(componentType, name)]; ok {
+				name = newName
+			}
+
+			if m.merged.componentExists(componentType, name) {
+				continue
+			}
+
+			forceCreate := true
+			object, err := m.merged.getOrCreateObjectByPath(componentReference(componentType, name), forceCreate)
+			if err != nil {
+				return err
+			}
+
+			err = object.Set(component)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	m.merged.Root.Components.Extensions = unionExtensions(m.merged.Root.Components.Extensions, componentsExtensions(source.Document))
+	if reflect.DeepEqual(*m.merged.Root.Components, Components{}) {
+		m.merged.Root.Components = nil
+	}
+
+	return nil
+}
+
+// mergePathItem merges operations of the path item into the path item of the same path, reporting operations defined by both
+func (m *documentMerger) mergePathItem(pathItems map[string]*PathItem, path string, pathItem *PathItem, sourceName string) {
+	if pathItem == nil {
+		return
+	}
+
+	for _, operation := range pathItem.Operations() {
+		m.checkOperationID(operation, sourceName)
+	}
+
+	existing, ok := pathItems[path]
+	if !ok {
+		pathItems[path] = pathItem
+		for method := range pathItem.Operations() {
+			m.operationSources[operationKey(method, path)] = sourceName
+		}
+
+		return
+	}
+
+	for _, method := range HTTPMethods {
+		operation := pathItem.Operation(method)
+		if operation == nil {
+			continue
+		}
+
+		key := operationKey(method, path)
+		if existing.Operation(method) != nil {
+			resolution := "kept operation of " + m.operationSources[key]
+			if m.cfg.OnPathConflict == OverrideOnConflict {
+				resolution = "overridden by operation of " + sourceName
+			}
+
+			m.conflicts = append(m.conflicts, MergeConflict{
+				Kind:       OperationConflict,
+				Name:       key,
+				Sources:    []string{m.operationSources[key], sourceName},
+				Resolution: resolution,
+			})
+
+			if m.cfg.OnPathConflict != OverrideOnConflict {
+				continue
+			}
+		}
+
+		existing.SetOperation(method, operation)
+		m.operationSources[key] = sourceName
+	}
+
+	for _, parameter := range pathItem.Parameters {
+		if !containsObject(existing.Parameters, parameter) {
+			existing.Parameters = append(existing.Parameters, parameter)
+		}
+	}
+
+	for _, server := range pathItem.Servers {
+		if !containsObject(existing.Servers, server) {
+			existing.Servers = append(existing.Servers, server)
+		}
+	}
+
+	existing.Summary = firstString(existing.Summary, pathItem.Summary)
+	existing.Description = firstString(existing.Description, pathItem.Description)
+	existing.Extensions = unionExtensions(existing.Extensions, pathItem.Extensions)
+}
+
+// checkOperationID reports operations of different sources sharing an operationId, which have to be unique in a document
+func (m *documentMerger) checkOperationID(operation *Operation, sourceName string) {
+	if operation.OperationID == "" {
+		return
+	}
+
+	if otherSource, ok := m.operationIDSources[operation.OperationID]; ok && otherSource != sourceName {
+		m.conflicts = append(m.conflicts, MergeConflict{
+			Kind:       OperationIDConflict,
+			Name:       operation.OperationID,
+			Sources:    []string{otherSource, sourceName},
+			Resolution: "kept as is",
+		})
+
+		return
+	}
+
+	m.operationIDSources[operation.OperationID] = sourceName
+}
+
+// mergeTags unions tags by name. Descriptions of tags merged first are kept
+func (m *documentMerger) mergeTags(tags []*Tag, sourceName string) {
+	for _, tag := range tags {
+		if tag == nil {
+			continue
+		}
+
+		var existing *Tag
+		for _, mergedTag := range m.merged.Root.Tags {
+			if mergedTag.Name == tag.Name {
+				existing = mergedTag
+			}
+		}
+
+		if existing == nil {
+			m.merged.Root.Tags = append(m.merged.Root.Tags, tag)
+			continue
+		}
+
+		if tag.Description != "" && existing.Description != "" && tag.Description != existing.Description {
+			m.conflicts = append(m.conflicts, MergeConflict{
+				Kind:       TagConflict,
+				Name:       tag.Name,
+				Sources:    []string{sourceName},
+				Resolution: "kept the first description",
+			})
+		}
+
+		existing.Description = firstString(existing.Description, tag.Description)
+		if existing.ExternalDocs == nil {
+			existing.ExternalDocs = tag.ExternalDocs
+		}
+	}
+}
+
+// renameSecurityRequirements renames security schemes in document-wide and operation security requirements
+func (doc Document) renameSecurityRequirements(renamed map[string]string) {
+	if len(renamed) == 0 {
+		return
+	}
+
+	rename := func(requirement SecurityRequirement) {
+		for from, to := range renamed {
+			if scopes, ok := requirement[from]; ok {
+				delete(requirement, from)
+				requirement[to] = scopes
+			}
+		}
+	}
+
+	for _, requirement := range doc.Root.Security {
+		rename(requirement)
+	}
+
+	for _, pathItems := range []map[string]*PathItem{doc.Root.Paths, doc.Root.Webhooks} {
+		for _, pathItem := range pathItems {
+			if pathItem == nil {
+				continue
+			}
+
+			for _, operation := range pathItem.Operations() {
+				if operation.Security != nil {
+					rename(*operation.Security)
+				}
+			}
+		}
+	}
+}
+
+// componentTypeNames returns keys of all component types
+func componentTypeNames() map[string]bool {
+	names := make(map[string]bool)
+
+	componentsType := reflect.TypeOf(Components{})
+	for i := 0; i < componentsType.NumField(); i++ {
+		if field := componentsType.Field(i); field.Type.Kind() == reflect.Map && field.Name != ExtensionsField {
+			names[getYamlKeyFromField(field)] = true
+		}
+	}
+
+	return names
+}
+
+func componentsExtensions(doc Document) map[string]interface{} {
+	if doc.Root.Components == nil {
+		return nil
+	}
+
+	return doc.Root.Components.Extensions
+}
+
+// sourceName returns info.title of the document, or its file name without extension when the document has no title
+func sourceName(doc Document) string {
+	if doc.Root.Info != nil && doc.Root.Info.Title != "" {
+		return doc.Root.Info.Title
+	}
+
+	return strings.TrimSuffix(doc.FileName, filepath.Ext(doc.FileName))
+}
+
+func prefixedPath(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+
+	return "/" + strings.Trim(prefix, "/") + path
+}
+
+func operationKey(method, path string) string {
+	return fmt.Sprintf("%s %s", strings.ToUpper(method), path)
+}
+
+// containsObject checks whether the list, eg. of servers, holds an object equal to the provided one
+func containsObject(list interface{}, object interface{}) bool {
+	value := reflect.ValueOf(list)
+	for i := 0; i < value.Len(); i++ {
+		if equalObjects(value.Index(i).Interface(), object) {
+			return true
+		}
+	}
+
+	return false
+}
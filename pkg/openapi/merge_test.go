@@ -0,0 +1,164 @@
+package openapi
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const serviceASpec = `openapi: 3.0.0
+info:
+  title: svc a
+  version: "1"
+tags:
+- name: orders
+  description: orders of svc a
+servers:
+- url: https://a.example.com
+paths:
+  /orders:
+    get:
+      operationId: listOrders
+      tags: [orders]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Order'
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        item:
+          $ref: '#/components/schemas/Item'
+    Item:
+      type: object
+      properties:
+        sku:
+          type: string
+    Money:
+      type: number
+`
+
+const serviceBSpec = `openapi: 3.0.0
+info:
+  title: svc b
+  version: "1"
+tags:
+- name: orders
+  description: orders of svc b
+servers:
+- url: https://a.example.com
+- url: https://b.example.com
+paths:
+  /orders:
+    post:
+      operationId: createOrder
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Order'
+      responses:
+        "201":
+          description: created
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        item:
+          $ref: '#/components/schemas/Item'
+    Item:
+      type: object
+      properties:
+        id:
+          type: integer
+    Money:
+      type: number
+`
+
+func TestMerge(t *testing.T) {
+	merged, conflicts, err := Merge(MergeConfig{}, MergeSource{Document: parseYAML(t, serviceASpec)}, MergeSource{Document: parseYAML(t, serviceBSpec)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, conflict := range conflicts {
+		got = append(got, conflict.String())
+	}
+
+	expected := []string{
+		"component #/components/schemas/Item defined by svc b: renamed to #/components/schemas/SvcBItem",
+		"component #/components/schemas/Order defined by svc b: renamed to #/components/schemas/SvcBOrder",
+		"tag orders defined by svc b: kept the first description",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected conflicts:\n%v\ngot:\n%v", expected, got)
+	}
+
+	if schemas := sortedKeys(merged.Root.Components.Schemas); !reflect.DeepEqual(schemas, []string{"Item", "Money", "Order", "SvcBItem", "SvcBOrder"}) {
+		t.Errorf("expected equal components to be kept once and colliding ones to be renamed, got %v", schemas)
+	}
+
+	if ref := merged.Root.Components.Schemas["SvcBOrder"].Properties["item"].Ref; ref != "#/components/schemas/SvcBItem" {
+		t.Errorf("expected references of the renamed component to point to renamed components, got %s", ref)
+	}
+
+	if ref := merged.Root.Components.Schemas["Order"].Properties["item"].Ref; ref != "#/components/schemas/Item" {
+		t.Errorf("expected components of the first document to be left as they are, got %s", ref)
+	}
+
+	if ref := merged.Root.Paths["/orders"].Post.RequestBody.Content["application/json"].Schema.Ref; ref != "#/components/schemas/SvcBOrder" {
+		t.Errorf("expected references to renamed components to be changed, got %s", ref)
+	}
+
+	if operations := operationLabels(merged); !reflect.DeepEqual(operations, []string{"GET /orders", "POST /orders"}) {
+		t.Errorf("expected operations of both documents, got %v", operations)
+	}
+
+	if len(merged.Root.Servers) != 2 || len(merged.Root.Tags) != 1 || merged.Root.Info.Title != "svc a" {
+		t.Errorf("expected servers and tags to be unioned and info of the first document, got %+v %+v %+v", merged.Root.Servers, merged.Root.Tags, merged.Root.Info)
+	}
+}
+
+func TestMergePathPrefix(t *testing.T) {
+	merged, _, err := Merge(MergeConfig{}, MergeSource{Document: parseYAML(t, serviceASpec)}, MergeSource{Document: parseYAML(t, serviceASpec), Name: "copy", PathPrefix: "/copy/"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if operations := operationLabels(merged); !reflect.DeepEqual(operations, []string{"GET /copy/orders", "GET /orders"}) {
+		t.Errorf("expected paths of the second document to be prefixed, got %v", operations)
+	}
+}
+
+func TestMergePathConflicts(t *testing.T) {
+	_, conflicts, err := Merge(MergeConfig{OnPathConflict: FailOnConflict}, MergeSource{Document: parseYAML(t, serviceASpec)}, MergeSource{Document: parseYAML(t, serviceASpec), Name: "copy"})
+	if !errors.Is(err, ErrPathConflict) {
+		t.Fatalf("expected ErrPathConflict, got %v", err)
+	}
+
+	if len(conflicts) != 2 || conflicts[0].Kind != OperationConflict || conflicts[0].Name != "GET /orders" || conflicts[1].Kind != OperationIDConflict {
+		t.Errorf("expected the operation and operationId conflicts to be reported, got %v", conflicts)
+	}
+
+	override := parseYAML(t, serviceASpec)
+	override.Root.Paths["/orders"].Get.OperationID = "listAllOrders"
+	merged, conflicts, err := Merge(MergeConfig{OnPathConflict: OverrideOnConflict}, MergeSource{Document: parseYAML(t, serviceASpec)}, MergeSource{Document: override, Name: "override"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if operationID := merged.Root.Paths["/orders"].Get.OperationID; operationID != "listAllOrders" {
+		t.Errorf("expected the operation of the document merged last, got %s", operationID)
+	}
+
+	if len(conflicts) != 1 || conflicts[0].Resolution != "overridden by operation of override" {
+		t.Errorf("expected the override to be reported, got %v", conflicts)
+	}
+}
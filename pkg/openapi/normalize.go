@@ -0,0 +1,186 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	defaultResponse = "default"
+)
+
+var (
+	// ErrDuplicateOperation occurs when a path item defines the same HTTP method more than once, eg. as "get" and "GET"
+	ErrDuplicateOperation = errors.New("operation is defined more than once")
+	// ErrDuplicateResponse occurs when an operation defines the same status code more than once, eg. as "2xx" and "2XX"
+	ErrDuplicateResponse = errors.New("response is defined more than once")
+
+	statusCodeRangePattern = regexp.MustCompile(`^[1-5][xX][xX]$`)
+)
+
+// Normalize puts the document into a canonical form, so the same API written by different authors or generators serializes the same way.
+// HTTP methods written in other cases than lowercase are moved into operations, status code ranges are uppercased ("2xx" becomes "2XX"), "default" responses are lowercased
+// and required lists of schemas are deduplicated and sorted.
+// Paths, components and other maps are sorted and keys of objects follow the order of the specification when the document is written, since YAML output is generated from the typed model.
+func (doc Document) Normalize() error {
+	var failures []normalizeFailure
+	normalizeObjects(reflect.ValueOf(doc.Root), func(object interface{}, location []string) {
+		var err error
+		switch object := object.(type) {
+		case *PathItem:
+			err = normalizeMethods(object)
+		case *Operation:
+			err = normalizeResponses(object)
+		case *Schema:
+			object.Required = sortedUnique(object.Required)
+		}
+
+		if err != nil {
+			failures = append(failures, normalizeFailure{location: itemsToPointer(location...), err: err})
+		}
+	}, nil, make(map[uintptr]bool))
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	// all failures are reported, with the first one wrapped so the error can be matched with errors.Is
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].location < failures[j].location
+	})
+
+	var others []string
+	for _, failure := range failures[1:] {
+		others = append(others, fmt.Sprintf("; %s: %v", failure.location, failure.err))
+	}
+
+	return fmt.Errorf("could not normalize the document: %s: %w%s", failures[0].location, failures[0].err, strings.Join(others, ""))
+}
+
+type normalizeFailure struct {
+	location string
+	err      error
+}
+
+// normalizeObjects walks the value and calls visit for every OpenAPI object (pointer to a struct) along with its location in the document.
+// Objects are visited before their descendants, so changes made by visit are walked too.
+func normalizeObjects(value reflect.Value, visit func(object interface{}, location []string), location []string, visited map[uintptr]bool) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() || value.Elem().Kind() != reflect.Struct || visited[value.Pointer()] {
+			return
+		}
+		visited[value.Pointer()] = true
+
+		visit(value.Interface(), location)
+		normalizeObjects(value.Elem(), visit, location, visited)
+	case reflect.Struct:
+		valueType := value.Type()
+		for i := 0; i < value.NumField(); i++ {
+			if valueType.Field(i).Name == ExtensionsField {
+				continue
+			}
+
+			normalizeObjects(value.Field(i), visit, append(location, getYamlKeyFromField(valueType.Field(i))), visited)
+		}
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			normalizeObjects(value.MapIndex(key), visit, append(location, fmt.Sprint(key.Interface())), visited)
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			normalizeObjects(value.Index(i), visit, append(location, fmt.Sprint(i)), visited)
+		}
+	}
+}
+
+// normalizeMethods moves operations written with non-lowercase HTTP methods, which are parsed as extensions, into operations of the path item
+func normalizeMethods(pathItem *PathItem) error {
+	for key, value := range pathItem.Extensions {
+		method := strings.ToLower(key)
+		if key == method || !isHTTPMethod(method) {
+			continue
+		}
+
+		if pathItem.Operation(method) != nil {
+			return fmt.Errorf("%w: %s", ErrDuplicateOperation, key)
+		}
+
+		operation, err := operationFromExtension(value)
+		if err != nil {
+			return fmt.Errorf("could not parse operation %s: %w", key, err)
+		}
+
+		pathItem.SetOperation(method, operation)
+		delete(pathItem.Extensions, key)
+	}
+
+	return nil
+}
+
+func operationFromExtension(value interface{}) (*Operation, error) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	operation := &Operation{}
+	err = yaml.Unmarshal(data, operation)
+	return operation, err
+}
+
+// normalizeResponses uppercases status code ranges and lowercases the default response key
+func normalizeResponses(operation *Operation) error {
+	for key, response := range operation.Responses {
+		code := normalizedStatusCode(key)
+		if code == key {
+			continue
+		}
+
+		if _, ok := operation.Responses[code]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicateResponse, key)
+		}
+
+		operation.Responses[code] = response
+		delete(operation.Responses, key)
+	}
+
+	return nil
+}
+
+func normalizedStatusCode(code string) string {
+	code = strings.TrimSpace(code)
+	if strings.EqualFold(code, defaultResponse) {
+		return defaultResponse
+	}
+
+	if statusCodeRangePattern.MatchString(code) {
+		return strings.ToUpper(code)
+	}
+
+	return code
+}
+
+func sortedUnique(values []string) []string {
+	if len(values) == 0 {
+		return values
+	}
+
+	seen := make(map[string]bool)
+	var unique []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+
+	sort.Strings(unique)
+	return unique
+}
@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	doc := parseYAML(t, `openapi: 3.0.0
+info:
+  version: "1"
+  title: shop
+paths:
+  /orders:
+    POST:
+      responses:
+        2xx:
+          description: created
+        Default:
+          description: error
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Order'
+  /customers:
+    x-owner: sales
+    get:
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    Order:
+      required: [status, id, status]
+      type: object
+      properties:
+        status:
+          type: string
+        id:
+          type: string
+`)
+
+	if err := doc.Normalize(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertYAML(t, "normalized", documentYAML(t, doc), `openapi: 3.0.0
+info:
+  title: shop
+  version: "1"
+paths:
+  /customers:
+    get:
+      responses:
+        "200":
+          description: ok
+    x-owner: sales
+  /orders:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Order'
+          description: ok
+    post:
+      responses:
+        2XX:
+          description: created
+        default:
+          description: error
+components:
+  schemas:
+    Order:
+      properties:
+        id:
+          type: string
+        status:
+          type: string
+      type: object
+      required:
+      - id
+      - status
+`)
+}
+
+func TestNormalizeDuplicates(t *testing.T) {
+	for name, tc := range map[string]struct {
+		spec     string
+		expected error
+	}{
+		"operation": {
+			spec: `paths:
+  /orders:
+    get:
+      responses: {}
+    GET:
+      responses: {}
+`,
+			expected: ErrDuplicateOperation,
+		},
+		"response": {
+			spec: `paths:
+  /orders:
+    get:
+      responses:
+        2xx:
+          description: ok
+        2XX:
+          description: ok
+`,
+			expected: ErrDuplicateResponse,
+		},
+	} {
+		doc := parseYAML(t, "openapi: 3.0.0\n"+tc.spec)
+		if err := doc.Normalize(); !errors.Is(err, tc.expected) {
+			t.Errorf("%s: expected %v, got %v", name, tc.expected, err)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package openapi
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 )
 
 var (
@@ -71,11 +73,17 @@ func (o *OasObject) parse() error {
 	parentVal := reflect.ValueOf(o.parent)
 	switch parentVal.Kind() {
 	case reflect.Ptr:
-		field := parentVal.Elem().FieldByName(o.name)
-		if field.IsZero() {
+		field, ok := fieldByName(parentVal.Elem(), o.name)
+		if !ok || field.IsZero() {
 			return ErrFieldWithNameUnusable
 		}
 
+		// objects held by value, eg. XML of a schema, are referenced by a pointer, so they are parsed like the other objects
+		if field.Kind() == reflect.Struct {
+			o.instance = field.Addr().Interface()
+			return nil
+		}
+
 		o.instance = field.Interface()
 		return nil
 	case reflect.Map:
@@ -105,17 +113,18 @@ func (o *OasObject) Create() error {
 
 	switch parentType.Kind() {
 	case reflect.Ptr:
-		structField, ok := objectType.FieldByName(o.name)
+		idx, ok := fieldsOf(objectType).byName[o.name]
 		if !ok {
 			return ErrFieldWithNameNotInType
 		}
 
 		// Slices to be implemented
-		if structField.Type.Kind() != reflect.Map {
+		fieldType := objectType.Field(idx).Type
+		if fieldType.Kind() != reflect.Map {
 			return nil
 		}
 
-		newMap := reflect.MakeMap(structField.Type).Interface()
+		newMap := reflect.MakeMap(fieldType).Interface()
 		o.Set(newMap)
 	case reflect.Map:
 		childVal := reflect.New(objectType).Elem().Interface()
@@ -136,11 +145,18 @@ func (o *OasObject) Set(val interface{}) error {
 	case reflect.Slice:
 		parentVal.Index(o.idx).Set(refVal)
 	case reflect.Ptr:
-		field := parentVal.Elem().FieldByName(o.name)
+		field, ok := fieldByName(parentVal.Elem(), o.name)
+		if !ok {
+			return ErrFieldWithNameNotInType
+		}
+
 		field.Set(refVal)
 	case reflect.Map:
-		childKey := reflect.New(reflect.TypeOf(o.parent).Key()).Elem()
-		childKey.Set(reflect.ValueOf(o.name))
+		childKey, ok := mapKey(parentVal.Type(), o.name)
+		if !ok {
+			return ErrIncorrectParent
+		}
+
 		parentVal.SetMapIndex(childKey, refVal)
 	default:
 		return ErrIncorrectParent
@@ -157,201 +173,155 @@ func (o OasObject) Unset() error {
 	return o.Set(nil)
 }
 
-// ChangeRefPath sets a $ref property of an object
+// ChangeRefPath sets a $ref property of an object. Objects held by extensions or fields not handled by the types are generic maps, which have the $ref key set instead
 func (o OasObject) ChangeRefPath(newRefPath string) error {
-	oasObjectStruct := reflect.ValueOf(o.instance).Elem()
-	newRefPathValue := reflect.ValueOf(newRefPath)
+	if value := reflect.ValueOf(o.instance); value.Kind() == reflect.Map {
+		key, _, err := itemFromMapByName(value, RefTag)
+		if err != nil {
+			return err
+		}
 
-	refFieldName, err := getFieldNameByTag(RefTag, oasObjectStruct)
-	if err != nil {
-		return err
+		value.SetMapIndex(key, reflect.ValueOf(newRefPath))
+		return nil
 	}
 
-	refField := oasObjectStruct.FieldByName(refFieldName)
-	refField.Set(newRefPathValue)
+	oasObjectStruct := reflect.ValueOf(o.instance).Elem()
 
+	idx, ok := fieldsOf(oasObjectStruct.Type()).byTag[RefTag]
+	if !ok {
+		return ErrNoFieldWithTag
+	}
+
+	oasObjectStruct.Field(idx).Set(reflect.ValueOf(newRefPath))
 	return nil
 }
 
 // references returns list of all references that need to be resolved for object to be independent from its references.
-// That list includes children references along with object's own references since parsing is done recursively until refs in all possible descendants are found.
+// That list includes children references along with object's own references, collected in a single pass over descendants of the object.
 func (o OasObject) references() ([]reference, error) {
-	var allRefs []reference
+	var refs []reference
+	err := o.collectReferences(&refs)
+	return refs, err
+}
 
+func (o OasObject) collectReferences(refs *[]reference) error {
 	value := reflect.ValueOf(o.instance)
 
 	switch value.Kind() {
 	case reflect.Ptr:
-		refPath, fields, err := parsePtrValue(value)
-		if err != nil {
-			return allRefs, err
-		}
-
-		if refPath != "" {
-			ref := reference{
-				object: o,
-				path:   refPath,
-			}
-			allRefs = append(allRefs, ref)
-		} else { // when refPath is in an oas object that is not a slice or map, other tahn $ref fields can be ignored per specification
-			for _, field := range fields {
-				obj, err := OasObjectByName(value.Interface(), field, false)
-				if err != nil {
-					return allRefs, err
-				}
+		item := value.Elem()
+		fields := fieldsOf(item.Type())
 
-				objRefs, err := obj.references()
-				if err != nil {
-					return allRefs, err
-				}
-
-				allRefs = append(allRefs, objRefs...)
+		if fields.ref >= 0 {
+			if refPath := item.Field(fields.ref).String(); refPath != "" { // when ref found in an object then no need to parse other fields, per specification
+				*refs = append(*refs, reference{object: o, path: refPath})
+				return nil
 			}
 		}
-	case reflect.Map:
-		refPaths, keys, err := parseMapValue(value)
-		if err != nil {
-			return allRefs, err
-		}
 
-		for _, refPath := range refPaths {
-			ref := reference{
-				object: o,
-				path:   refPath,
+		for _, idx := range fields.nested {
+			child := item.Field(idx)
+			if child.IsZero() {
+				continue
 			}
-			allRefs = append(allRefs, ref)
-		}
 
-		for _, key := range keys {
-			obj, err := OasObjectByName(value.Interface(), key, false)
-			if err != nil {
-				return allRefs, err
+			if child.Kind() == reflect.Struct { // objects embedded by value are walked through their address, so their references can be replaced
+				child = child.Addr()
 			}
 
-			newRefs, err := obj.references()
+			err := OasObject{parent: o.instance, instance: child.Interface(), name: fields.names[idx]}.collectReferences(refs)
 			if err != nil {
-				return allRefs, err
+				return err
 			}
-			allRefs = append(allRefs, newRefs...)
-		}
-	case reflect.Slice:
-		refPaths, indexes, err := parseSliceValue(value)
-		if err != nil {
-			return allRefs, err
 		}
+	case reflect.Map:
+		extensions := o.name == ExtensionsField
+		mapIter := value.MapRange()
+		for mapIter.Next() {
+			child := rawValue(mapIter.Value())
+			key := mapKeyString(mapIter.Key())
+
+			// values of extensions (x- fields) are not walked, since their content is not defined by the specification
+			if child.IsZero() || (extensions && isExtension(key)) {
+				continue
+			}
 
-		for _, refPath := range refPaths {
-			ref := reference{
-				object: o,
-				path:   refPath,
+			switch child.Kind() {
+			case reflect.String:
+				if key == RefTag {
+					*refs = append(*refs, reference{object: o, path: child.String()})
+				}
+			case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Array:
+				err := OasObject{parent: o.instance, instance: child.Interface(), name: key}.collectReferences(refs)
+				if err != nil {
+					return err
+				}
 			}
-			allRefs = append(allRefs, ref)
 		}
-
-		for _, idx := range indexes {
-			obj, err := OasObjectByIdx(value.Interface(), idx)
-			if err != nil {
-				return allRefs, err
+	case reflect.Slice:
+		for idx := 0; idx < value.Len(); idx++ {
+			child := rawValue(value.Index(idx))
+			if child.IsZero() {
+				continue
 			}
 
-			newRefs, err := obj.references()
-			if err != nil {
-				return allRefs, err
+			switch child.Kind() {
+			case reflect.String:
+				if child.String() == Ref {
+					*refs = append(*refs, reference{object: o, path: child.String()})
+				}
+			case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Array:
+				err := OasObject{parent: o.instance, instance: child.Interface(), idx: idx}.collectReferences(refs)
+				if err != nil {
+					return err
+				}
 			}
-			allRefs = append(allRefs, newRefs...)
 		}
 	default:
-		return allRefs, ErrIncorrectObjectType
+		return ErrIncorrectObjectType
 	}
 
-	return allRefs, nil
+	return nil
 }
 
-func parsePtrValue(value reflect.Value) (string, []string, error) {
-	var fieldsToParse []string
-
-	itemElem := value.Elem()
-	itemType := itemElem.Type()
-	for i := 0; i < itemElem.NumField(); i++ {
-		childItem := itemElem.Field(i)
-
-		if childItem.IsZero() {
-			continue
-		}
-
-		switch childItem.Kind() {
-		case reflect.String:
-			if itemType.Field(i).Name == Ref {
-				return childItem.String(), []string{}, nil // when ref found in an object then no need to parse other fields
-			}
-		case reflect.Struct, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Array:
-			fieldsToParse = append(fieldsToParse, itemType.Field(i).Name)
-		}
+func itemFromMapByName(mapVal reflect.Value, key string) (reflect.Value, reflect.Value, error) {
+	mapKeyVal, ok := mapKey(mapVal.Type(), key)
+	if !ok {
+		return reflect.Value{}, reflect.Value{}, ErrNoValueWithKey
 	}
 
-	return "", fieldsToParse, nil
-}
-
-func parseMapValue(value reflect.Value) ([]string, []string, error) {
-	var keysToParse []string
-	var refs []string
-
-	mapIter := value.MapRange()
-	for mapIter.Next() {
-		childItem := mapIter.Value()
-
-		if childItem.IsZero() {
-			continue
-		}
-
-		switch childItem.Kind() {
-		case reflect.String:
-			if mapIter.Key().String() == Ref {
-				refs = append(refs, childItem.String())
-			}
-		case reflect.Struct, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Array:
-			keysToParse = append(keysToParse, mapIter.Key().String())
-		}
+	value := mapVal.MapIndex(mapKeyVal)
+	if !value.IsValid() {
+		return reflect.Value{}, reflect.Value{}, ErrNoValueWithKey
 	}
 
-	return refs, keysToParse, nil
+	return mapKeyVal, value, nil
 }
 
-func parseSliceValue(value reflect.Value) ([]string, []int, error) {
-	var refs []string
-	var indexesToParse []int
-
-	for i := 0; i < value.Len(); i++ {
-		childItem := value.Index(i)
-
-		if childItem.IsZero() {
-			continue
-		}
+func isNotExisitngObject(err error) bool {
+	return errors.Is(err, ErrFieldWithNameUnusable) || errors.Is(err, ErrNoValueWithKey)
+}
 
-		switch childItem.Kind() {
-		case reflect.String:
-			if childItem.String() == Ref {
-				refs = append(refs, childItem.String())
-			}
-		case reflect.Struct, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Array:
-			indexesToParse = append(indexesToParse, i)
-		}
+// rawValue unwraps a value held by an interface, eg. a value of an extension or of a field not handled by the types, which is decoded into generic maps and slices
+func rawValue(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Interface && !value.IsNil() {
+		value = value.Elem()
 	}
 
-	return refs, indexesToParse, nil
+	return value
 }
 
-func itemFromMapByName(mapVal reflect.Value, key string) (reflect.Value, reflect.Value, error) {
-	mapIter := mapVal.MapRange()
-	for mapIter.Next() {
-		if mapIter.Key().String() == key {
-			return mapIter.Key(), mapIter.Value(), nil
-		}
+// mapKeyString returns the key of a map as a string, including keys of generic maps, which are held by interfaces
+func mapKeyString(key reflect.Value) string {
+	key = rawValue(key)
+	if key.Kind() == reflect.String {
+		return key.String()
 	}
 
-	return reflect.Value{}, reflect.Value{}, ErrNoValueWithKey
+	return fmt.Sprint(key.Interface())
 }
 
-func isNotExisitngObject(err error) bool {
-	return errors.Is(err, ErrFieldWithNameUnusable) || errors.Is(err, ErrNoValueWithKey)
+// isExtension checks whether the key of a field is a specification extension
+func isExtension(key string) bool {
+	return strings.HasPrefix(key, extensionPrefix)
 }
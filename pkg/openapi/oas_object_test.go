@@ -0,0 +1,146 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestResolveReferencesSchemaWithXML(t *testing.T) {
+	doc := parseYAML(t, `openapi: 3.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: pets
+          content:
+            application/xml:
+              schema:
+                $ref: '#/components/schemas/Pet'
+components:
+  schemas:
+    Pet:
+      type: object
+      xml:
+        name: pet
+      properties:
+        owner:
+          $ref: '#/components/schemas/Owner'
+    Owner:
+      type: string
+      xml:
+        name: owner
+`)
+	doc.Cfg.InlineLocalRefs = true
+
+	err := doc.ResolveReferences()
+	if err != nil {
+		t.Fatalf("could not resolve references of schemas with an xml object: %v", err)
+	}
+
+	schema := doc.Root.Paths["/pets"].Get.Responses["200"].Content["application/xml"].Schema
+	if schema.Ref != "" || schema.XML.Extensions["name"] != "pet" || schema.Properties["owner"].Type != "string" {
+		t.Errorf("expected the schema to be inlined along with its xml object, got %+v", schema)
+	}
+}
+
+const benchmarkOperations = 1000
+
+// largeSpec returns a document with the count of operations, each of them referencing its own schema and shared components
+func largeSpec(count int) string {
+	var spec strings.Builder
+	spec.WriteString("openapi: 3.0.0\ninfo:\n  title: Bench\n  version: \"1.0\"\npaths:\n")
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&spec, `  /resources%d/{id}:
+    get:
+      operationId: getResource%d
+      parameters:
+      - $ref: '#/components/parameters/Id'
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Resource%d'
+`, i, i, i)
+	}
+
+	spec.WriteString(`components:
+  parameters:
+    Id:
+      name: id
+      in: path
+      required: true
+      schema:
+        type: string
+  schemas:
+    Money:
+      type: object
+      xml:
+        name: money
+      properties:
+        amount:
+          type: number
+`)
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&spec, `    Resource%d:
+      type: object
+      properties:
+        id:
+          type: string
+        price:
+          $ref: '#/components/schemas/Money'
+`, i)
+	}
+
+	return spec.String()
+}
+
+func BenchmarkResolveReferences(b *testing.B) {
+	spec := largeSpec(benchmarkOperations)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		doc := parseYAML(b, spec)
+		doc.Cfg.InlineLocalRefs = true
+		b.StartTimer()
+
+		err := doc.ResolveReferences()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetOrCreateObjectByPath(b *testing.B) {
+	doc := parseYAML(b, largeSpec(benchmarkOperations))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := doc.getOrCreateObjectByPath(fmt.Sprintf("#/components/schemas/Resource%d/properties/price", i%benchmarkOperations), false)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReferences(b *testing.B) {
+	doc := parseYAML(b, largeSpec(benchmarkOperations))
+	rootObject, err := OasObjectByName(&doc, RootItem, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		refs, err := rootObject.references()
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if len(refs) != 3*benchmarkOperations {
+			b.Fatalf("expected %d references, got %d", 3*benchmarkOperations, len(refs))
+		}
+	}
+}
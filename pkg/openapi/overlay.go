@@ -0,0 +1,245 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	overlayMajorVersion = "1."
+)
+
+var (
+	// ErrInvalidOverlay occurs when an overlay document does not follow the Overlay specification
+	ErrInvalidOverlay = errors.New("invalid overlay")
+)
+
+// Overlay is an OpenAPI Overlay 1.0 document: a list of actions updating or removing nodes of a specification selected by JSONPath targets
+type Overlay struct {
+	Version    string                 `yaml:"overlay,omitempty"`
+	Info       *OverlayInfo           `yaml:"info,omitempty"`
+	Extends    string                 `yaml:"extends,omitempty"`
+	Actions    []*OverlayAction       `yaml:"actions,omitempty"`
+	Extensions map[string]interface{} `yaml:",inline"`
+}
+
+// OverlayInfo ...
+type OverlayInfo struct {
+	Title      string                 `yaml:"title,omitempty"`
+	Version    string                 `yaml:"version,omitempty"`
+	Extensions map[string]interface{} `yaml:",inline"`
+}
+
+// OverlayAction updates or removes nodes matched by the target
+type OverlayAction struct {
+	Target      string                 `yaml:"target,omitempty"`
+	Description string                 `yaml:"description,omitempty"`
+	Update      interface{}            `yaml:"update,omitempty"`
+	Remove      bool                   `yaml:"remove,omitempty"`
+	Extensions  map[string]interface{} `yaml:",inline"`
+}
+
+// removedNode replaces elements of sequences removed by actions, until sequences are compacted
+type removedNode struct{}
+
+// ReadOverlay reads and validates an overlay file
+func ReadOverlay(path string) (Overlay, error) {
+	var overlay Overlay
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return overlay, err
+	}
+
+	err = yaml.Unmarshal(data, &overlay)
+	if err != nil {
+		return overlay, err
+	}
+
+	return overlay, overlay.validate()
+}
+
+// ApplyOverlay applies actions of the overlay to the document, in order.
+// An update is merged recursively into matched objects and appended to matched sequences, while a remove deletes matched nodes.
+// Returned list holds actions whose targets matched no nodes, which is not an error according to the specification, but usually a mistake worth reporting.
+func (doc Document) ApplyOverlay(overlay Overlay) ([]*OverlayAction, error) {
+	var unmatched []*OverlayAction
+
+	err := overlay.validate()
+	if err != nil {
+		return unmatched, err
+	}
+
+	data, err := yaml.Marshal(doc.Root)
+	if err != nil {
+		return unmatched, err
+	}
+
+	var root interface{}
+	err = yaml.Unmarshal(data, &root)
+	if err != nil {
+		return unmatched, err
+	}
+
+	for _, action := range overlay.Actions {
+		target, err := parseJSONPath(action.Target)
+		if err != nil {
+			return unmatched, err
+		}
+
+		matches := target.matches(root)
+		if len(matches) == 0 {
+			unmatched = append(unmatched, action)
+			continue
+		}
+
+		if action.Remove {
+			removeMatches(matches)
+			root = compactRaw(root)
+			continue
+		}
+
+		for _, match := range matches {
+			root, err = updateMatch(root, match, action.Update)
+			if err != nil {
+				return unmatched, fmt.Errorf("could not apply action with target %s: %w", action.Target, err)
+			}
+		}
+	}
+
+	data, err = yaml.Marshal(root)
+	if err != nil {
+		return unmatched, err
+	}
+
+	*doc.Root = OpenAPI{}
+	return unmatched, yaml.Unmarshal(data, doc.Root)
+}
+
+func (o Overlay) validate() error {
+	if !strings.HasPrefix(o.Version, overlayMajorVersion) {
+		return fmt.Errorf("%w: unsupported overlay version %q", ErrInvalidOverlay, o.Version)
+	}
+
+	for idx, action := range o.Actions {
+		if action == nil || action.Target == "" {
+			return fmt.Errorf("%w: action %d has no target", ErrInvalidOverlay, idx)
+		}
+
+		if action.Update == nil && !action.Remove {
+			return fmt.Errorf("%w: action with target %s neither updates nor removes", ErrInvalidOverlay, action.Target)
+		}
+	}
+
+	return nil
+}
+
+// removeMatches deletes matched map entries and marks matched sequence elements as removed, in reverse document order so indexes stay valid
+func removeMatches(matches []rawMatch) {
+	for idx := len(matches) - 1; idx >= 0; idx-- {
+		switch parent := matches[idx].parent.(type) {
+		case map[interface{}]interface{}:
+			delete(parent, matches[idx].key)
+		case []interface{}:
+			parent[matches[idx].key.(int)] = removedNode{}
+		}
+	}
+}
+
+// updateMatch merges the update into the matched node, returning the root, which is replaced when the root itself is matched
+func updateMatch(root interface{}, match rawMatch, update interface{}) (interface{}, error) {
+	updated := mergeRaw(match.value, copyRaw(update))
+
+	switch parent := match.parent.(type) {
+	case nil:
+		if _, ok := updated.(map[interface{}]interface{}); !ok {
+			return root, fmt.Errorf("root can only be updated with an object")
+		}
+
+		return updated, nil
+	case map[interface{}]interface{}:
+		parent[match.key] = updated
+	case []interface{}:
+		parent[match.key.(int)] = updated
+	}
+
+	return root, nil
+}
+
+// mergeRaw merges the update into the node: objects are merged recursively, sequences are appended to and other values are replaced
+func mergeRaw(node interface{}, update interface{}) interface{} {
+	switch node := node.(type) {
+	case map[interface{}]interface{}:
+		updateMap, ok := update.(map[interface{}]interface{})
+		if !ok {
+			return update
+		}
+
+		for key, value := range updateMap {
+			if current, ok := node[key]; ok {
+				node[key] = mergeRaw(current, value)
+			} else {
+				node[key] = value
+			}
+		}
+
+		return node
+	case []interface{}:
+		if updateSequence, ok := update.([]interface{}); ok {
+			return append(node, updateSequence...)
+		}
+
+		return append(node, update)
+	default:
+		return update
+	}
+}
+
+// copyRaw deeply copies maps and sequences, so an update applied to many nodes does not make them share content
+func copyRaw(node interface{}) interface{} {
+	switch node := node.(type) {
+	case map[interface{}]interface{}:
+		copied := make(map[interface{}]interface{}, len(node))
+		for key, value := range node {
+			copied[key] = copyRaw(value)
+		}
+
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, 0, len(node))
+		for _, value := range node {
+			copied = append(copied, copyRaw(value))
+		}
+
+		return copied
+	default:
+		return node
+	}
+}
+
+// compactRaw drops elements of sequences marked as removed
+func compactRaw(node interface{}) interface{} {
+	switch node := node.(type) {
+	case map[interface{}]interface{}:
+		for key, value := range node {
+			node[key] = compactRaw(value)
+		}
+
+		return node
+	case []interface{}:
+		compacted := make([]interface{}, 0, len(node))
+		for _, value := range node {
+			if _, removed := value.(removedNode); !removed {
+				compacted = append(compacted, compactRaw(value))
+			}
+		}
+
+		return compacted
+	default:
+		return node
+	}
+}
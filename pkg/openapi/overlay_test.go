@@ -0,0 +1,109 @@
+package openapi
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyOverlay(t *testing.T) {
+	doc := parseYAML(t, `openapi: 3.0.0
+info:
+  title: shop
+  version: "1"
+servers:
+- url: https://dev.example.com
+paths:
+  /orders:
+    get:
+      parameters:
+      - name: limit
+        in: query
+      - name: debug
+        in: query
+        x-internal: true
+      responses:
+        "200":
+          description: ok
+  /admin:
+    get:
+      responses:
+        "200":
+          description: ok
+`)
+
+	dir := writeFiles(t, map[string]string{
+		"overlay.yaml": `overlay: 1.0.0
+info:
+  title: production
+  version: "1"
+actions:
+- target: $.info
+  update:
+    title: shop (production)
+    x-environment: production
+- target: $.servers
+  update:
+    url: https://api.example.com
+- target: $..parameters[?(@.x-internal == true)]
+  remove: true
+- target: $.paths['/admin']
+  remove: true
+- target: $.paths['/missing']
+  update:
+    x-owner: nobody
+`,
+	})
+
+	overlay, err := ReadOverlay(filepath.Join(dir, "overlay.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	unmatched, err := doc.ApplyOverlay(overlay)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(unmatched) != 1 || unmatched[0].Target != "$.paths['/missing']" {
+		t.Errorf("expected the action with a missing target to be reported, got %+v", unmatched)
+	}
+
+	assertYAML(t, "overlay", documentYAML(t, doc), `openapi: 3.0.0
+info:
+  title: shop (production)
+  version: "1"
+  x-environment: production
+paths:
+  /orders:
+    get:
+      parameters:
+      - name: limit
+        in: query
+      responses:
+        "200":
+          description: ok
+servers:
+- url: https://dev.example.com
+- url: https://api.example.com
+`)
+}
+
+func TestOverlayValidation(t *testing.T) {
+	doc := parseYAML(t, "openapi: 3.0.0\n")
+
+	for name, overlay := range map[string]Overlay{
+		"version":   {Version: "2.0.0"},
+		"no target": {Version: "1.0.0", Actions: []*OverlayAction{{Remove: true}}},
+		"no action": {Version: "1.0.0", Actions: []*OverlayAction{{Target: "$.info"}}},
+	} {
+		if _, err := doc.ApplyOverlay(overlay); !errors.Is(err, ErrInvalidOverlay) {
+			t.Errorf("%s: expected ErrInvalidOverlay, got %v", name, err)
+		}
+	}
+
+	invalidTarget := Overlay{Version: "1.0.0", Actions: []*OverlayAction{{Target: "info", Remove: true}}}
+	if _, err := doc.ApplyOverlay(invalidTarget); !errors.Is(err, ErrInvalidJSONPath) {
+		t.Errorf("expected ErrInvalidJSONPath, got %v", err)
+	}
+}
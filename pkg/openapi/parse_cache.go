@@ -0,0 +1,133 @@
+package openapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// parseCacheVersion is a part of keys of parsed documents, so documents persisted by versions with a different model are not used
+	parseCacheVersion = "openapi-utils/1"
+	parsedSuffix      = ".gob"
+)
+
+func init() {
+	gob.Register(map[interface{}]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// ParseCache keeps parsed documents keyed by hashes of their content, so documents that did not change are not parsed again, eg. in watch mode or in CI re-runs.
+// Documents are kept in memory, and persisted in Dir when it is provided. The zero value is ready to use and it is safe for concurrent use.
+type ParseCache struct {
+	// Dir is a directory where parsed documents are persisted between runs. Documents are kept only in memory when empty
+	Dir string
+
+	mu        sync.RWMutex
+	documents map[string][]byte
+}
+
+// parse unmarshalls the data into the root, decoding the parsed document instead when the same data has been parsed before. A nil ParseCache always unmarshalls the data
+func (c *ParseCache) parse(data []byte, root *OpenAPI) error {
+	if c == nil {
+		return yaml.Unmarshal(data, root)
+	}
+
+	key := parsedKey(data)
+	if encoded, ok := c.get(key); ok {
+		var parsed OpenAPI
+		if gob.NewDecoder(bytes.NewReader(encoded)).Decode(&parsed) == nil {
+			*root = parsed
+			return nil
+		}
+	}
+
+	err := yaml.Unmarshal(data, root)
+	if err != nil {
+		return err
+	}
+
+	var encoded bytes.Buffer
+	err = gob.NewEncoder(&encoded).Encode(root)
+	if err != nil { // the document is parsed, it is just not cached
+		return nil
+	}
+
+	c.put(key, encoded.Bytes())
+	return nil
+}
+
+func (c *ParseCache) get(key string) ([]byte, bool) {
+	c.mu.RLock()
+	encoded, ok := c.documents[key]
+	c.mu.RUnlock()
+
+	if ok || c.Dir == "" {
+		return encoded, ok
+	}
+
+	encoded, err := ioutil.ReadFile(filepath.Join(c.Dir, key+parsedSuffix))
+	if err != nil {
+		return nil, false
+	}
+
+	c.store(key, encoded)
+	return encoded, true
+}
+
+// put keeps the parsed document in memory and persists it. Documents that could not be persisted are only kept in memory
+func (c *ParseCache) put(key string, encoded []byte) {
+	c.store(key, encoded)
+	if c.Dir == "" {
+		return
+	}
+
+	err := os.MkdirAll(c.Dir, os.FileMode(0755))
+	if err != nil {
+		return
+	}
+
+	tmpFile, err := ioutil.TempFile(c.Dir, key+"-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(encoded)
+	if err == nil {
+		err = tmpFile.Chmod(os.FileMode(0644))
+	}
+
+	closeErr := tmpFile.Close()
+	if err != nil || closeErr != nil {
+		return
+	}
+
+	os.Rename(tmpFile.Name(), filepath.Join(c.Dir, key+parsedSuffix))
+}
+
+func (c *ParseCache) store(key string, encoded []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.documents == nil {
+		c.documents = make(map[string][]byte)
+	}
+
+	c.documents[key] = encoded
+}
+
+// parsedKey returns a hash of the content of the document
+func parsedKey(data []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(parseCacheVersion))
+	hash.Write(data)
+	return hex.EncodeToString(hash.Sum(nil))
+}
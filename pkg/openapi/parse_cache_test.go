@@ -0,0 +1,150 @@
+package openapi
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const parseCacheSpec = `openapi: 3.0.0
+info:
+  title: Pets
+components:
+  schemas:
+    Pet:
+      type: object
+      x-owner:
+        team: pets
+`
+
+func TestParseCache(t *testing.T) {
+	cache := &ParseCache{}
+	first := NewDocument(Config{ParseCache: cache})
+	err := first.Parse([]byte(parseCacheSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second := NewDocument(Config{ParseCache: cache})
+	err = second.Parse([]byte(parseCacheSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cache.documents) != 1 {
+		t.Errorf("expected the same content to be cached once, got %d documents", len(cache.documents))
+	}
+
+	if !reflect.DeepEqual(first.Root, second.Root) {
+		t.Errorf("expected the cached document to equal the parsed one, got %+v and %+v", first.Root, second.Root)
+	}
+
+	if second.Root == first.Root || second.Root.Components.Schemas["Pet"] == first.Root.Components.Schemas["Pet"] {
+		t.Errorf("expected documents decoded from the cache not to share objects")
+	}
+}
+
+func TestParseCachePersisted(t *testing.T) {
+	dir := t.TempDir()
+	err := NewDocument(Config{ParseCache: &ParseCache{Dir: dir}}).Parse([]byte(parseCacheSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	persisted := filepath.Join(dir, parsedKey([]byte(parseCacheSpec))+parsedSuffix)
+	if _, err := os.Stat(persisted); err != nil {
+		t.Fatalf("expected the parsed document to be persisted: %v", err)
+	}
+
+	cache := &ParseCache{Dir: dir}
+	if _, ok := cache.get(parsedKey([]byte(parseCacheSpec))); !ok {
+		t.Fatalf("expected the persisted document to be read by another cache")
+	}
+
+	doc := NewDocument(Config{ParseCache: cache})
+	err = doc.Parse([]byte(parseCacheSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.Root.Info.Title != "Pets" {
+		t.Errorf("expected the persisted document to be decoded, got %+v", doc.Root.Info)
+	}
+}
+
+func TestParseCacheCorruptedEntry(t *testing.T) {
+	dir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(dir, parsedKey([]byte(parseCacheSpec))+parsedSuffix), []byte("corrupted"), os.FileMode(0644))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := NewDocument(Config{ParseCache: &ParseCache{Dir: dir}})
+	err = doc.Parse([]byte(parseCacheSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.Root.Info.Title != "Pets" {
+		t.Errorf("expected the document to be parsed again, got %+v", doc.Root.Info)
+	}
+}
+
+func TestReferencedDocumentsSharedPerSession(t *testing.T) {
+	loader := &recordingLoader{files: MapLoader{
+		"specs/openapi.yaml": []byte(`openapi: 3.0.0
+components:
+  schemas:
+    Pet:
+      $ref: 'pets/pet.yaml#/components/schemas/Pet'
+    Owner:
+      $ref: 'common.yaml#/components/schemas/Owner'
+`),
+		"specs/pets/pet.yaml": []byte(`components:
+  schemas:
+    Pet:
+      title: pet
+      properties:
+        owner:
+          $ref: '../common.yaml#/components/schemas/Owner'
+`),
+		"specs/common.yaml": []byte("components:\n  schemas:\n    Owner:\n      title: owner\n"),
+	}}
+
+	doc, err := ParseDocument(Config{Loader: loader}, "specs/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(loader.loads) != 3 {
+		t.Errorf("expected every document to be loaded once, got %v", loader.loads)
+	}
+
+	if paths := doc.ReferencedDocuments.Paths(); len(paths) != 2 {
+		t.Errorf("expected 2 referenced documents in the session, got %v", paths)
+	}
+}
+
+func TestCanonicalDocumentPath(t *testing.T) {
+	tests := map[string]string{
+		"https://Schemas.Example.com/specs/../common.yaml#/components": "https://schemas.example.com/common.yaml",
+		"https://schemas.example.com/./common.yaml":                    "https://schemas.example.com/common.yaml",
+	}
+
+	for documentPath, expected := range tests {
+		if canonical := canonicalDocumentPath(documentPath); canonical != expected {
+			t.Errorf("%s: expected %s, got %s", documentPath, expected, canonical)
+		}
+	}
+
+	absPath, err := filepath.Abs("specs/common.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if canonical := canonicalDocumentPath("specs/pets/../common.yaml"); canonical != absPath {
+		t.Errorf("expected %s, got %s", absPath, canonical)
+	}
+}
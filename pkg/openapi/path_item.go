@@ -0,0 +1,64 @@
+package openapi
+
+import "strings"
+
+// HTTPMethods lists lowercase HTTP methods for which a PathItem can hold an operation, in order of PathItem fields
+var HTTPMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Operation returns an operation defined for the HTTP method, or nil when there is none
+func (p *PathItem) Operation(method string) *Operation {
+	switch strings.ToLower(method) {
+	case "get":
+		return p.Get
+	case "put":
+		return p.Put
+	case "post":
+		return p.Post
+	case "delete":
+		return p.Delete
+	case "options":
+		return p.Options
+	case "head":
+		return p.Head
+	case "patch":
+		return p.Patch
+	case "trace":
+		return p.Trace
+	default:
+		return nil
+	}
+}
+
+// SetOperation replaces an operation defined for the HTTP method. Nil operation removes it from the PathItem
+func (p *PathItem) SetOperation(method string, operation *Operation) {
+	switch strings.ToLower(method) {
+	case "get":
+		p.Get = operation
+	case "put":
+		p.Put = operation
+	case "post":
+		p.Post = operation
+	case "delete":
+		p.Delete = operation
+	case "options":
+		p.Options = operation
+	case "head":
+		p.Head = operation
+	case "patch":
+		p.Patch = operation
+	case "trace":
+		p.Trace = operation
+	}
+}
+
+// Operations returns operations defined by the PathItem, keyed by lowercase HTTP method
+func (p *PathItem) Operations() map[string]*Operation {
+	operations := make(map[string]*Operation)
+	for _, method := range HTTPMethods {
+		if operation := p.Operation(method); operation != nil {
+			operations[method] = operation
+		}
+	}
+
+	return operations
+}
@@ -0,0 +1,201 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	// DefaultParallelism is the number of documents loaded at the same time when Config has no Parallelism
+	DefaultParallelism = 8
+)
+
+var (
+	// ErrCircularReference occurs when documents reference each other in a cycle, eg. a.yaml references b.yaml, which references a.yaml
+	ErrCircularReference = errors.New("documents reference each other in a cycle")
+)
+
+// pendingDocument is a document referenced by the parent document, waiting to be loaded
+type pendingDocument struct {
+	parent       Document
+	documentPath string
+	filePath     string
+}
+
+// LoadReferencedDocuments loads and parses documents referenced by the document, and documents referenced by them, concurrently - at most Parallelism of the config at a time.
+// Documents are loaded level by level, so each of them is loaded at the shortest depth it is referenced at. Loading stops at the first error or when the context is canceled.
+// ResolveReferences uses loaded documents, and loads them itself when they were not loaded beforehand.
+func (doc Document) LoadReferencedDocuments(ctx context.Context) error {
+	cache := doc.ReferencedDocuments
+	cache.markLoaded()
+
+	doc.Cfg.setSandboxRoot(doc.RefDirectory)
+
+	seen := make(map[string]bool)
+	level := []Document{doc}
+	for len(level) > 0 {
+		var pending []pendingDocument
+		for _, parent := range level {
+			documentPaths, err := parent.referencedDocumentPaths()
+			if err != nil {
+				return err
+			}
+
+			for _, documentPath := range documentPaths {
+				filePath := joinDocumentPath(parent.RefDirectory, documentPath)
+				key := canonicalDocumentPath(filePath)
+				if _, ok := cache.Get(key); ok || seen[key] {
+					continue
+				}
+
+				seen[key] = true
+				pending = append(pending, pendingDocument{
+					parent:       parent,
+					documentPath: documentPath,
+					filePath:     filePath,
+				})
+			}
+		}
+
+		loaded, err := loadConcurrently(ctx, pending, doc.Cfg.parallelism())
+		if err != nil {
+			return err
+		}
+
+		for idx, document := range loaded {
+			cache.setDocument(pending[idx].filePath, document, documentParsed)
+		}
+
+		level = level[:0]
+		for _, document := range loaded {
+			level = append(level, *document)
+		}
+	}
+
+	return nil
+}
+
+// loadConcurrently loads pending documents with at most parallelism loads at a time. The error of the first pending document that failed is returned
+func loadConcurrently(ctx context.Context, pending []pendingDocument, parallelism int) ([]*Document, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	loaded := make([]*Document, len(pending))
+	errs := make([]error, len(pending))
+	semaphore := make(chan struct{}, parallelism)
+
+	var wg sync.WaitGroup
+	for idx, document := range pending {
+		wg.Add(1)
+		go func(idx int, document pendingDocument) {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				errs[idx] = ctx.Err()
+				return
+			}
+
+			if err := ctx.Err(); err != nil {
+				errs[idx] = err
+				return
+			}
+
+			loaded[idx], errs[idx] = document.parent.loadReferencedDocument(ctx, document.documentPath, document.filePath)
+			if errs[idx] != nil {
+				cancel()
+			}
+		}(idx, document)
+	}
+
+	wg.Wait()
+
+	var canceled error
+	for idx, err := range errs {
+		switch {
+		case err == nil:
+		case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+			if canceled == nil {
+				canceled = err
+			}
+		default:
+			return nil, fmt.Errorf("could not load %s: %w", pending[idx].filePath, err)
+		}
+	}
+
+	return loaded, canceled
+}
+
+// loadReferencedDocument reads and parses the document referenced by the document, without resolving its references
+func (doc Document) loadReferencedDocument(ctx context.Context, documentPath, filePath string) (*Document, error) {
+	err := doc.Cfg.checkReference(documentPath, doc.Cfg.refDepth+1)
+	if err != nil {
+		return nil, err
+	}
+
+	doc.Cfg.setSandboxRoot(doc.RefDirectory)
+
+	referencedDocument := NewDocument(doc.Cfg.referencedConfig())
+	referencedDocument.ReferencedDocuments = doc.ReferencedDocuments
+	err = referencedDocument.ReadFileContext(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &referencedDocument, nil
+}
+
+// referencedDocumentPaths returns paths of documents referenced by the document, as they are written in references
+func (doc Document) referencedDocumentPaths() ([]string, error) {
+	rootObject, err := OasObjectByName(&doc, RootItem, false)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := rootObject.references()
+	if err != nil {
+		return nil, err
+	}
+
+	var documentPaths []string
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		if isLocalReference(ref.path) {
+			continue
+		}
+
+		documentPath := getDocumentPath(ref.path)
+		if !seen[documentPath] {
+			seen[documentPath] = true
+			documentPaths = append(documentPaths, documentPath)
+		}
+	}
+
+	return documentPaths, nil
+}
+
+// referencedConfig returns a config of documents referenced by the document
+func (cfg Config) referencedConfig() Config {
+	return Config{
+		InlineLocalRefs: true,
+		Substitution:    cfg.Substitution,
+		Loader:          cfg.Loader,
+		Sandbox:         cfg.Sandbox,
+		ParseCache:      cfg.ParseCache,
+		Parallelism:     cfg.Parallelism,
+		sandboxState:    cfg.sandboxState,
+		refDepth:        cfg.refDepth + 1,
+	}
+}
+
+func (cfg Config) parallelism() int {
+	if cfg.Parallelism <= 0 {
+		return DefaultParallelism
+	}
+
+	return cfg.Parallelism
+}
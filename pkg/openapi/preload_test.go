@@ -0,0 +1,142 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errLoadFailed = errors.New("load failed")
+
+// recordingLoader reads files from memory, recording loads and the highest number of loads at the same time
+type recordingLoader struct {
+	files MapLoader
+	delay time.Duration
+	fail  string
+
+	mu          sync.Mutex
+	loads       []string
+	inFlight    int
+	maxInFlight int
+}
+
+func (l *recordingLoader) Load(path string) ([]byte, error) {
+	l.mu.Lock()
+	l.loads = append(l.loads, fsPath(path))
+	l.inFlight++
+	if l.inFlight > l.maxInFlight {
+		l.maxInFlight = l.inFlight
+	}
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.inFlight--
+		l.mu.Unlock()
+	}()
+
+	time.Sleep(l.delay)
+	if fsPath(path) == l.fail {
+		return nil, errLoadFailed
+	}
+
+	return l.files.Load(path)
+}
+
+// fanOutFiles returns a root document referencing count documents, each of them referencing the shared document
+func fanOutFiles(count int) MapLoader {
+	var root strings.Builder
+	root.WriteString("openapi: 3.0.0\ncomponents:\n  schemas:\n")
+
+	files := MapLoader{
+		"shared.yaml": []byte("components:\n  schemas:\n    Shared:\n      title: shared\n"),
+	}
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("pet%d.yaml", i)
+		fmt.Fprintf(&root, "    Pet%d:\n      $ref: '%s#/components/schemas/Pet%d'\n", i, name, i)
+		files[name] = []byte(fmt.Sprintf("components:\n  schemas:\n    Pet%d:\n      title: pet\n      properties:\n        shared:\n          $ref: 'shared.yaml#/components/schemas/Shared'\n", i))
+	}
+
+	files["openapi.yaml"] = []byte(root.String())
+	return files
+}
+
+func TestLoadReferencedDocuments(t *testing.T) {
+	loader := &recordingLoader{files: fanOutFiles(6), delay: 10 * time.Millisecond}
+	doc, err := ParseDocument(Config{Loader: loader, Parallelism: 2}, "openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loader.maxInFlight > 2 {
+		t.Errorf("expected at most 2 documents loaded at the same time, got %d", loader.maxInFlight)
+	}
+
+	if len(loader.loads) != 8 {
+		t.Errorf("expected every document to be loaded once, got %v", loader.loads)
+	}
+
+	for i := 0; i < 6; i++ {
+		pet := doc.Root.Components.Schemas[fmt.Sprintf("Pet%d", i)]
+		if pet == nil || pet.Title != "pet" {
+			t.Errorf("expected Pet%d to be resolved, got %+v", i, pet)
+		}
+	}
+}
+
+func TestLoadReferencedDocumentsStopsAtFirstError(t *testing.T) {
+	loader := &recordingLoader{files: fanOutFiles(6), fail: "pet0.yaml"}
+	doc := NewDocument(Config{Loader: loader, Parallelism: 1})
+	err := doc.ReadFile("openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = doc.LoadReferencedDocuments(context.Background())
+	if !errors.Is(err, errLoadFailed) {
+		t.Fatalf("expected %v, got %v", errLoadFailed, err)
+	}
+
+	// with one load at a time, no document is loaded after the failed one
+	if last := loader.loads[len(loader.loads)-1]; last != "pet0.yaml" {
+		t.Errorf("expected loading to stop after the failed document, got %v", loader.loads)
+	}
+}
+
+func TestLoadReferencedDocumentsCanceled(t *testing.T) {
+	loader := &recordingLoader{files: fanOutFiles(2)}
+	doc := NewDocument(Config{Loader: loader})
+	err := doc.ReadFile("openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = doc.LoadReferencedDocuments(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+
+	if len(loader.loads) != 1 {
+		t.Errorf("expected no referenced documents to be loaded, got %v", loader.loads)
+	}
+}
+
+func TestCircularReferences(t *testing.T) {
+	loader := MapLoader{
+		"openapi.yaml": []byte(sandboxRoot("a.yaml#/components/schemas/Pet")),
+		"a.yaml":       []byte(sandboxRoot("b.yaml#/components/schemas/Pet")),
+		"b.yaml":       []byte(sandboxRoot("a.yaml#/components/schemas/Pet")),
+	}
+
+	_, err := ParseDocument(Config{Loader: loader}, "openapi.yaml")
+	if !errors.Is(err, ErrCircularReference) {
+		t.Errorf("expected %v, got %v", ErrCircularReference, err)
+	}
+}
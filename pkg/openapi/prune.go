@@ -0,0 +1,188 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+const (
+	// PathsItem is a name of the OpenAPI Root field holding paths
+	PathsItem = "Paths"
+	// WebhooksItem is a name of the OpenAPI Root field holding webhooks
+	WebhooksItem = "Webhooks"
+	// securitySchemesItem is a key of components holding security schemes, which are referenced by name in security requirements instead of $refs
+	securitySchemesItem = "securitySchemes"
+)
+
+// PruneComponents removes components that are not reachable from paths, webhooks or security requirements of the document, either directly or through other components.
+// Returned list holds local references to removed components, sorted. When dryRun is true, the document is left unchanged and the list only reports what would be removed.
+// Should be run on a document with resolved references, since remote references are not followed.
+func (doc Document) PruneComponents(dryRun bool) ([]string, error) {
+	var pruned []string
+
+	if doc.Root.Components == nil {
+		return pruned, nil
+	}
+
+	reachable, err := doc.reachableComponents()
+	if err != nil {
+		return pruned, err
+	}
+
+	components := reflect.ValueOf(doc.Root.Components).Elem()
+	componentsType := components.Type()
+	for i := 0; i < components.NumField(); i++ {
+		componentsOfType := components.Field(i)
+		if componentsOfType.Kind() != reflect.Map || componentsType.Field(i).Name == ExtensionsField {
+			continue
+		}
+
+		componentType := getYamlKeyFromField(componentsType.Field(i))
+		for _, key := range componentsOfType.MapKeys() {
+			ref := componentReference(componentType, key.String())
+			if reachable[ref] {
+				continue
+			}
+
+			pruned = append(pruned, ref)
+			if dryRun {
+				continue
+			}
+
+			componentsOfType.SetMapIndex(key, reflect.Value{})
+		}
+	}
+
+	sort.Strings(pruned)
+	return pruned, nil
+}
+
+// reachableComponents walks references starting from paths, webhooks and security requirements, returning local references of all components that can be reached.
+func (doc Document) reachableComponents() (map[string]bool, error) {
+	reachable := make(map[string]bool)
+
+	pending, err := doc.rootReferences(PathsItem, WebhooksItem)
+	if err != nil {
+		return reachable, err
+	}
+
+	pending = append(pending, doc.securitySchemeReferences()...)
+
+	for len(pending) > 0 {
+		refPath := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if !isLocalReference(refPath) {
+			continue
+		}
+
+		items := referencePathToItems(refPath)
+		if len(items) < 3 || items[0] != ComponentsItem {
+			continue
+		}
+
+		// a reference can point to an object nested in a component, which still requires the whole component to be kept
+		componentRef := componentReference(items[1], items[2])
+		if reachable[componentRef] {
+			continue
+		}
+		reachable[componentRef] = true
+
+		if !doc.componentExists(items[1], items[2]) {
+			continue
+		}
+
+		object, err := doc.getOrCreateObjectByPath(componentRef, false)
+		if err != nil {
+			return reachable, fmt.Errorf("could not find referenced component %s: %w", componentRef, err)
+		}
+
+		refs, err := object.references()
+		if err != nil {
+			return reachable, err
+		}
+
+		for _, ref := range refs {
+			pending = append(pending, ref.path)
+		}
+	}
+
+	return reachable, nil
+}
+
+// rootReferences returns paths of all references found in the specified fields of the OpenAPI Root
+func (doc Document) rootReferences(fieldNames ...string) ([]string, error) {
+	var refPaths []string
+
+	for _, fieldName := range fieldNames {
+		object, err := OasObjectByName(doc.Root, fieldName, false)
+		if isNotExisitngObject(err) {
+			continue
+		} else if err != nil {
+			return refPaths, err
+		}
+
+		refs, err := object.references()
+		if err != nil {
+			return refPaths, err
+		}
+
+		for _, ref := range refs {
+			refPaths = append(refPaths, ref.path)
+		}
+	}
+
+	return refPaths, nil
+}
+
+// securitySchemeReferences returns local references to security schemes used by the document-wide and operation security requirements.
+func (doc Document) securitySchemeReferences() []string {
+	var refPaths []string
+
+	addRequirement := func(requirement SecurityRequirement) {
+		for name := range requirement {
+			refPaths = append(refPaths, componentReference(securitySchemesItem, name))
+		}
+	}
+
+	for _, requirement := range doc.Root.Security {
+		addRequirement(requirement)
+	}
+
+	for _, pathItems := range []map[string]*PathItem{doc.Root.Paths, doc.Root.Webhooks} {
+		for _, pathItem := range pathItems {
+			if pathItem == nil {
+				continue
+			}
+
+			for _, operation := range pathItem.Operations() {
+				if operation.Security != nil {
+					addRequirement(*operation.Security)
+				}
+			}
+		}
+	}
+
+	return refPaths
+}
+
+// componentExists checks whether the document has a not empty component of provided type and name
+func (doc Document) componentExists(componentType, name string) bool {
+	if doc.Root.Components == nil {
+		return false
+	}
+
+	components := reflect.ValueOf(doc.Root.Components).Elem()
+	fieldName, err := getFieldNameByTag(componentType, components)
+	if err != nil {
+		return false
+	}
+
+	component := components.FieldByName(fieldName).MapIndex(reflect.ValueOf(name))
+	return component.IsValid() && !component.IsNil()
+}
+
+func componentReference(componentType, name string) string {
+	return fmt.Sprintf("%c%s", referenceSeparator, itemsToPointer(ComponentsItem, componentType, name))
+}
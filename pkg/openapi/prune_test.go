@@ -0,0 +1,167 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+const pruneSpec = `openapi: 3.1.0
+info:
+  title: pets
+  version: "1"
+security:
+- apiKey: []
+paths:
+  /pets:
+    get:
+      parameters:
+      - $ref: '#/components/parameters/Limit'
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pets'
+webhooks:
+  adopted:
+    post:
+      requestBody:
+        $ref: '#/components/requestBodies/Adoption'
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    Pets:
+      type: array
+      items:
+        $ref: '#/components/schemas/Pet'
+    Pet:
+      type: object
+      properties:
+        name:
+          $ref: '#/components/schemas/Owner/properties/name'
+    Owner:
+      type: object
+      properties:
+        name:
+          type: string
+    Unused:
+      type: object
+      properties:
+        pet:
+          $ref: '#/components/schemas/Pet'
+    UnusedLeaf:
+      type: string
+  parameters:
+    Limit:
+      name: limit
+      in: query
+    Offset:
+      name: offset
+      in: query
+  requestBodies:
+    Adoption:
+      description: adoption
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      name: key
+      in: header
+    oauth:
+      type: oauth2
+    basic:
+      type: http
+      scheme: basic
+`
+
+func TestPruneComponents(t *testing.T) {
+	expectedPruned := []string{
+		"#/components/parameters/Offset",
+		"#/components/schemas/Unused",
+		"#/components/schemas/UnusedLeaf",
+		"#/components/securitySchemes/basic",
+		"#/components/securitySchemes/oauth",
+	}
+
+	doc := parseYAML(t, pruneSpec)
+	pruned, err := doc.PruneComponents(true)
+	if err != nil {
+		t.Fatalf("dry run: unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(pruned, expectedPruned) {
+		t.Errorf("dry run: expected %v, got %v", expectedPruned, pruned)
+	}
+
+	if _, ok := doc.Root.Components.Schemas["Unused"]; !ok {
+		t.Errorf("dry run: expected the document to be left unchanged")
+	}
+
+	pruned, err = doc.PruneComponents(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(pruned, expectedPruned) {
+		t.Errorf("expected %v, got %v", expectedPruned, pruned)
+	}
+
+	components := doc.Root.Components
+	for _, name := range []string{"Pets", "Pet", "Owner"} {
+		if _, ok := components.Schemas[name]; !ok {
+			t.Errorf("expected reachable schema %s to be kept", name)
+		}
+	}
+	for _, name := range []string{"Unused", "UnusedLeaf"} {
+		if _, ok := components.Schemas[name]; ok {
+			t.Errorf("expected unreachable schema %s to be removed", name)
+		}
+	}
+	if _, ok := components.Parameters["Limit"]; !ok {
+		t.Errorf("expected parameter referenced by an operation to be kept")
+	}
+	if _, ok := components.RequestBodies["Adoption"]; !ok {
+		t.Errorf("expected request body referenced by a webhook to be kept")
+	}
+	if _, ok := components.SecuritySchemes["apiKey"]; !ok {
+		t.Errorf("expected security scheme used by security requirements to be kept")
+	}
+}
+
+func TestPruneComponentsConfig(t *testing.T) {
+	doc := parseFiles(t, Config{PruneComponents: true}, map[string]string{
+		"openapi.yaml": `openapi: 3.0.0
+info:
+  title: pets
+  version: "1"
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: 'common.yaml#/components/schemas/Pet'
+components:
+  schemas:
+    Local:
+      type: string
+`,
+		"common.yaml": `components:
+  schemas:
+    Pet:
+      type: string
+    Unused:
+      type: string
+`,
+	}, "openapi.yaml")
+
+	schemas := doc.Root.Components.Schemas
+	if _, ok := schemas["Pet"]; !ok || len(schemas) != 1 {
+		t.Errorf("expected only the referenced remote schema to be kept, got %v", schemas)
+	}
+}
@@ -0,0 +1,263 @@
+package openapi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// rawDocument holds the content of a single source file in a generic, order preserving form.
+// Contrary to the Document, the rawDocument keeps every key of the source file - even the ones unknown to the typed representation - which makes it suitable for changes that are written back to the source files.
+type rawDocument struct {
+	path    string
+	content yaml.MapSlice
+	changed bool
+}
+
+// rawLocation describes an absolute location of an object: the absolute path of the file and the path to the object inside of the file.
+type rawLocation struct {
+	file    string
+	pointer string
+}
+
+// rawRefVisitor is called for every $ref found in a raw content. The path holds keys (and indexes) leading to the object containing $ref.
+// When returned bool is true, the $ref value is replaced with the returned string.
+type rawRefVisitor func(path []string, ref string) (string, bool)
+
+func readRawDocument(path string) (*rawDocument, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &rawDocument{
+		path: path,
+	}
+
+	err = yaml.Unmarshal(data, &doc.content)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse file %s: %w", path, err)
+	}
+
+	return doc, nil
+}
+
+// readRawDocuments reads the root file and all files that are referenced from it, directly or by other referenced files.
+// Documents referenced by URLs are not read, since they can not be changed.
+// Returned map is keyed by clean absolute paths of the files.
+func readRawDocuments(rootPath string) (map[string]*rawDocument, error) {
+	rootPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make(map[string]*rawDocument)
+	pending := []string{rootPath}
+	for len(pending) > 0 {
+		path := pending[0]
+		pending = pending[1:]
+
+		if _, ok := documents[path]; ok {
+			continue
+		}
+
+		doc, err := readRawDocument(path)
+		if err != nil {
+			return nil, err
+		}
+
+		documents[path] = doc
+		doc.walkRefs(func(_ []string, ref string) (string, bool) {
+			location := doc.locate(ref)
+			if _, ok := documents[location.file]; !ok && !isURL(location.file) {
+				pending = append(pending, location.file)
+			}
+
+			return ref, false
+		})
+	}
+
+	return documents, nil
+}
+
+// write stores the content of the document under its path, keeping the permissions of the file when it already exists and creating missing directories otherwise.
+func (doc *rawDocument) write() error {
+	data, err := yaml.Marshal(doc.content)
+	if err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(doc.path); err == nil {
+		mode = info.Mode()
+	}
+
+	err = os.MkdirAll(filepath.Dir(doc.path), os.FileMode(0755))
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(doc.path, data, mode)
+}
+
+// locate converts a $ref found in the document to the absolute location.
+func (doc *rawDocument) locate(ref string) rawLocation {
+	documentPath, pointer := splitReference(ref)
+	if documentPath == "" {
+		return rawLocation{file: doc.path, pointer: pointer}
+	}
+
+	if isURL(documentPath) {
+		return rawLocation{file: documentPath, pointer: pointer}
+	}
+
+	return rawLocation{
+		file:    filepath.Clean(filepath.Join(filepath.Dir(doc.path), filepath.FromSlash(documentPath))),
+		pointer: pointer,
+	}
+}
+
+// refTo creates a $ref pointing to the provided location, relative to the document.
+func (doc *rawDocument) refTo(location rawLocation) (string, error) {
+	if location.file == doc.path {
+		return fmt.Sprintf("%c%s", referenceSeparator, location.pointer), nil
+	}
+
+	relativePath, err := filepath.Rel(filepath.Dir(doc.path), location.file)
+	if err != nil {
+		return "", err
+	}
+
+	if location.pointer == "" {
+		return filepath.ToSlash(relativePath), nil
+	}
+
+	return fmt.Sprintf("%s%c%s", filepath.ToSlash(relativePath), referenceSeparator, location.pointer), nil
+}
+
+func (doc *rawDocument) walkRefs(visit rawRefVisitor) {
+	if walkRawRefs(doc.content, nil, visit) {
+		doc.changed = true
+	}
+}
+
+// walkRawRefs visits all $refs in the node and its descendants. Returns true when any of $refs has been changed by the visitor.
+func walkRawRefs(node interface{}, path []string, visit rawRefVisitor) bool {
+	changed := false
+
+	switch value := node.(type) {
+	case yaml.MapSlice:
+		for idx, item := range value {
+			key := fmt.Sprint(item.Key)
+			if ref, ok := item.Value.(string); ok && key == RefTag {
+				newRef, replace := visit(path, ref)
+				if replace && newRef != ref {
+					value[idx].Value = newRef
+					changed = true
+				}
+
+				continue
+			}
+
+			if walkRawRefs(item.Value, append(path[:len(path):len(path)], key), visit) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for idx, item := range value {
+			if walkRawRefs(item, append(path[:len(path):len(path)], fmt.Sprint(idx)), visit) {
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
+
+// rawNodeByPointer returns a node found under the pointer, or nil when the node does not exist.
+func rawNodeByPointer(node interface{}, pointer string) interface{} {
+	for _, item := range pointerToItems(pointer) {
+		mapSlice, ok := node.(yaml.MapSlice)
+		if !ok {
+			return nil
+		}
+
+		node, ok = mapSliceGet(mapSlice, item)
+		if !ok {
+			return nil
+		}
+	}
+
+	return node
+}
+
+func mapSliceGet(mapSlice yaml.MapSlice, key string) (interface{}, bool) {
+	for _, item := range mapSlice {
+		if fmt.Sprint(item.Key) == key {
+			return item.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// mapSliceSet replaces the value under the key or appends it when key is not present.
+func mapSliceSet(mapSlice yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	for idx, item := range mapSlice {
+		if fmt.Sprint(item.Key) == key {
+			mapSlice[idx].Value = value
+			return mapSlice
+		}
+	}
+
+	return append(mapSlice, yaml.MapItem{Key: key, Value: value})
+}
+
+func mapSliceDelete(mapSlice yaml.MapSlice, key string) yaml.MapSlice {
+	for idx, item := range mapSlice {
+		if fmt.Sprint(item.Key) == key {
+			return append(mapSlice[:idx:idx], mapSlice[idx+1:]...)
+		}
+	}
+
+	return mapSlice
+}
+
+// setRawNode sets the value under the path described by items, creating missing maps on the way.
+// A nil value removes the node, along with maps that become empty due to the removal.
+func setRawNode(node yaml.MapSlice, items []string, value interface{}) yaml.MapSlice {
+	if len(items) == 1 {
+		if value == nil {
+			return mapSliceDelete(node, items[0])
+		}
+
+		return mapSliceSet(node, items[0], value)
+	}
+
+	child, _ := mapSliceGet(node, items[0])
+	childMap, _ := child.(yaml.MapSlice)
+	childMap = setRawNode(childMap, items[1:], value)
+	if value == nil && len(childMap) == 0 {
+		return mapSliceDelete(node, items[0])
+	}
+
+	return mapSliceSet(node, items[0], childMap)
+}
+
+// renameRawNode changes the key of the node under the path described by items, keeping its position among its siblings
+func renameRawNode(node yaml.MapSlice, items []string, key string) {
+	for _, item := range items[:len(items)-1] {
+		child, _ := mapSliceGet(node, item)
+		node, _ = child.(yaml.MapSlice)
+	}
+
+	for idx, item := range node {
+		if fmt.Sprint(item.Key) == items[len(items)-1] {
+			node[idx].Key = key
+			return
+		}
+	}
+}
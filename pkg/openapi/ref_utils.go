@@ -2,6 +2,8 @@ package openapi
 
 import (
 	"fmt"
+	"net/url"
+	"path/filepath"
 	"strings"
 )
 
@@ -10,6 +12,67 @@ const (
 	pathSeparator      = "/"
 )
 
+// isURL checks whether the document path is an HTTP(S) URL rather than a file path
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// documentDirectory returns a directory against which references of the document are resolved. For URLs it is the URL of the "directory", ending with a slash
+func documentDirectory(path string) string {
+	if !isURL(path) {
+		return filepath.Dir(path)
+	}
+
+	documentURL, err := url.Parse(path)
+	if err != nil {
+		return path
+	}
+
+	return documentURL.ResolveReference(&url.URL{Path: "./"}).String()
+}
+
+// documentBase returns a name of the document file, without the directory
+func documentBase(path string) string {
+	if !isURL(path) {
+		return filepath.Base(path)
+	}
+
+	documentURL, err := url.Parse(path)
+	if err != nil {
+		return path
+	}
+
+	return documentURL.Path[strings.LastIndex(documentURL.Path, pathSeparator)+1:]
+}
+
+// joinDocumentPath resolves the path of a referenced document against the directory of the referencing document.
+// URLs are kept as they are, while relative paths referenced from a document fetched from an URL are resolved against that URL.
+func joinDocumentPath(directory, documentPath string) string {
+	if isURL(documentPath) {
+		return documentPath
+	}
+
+	if !isURL(directory) {
+		return filepath.Join(directory, documentPath)
+	}
+
+	directoryURL, err := url.Parse(directory)
+	if err != nil {
+		return directory + documentPath
+	}
+
+	if !strings.HasSuffix(directoryURL.Path, pathSeparator) {
+		directoryURL.Path += pathSeparator
+	}
+
+	documentURL, err := url.Parse(filepath.ToSlash(documentPath))
+	if err != nil {
+		return directory + documentPath
+	}
+
+	return directoryURL.ResolveReference(documentURL).String()
+}
+
 func isLocalReference(path string) bool {
 	return strings.IndexRune(path, referenceSeparator) == 0
 }
@@ -49,3 +112,47 @@ func sortReferences(refI, refJ reference) bool {
 
 	return false
 }
+
+func escapePointerToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+func unescapePointerToken(token string) string {
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+}
+
+// splitReference returns document path and a path to the object inside of the document (including the leading slash).
+// For a local reference the document path is empty, for a reference to the whole document the object path is empty.
+func splitReference(ref string) (string, string) {
+	parts := strings.SplitN(ref, string(referenceSeparator), 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+
+	return parts[0], parts[1]
+}
+
+// pointerToItems splits path to the object inside of the document into unescaped items.
+func pointerToItems(pointer string) []string {
+	if pointer == "" {
+		return nil
+	}
+
+	items := strings.Split(pointer, pathSeparator)[1:]
+	for idx, item := range items {
+		items[idx] = unescapePointerToken(item)
+	}
+
+	return items
+}
+
+// itemsToPointer is a reverse of pointerToItems.
+func itemsToPointer(items ...string) string {
+	var pointer strings.Builder
+	for _, item := range items {
+		pointer.WriteString(pathSeparator)
+		pointer.WriteString(escapePointerToken(item))
+	}
+
+	return pointer.String()
+}
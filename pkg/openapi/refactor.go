@@ -0,0 +1,210 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// ComponentsItem is a name of the OpenAPI Root item holding reusable objects
+	ComponentsItem = "components"
+)
+
+var (
+	// ErrNotAComponent occurs when a reference passed to a refactoring does not point directly to a component, eg. #/components/schemas/Name
+	ErrNotAComponent = errors.New("reference does not point to a component")
+	// ErrComponentNotFound occurs when a component to be refactored is not present in the specification
+	ErrComponentNotFound = errors.New("component could not be found")
+	// ErrComponentExists occurs when a component cannot be renamed or moved due to a component already existing in the target location
+	ErrComponentExists = errors.New("component already exists")
+	// ErrComponentTypeMismatch occurs when a component would be moved between different component types, eg. from schemas to responses
+	ErrComponentTypeMismatch = errors.New("component cannot change its type")
+)
+
+// Refactoring holds all source files of a specification - the root file and every file referenced by it - in their raw form.
+// Changes made by a refactoring are applied to every source file and can be written back without losing content not handled by the Document.
+type Refactoring struct {
+	RootPath  string
+	documents map[string]*rawDocument
+}
+
+// NewRefactoring reads the root file and all the files that are referenced from it
+func NewRefactoring(rootPath string) (*Refactoring, error) {
+	rootPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := readRawDocuments(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Refactoring{
+		RootPath:  rootPath,
+		documents: documents,
+	}, nil
+}
+
+// RenameComponent renames or moves the component pointed by "from" reference to the location pointed by "to" reference, eg. from "#/components/schemas/UserDTO" to "#/components/schemas/User" or to "users.yaml#/components/schemas/User".
+// Both references are relative to the root file. When the target file is not part of the specification yet, it is created.
+// Every $ref pointing to the component or any of its descendants is rewritten in all source files, as are the $refs inside of moved component.
+func (r *Refactoring) RenameComponent(from, to string) error {
+	root := r.documents[r.RootPath]
+	source := root.locate(from)
+	target := root.locate(to)
+
+	sourceItems, err := componentItems(source.pointer)
+	if err != nil {
+		return fmt.Errorf("could not rename %s: %w", from, err)
+	}
+
+	targetItems, err := componentItems(target.pointer)
+	if err != nil {
+		return fmt.Errorf("could not rename to %s: %w", to, err)
+	}
+
+	if sourceItems[1] != targetItems[1] {
+		return fmt.Errorf("could not rename %s to %s: %w", from, to, ErrComponentTypeMismatch)
+	}
+
+	sourceDocument, ok := r.documents[source.file]
+	if !ok {
+		return fmt.Errorf("could not rename %s: %w", from, ErrComponentNotFound)
+	}
+
+	component := rawNodeByPointer(sourceDocument.content, source.pointer)
+	if component == nil {
+		return fmt.Errorf("could not rename %s: %w", from, ErrComponentNotFound)
+	}
+
+	targetDocument, err := r.documentOrNew(target.file)
+	if err != nil {
+		return err
+	}
+
+	if rawNodeByPointer(targetDocument.content, target.pointer) != nil {
+		return fmt.Errorf("could not rename %s to %s: %w", from, to, ErrComponentExists)
+	}
+
+	renamed := func(location rawLocation) (rawLocation, bool) {
+		if location.file != source.file {
+			return location, false
+		}
+
+		if location.pointer != source.pointer && !strings.HasPrefix(location.pointer, source.pointer+pathSeparator) {
+			return location, false
+		}
+
+		return rawLocation{
+			file:    target.file,
+			pointer: target.pointer + strings.TrimPrefix(location.pointer, source.pointer),
+		}, true
+	}
+
+	// a component moved to another file is detached first, since refs inside of it are relative to the source file and need to be made relative to the target one.
+	// A component renamed within its file stays in place, so its key keeps its position
+	sameFile := source.file == target.file
+	if !sameFile {
+		sourceDocument.content = setRawNode(sourceDocument.content, sourceItems, nil)
+		sourceDocument.changed = true
+	}
+
+	var rewriteErr error
+	rewrite := func(holder, destination *rawDocument) rawRefVisitor {
+		return func(_ []string, ref string) (string, bool) {
+			location, changed := renamed(holder.locate(ref))
+			if !changed && holder == destination {
+				return ref, false
+			}
+
+			newRef, err := destination.refTo(location)
+			if err != nil {
+				rewriteErr = err
+				return ref, false
+			}
+
+			return newRef, true
+		}
+	}
+
+	for _, document := range r.documents {
+		document.walkRefs(rewrite(document, document))
+	}
+	if !sameFile {
+		walkRawRefs(component, nil, rewrite(sourceDocument, targetDocument))
+	}
+	if rewriteErr != nil {
+		return rewriteErr
+	}
+
+	if sameFile {
+		renameRawNode(sourceDocument.content, sourceItems, targetItems[len(targetItems)-1])
+		sourceDocument.changed = true
+		return nil
+	}
+
+	targetDocument.content = setRawNode(targetDocument.content, targetItems, component)
+	targetDocument.changed = true
+	r.documents[target.file] = targetDocument
+
+	return nil
+}
+
+// ChangedFiles returns sorted paths of files that were changed by the refactoring
+func (r *Refactoring) ChangedFiles() []string {
+	var paths []string
+	for path, document := range r.documents {
+		if document.changed {
+			paths = append(paths, path)
+		}
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// WriteFiles writes back all files that were changed by the refactoring.
+// Changed files are written by a YAML encoder, so their keys keep their order, but comments, quoting and flow style are not preserved. Files that were not changed are left untouched.
+func (r *Refactoring) WriteFiles() error {
+	for _, path := range r.ChangedFiles() {
+		err := r.documents[path].write()
+		if err != nil {
+			return fmt.Errorf("could not write file %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// documentOrNew returns a document that is part of the specification, or prepares a new one for the file not referenced yet.
+func (r *Refactoring) documentOrNew(path string) (*rawDocument, error) {
+	if document, ok := r.documents[path]; ok {
+		return document, nil
+	}
+
+	document, err := readRawDocument(path)
+	if err == nil {
+		return document, nil
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return &rawDocument{path: path}, nil
+}
+
+// componentItems validates that the pointer leads directly to a component and returns it's items.
+func componentItems(pointer string) ([]string, error) {
+	items := pointerToItems(pointer)
+	if len(items) != 3 || items[0] != ComponentsItem {
+		return nil, ErrNotAComponent
+	}
+
+	return items, nil
+}
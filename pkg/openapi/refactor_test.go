@@ -0,0 +1,203 @@
+package openapi
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	refactorRoot = `openapi: 3.0.0
+info:
+  title: orders
+  version: "1"
+paths:
+  /users:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/UserDTO'
+components:
+  schemas:
+    Address:
+      type: string
+    UserDTO:
+      type: object
+      properties:
+        address:
+          $ref: '#/components/schemas/Address'
+        group:
+          $ref: common.yaml#/components/schemas/Group
+    Zone:
+      type: string
+`
+	refactorCommon = `components:
+  schemas:
+    Group:
+      type: object
+      properties:
+        owner:
+          $ref: openapi.yaml#/components/schemas/UserDTO
+        ownerName:
+          $ref: openapi.yaml#/components/schemas/UserDTO/properties/address
+`
+)
+
+func TestRenameComponentInPlace(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"openapi.yaml": refactorRoot,
+		"common.yaml":  refactorCommon,
+	})
+
+	refactoring, err := NewRefactoring(filepath.Join(dir, "openapi.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = refactoring.RenameComponent("#/components/schemas/UserDTO", "#/components/schemas/User")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = refactoring.WriteFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertFileContent(t, filepath.Join(dir, "openapi.yaml"), `openapi: 3.0.0
+info:
+  title: orders
+  version: "1"
+paths:
+  /users:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+components:
+  schemas:
+    Address:
+      type: string
+    User:
+      type: object
+      properties:
+        address:
+          $ref: '#/components/schemas/Address'
+        group:
+          $ref: common.yaml#/components/schemas/Group
+    Zone:
+      type: string
+`)
+	assertFileContent(t, filepath.Join(dir, "common.yaml"), `components:
+  schemas:
+    Group:
+      type: object
+      properties:
+        owner:
+          $ref: openapi.yaml#/components/schemas/User
+        ownerName:
+          $ref: openapi.yaml#/components/schemas/User/properties/address
+`)
+}
+
+func TestMoveComponentToAnotherFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"openapi.yaml": refactorRoot,
+		"common.yaml":  refactorCommon,
+	})
+
+	refactoring, err := NewRefactoring(filepath.Join(dir, "openapi.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = refactoring.RenameComponent("#/components/schemas/UserDTO", "users/users.yaml#/components/schemas/User")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedFiles := []string{
+		filepath.Join(dir, "common.yaml"),
+		filepath.Join(dir, "openapi.yaml"),
+		filepath.Join(dir, "users", "users.yaml"),
+	}
+	changedFiles := refactoring.ChangedFiles()
+	if len(changedFiles) != len(expectedFiles) {
+		t.Fatalf("expected changed files %v, got %v", expectedFiles, changedFiles)
+	}
+	for idx := range expectedFiles {
+		if changedFiles[idx] != expectedFiles[idx] {
+			t.Fatalf("expected changed files %v, got %v", expectedFiles, changedFiles)
+		}
+	}
+
+	err = refactoring.WriteFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertFileContent(t, filepath.Join(dir, "users", "users.yaml"), `components:
+  schemas:
+    User:
+      type: object
+      properties:
+        address:
+          $ref: ../openapi.yaml#/components/schemas/Address
+        group:
+          $ref: ../common.yaml#/components/schemas/Group
+`)
+	assertFileContent(t, filepath.Join(dir, "common.yaml"), `components:
+  schemas:
+    Group:
+      type: object
+      properties:
+        owner:
+          $ref: users/users.yaml#/components/schemas/User
+        ownerName:
+          $ref: users/users.yaml#/components/schemas/User/properties/address
+`)
+
+	root := readFile(t, filepath.Join(dir, "openapi.yaml"))
+	if !containsLine(root, "                $ref: users/users.yaml#/components/schemas/User") || containsLine(root, "    UserDTO:") {
+		t.Errorf("expected the root file to reference the moved component, got:\n%s", root)
+	}
+}
+
+func TestRenameComponentErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"openapi.yaml": refactorRoot,
+		"common.yaml":  refactorCommon,
+	})
+
+	refactoring, err := NewRefactoring(filepath.Join(dir, "openapi.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		from, to string
+		expected error
+	}{
+		{"#/components/schemas/Missing", "#/components/schemas/Other", ErrComponentNotFound},
+		{"#/components/schemas/UserDTO", "#/components/schemas/Zone", ErrComponentExists},
+		{"#/components/schemas/UserDTO", "#/components/responses/User", ErrComponentTypeMismatch},
+		{"#/paths/~1users", "#/components/schemas/Users", ErrNotAComponent},
+	} {
+		err := refactoring.RenameComponent(tc.from, tc.to)
+		if !errors.Is(err, tc.expected) {
+			t.Errorf("%s -> %s: expected %v, got %v", tc.from, tc.to, tc.expected, err)
+		}
+	}
+
+	if changed := refactoring.ChangedFiles(); len(changed) != 0 {
+		t.Errorf("expected failed renames not to change files, got %v", changed)
+	}
+}
@@ -0,0 +1,190 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// ErrReloadUnsupported occurs when a document that does not track its resolved references is reloaded, eg. a document not constructed by NewDocument
+	ErrReloadUnsupported = errors.New("document does not track resolved references")
+)
+
+// resolution is a reference of the root document resolved to an object, which is set to the target
+type resolution struct {
+	target OasObject
+	ref    string
+	// source is the canonical path of the document the object came from, empty for local references
+	source   string
+	instance interface{}
+	// removed is set when the target was removed after it was inlined, and therefore it is removed again after it is resolved again
+	removed bool
+}
+
+// resolutionLog holds resolutions in the order they were made, so remote references are resolved again before local references aliasing them
+type resolutionLog struct {
+	resolutions []resolution
+	// pending are canonical paths of documents of a reload that failed, so their references are resolved again by the next reload
+	pending map[string]bool
+	// rootPending is set when reading the root document again failed, so the next reload reads it again
+	rootPending bool
+}
+
+func (l *resolutionLog) add(r resolution) {
+	if l == nil {
+		return
+	}
+
+	l.resolutions = append(l.resolutions, r)
+}
+
+// Sources returns canonical paths of files the document was read from and depends on, directly or not, eg. to be watched for changes
+func (doc Document) Sources() []string {
+	var sources []string
+	for _, source := range doc.ReferencedDocuments.dependenciesOf(doc.path()) {
+		if doc.FileName == "" && source == canonicalDocumentPath(doc.path()) {
+			continue
+		}
+
+		sources = append(sources, source)
+	}
+
+	return sources
+}
+
+// Reload updates the document after the files changed, eg. in an editor or watch mode, without resolving the whole tree again.
+// Changed files and documents depending on them are loaded and resolved again, and only references of the document to them are replaced - other documents stay cached.
+// When the file of the document itself changed, the document is read again and its references are resolved against cached documents.
+// With ParseCache, documents depending on changed files are not parsed again either.
+func (doc Document) Reload(changedPaths ...string) error {
+	return doc.ReloadContext(context.Background(), changedPaths...)
+}
+
+// ReloadContext reloads the document as Reload does, stopping when the context is canceled or its deadline is exceeded
+func (doc Document) ReloadContext(ctx context.Context, changedPaths ...string) error {
+	if doc.resolutions == nil {
+		return ErrReloadUnsupported
+	}
+
+	affected := doc.ReferencedDocuments.invalidate(changedPaths...)
+	for source := range doc.resolutions.pending {
+		affected[source] = true
+	}
+
+	rootPath := canonicalDocumentPath(doc.path())
+	reloadRoot := doc.FileName != "" && (doc.resolutions.rootPending || doc.changed(rootPath, changedPaths))
+
+	var err error
+	if reloadRoot {
+		err = doc.reloadRoot(ctx)
+	} else {
+		err = doc.resolveAgain(ctx, affected)
+	}
+
+	doc.resolutions.pending = nil
+	doc.resolutions.rootPending = false
+	if err != nil {
+		doc.resolutions.pending = affected
+		doc.resolutions.rootPending = reloadRoot
+		return err
+	}
+
+	if doc.Cfg.PruneComponents {
+		_, err = doc.PruneComponents(false)
+	}
+
+	return err
+}
+
+// reloadRoot reads the document again and resolves all of its references against cached documents
+func (doc Document) reloadRoot(ctx context.Context) error {
+	data, err := doc.Cfg.load(ctx, doc.path())
+	if err != nil {
+		return err
+	}
+
+	parsed := Document{Cfg: doc.Cfg, Root: &OpenAPI{}}
+	err = parsed.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	*doc.Root = *parsed.Root
+	doc.resolutions.resolutions = nil
+	doc.ReferencedDocuments.removeDependencies(doc.path())
+
+	return doc.ResolveReferencesContext(ctx)
+}
+
+// resolveAgain replaces objects resolved from affected documents with objects of their reloaded versions, along with objects of local references aliasing them
+func (doc Document) resolveAgain(ctx context.Context, affected map[string]bool) error {
+	replaced := make(map[interface{}]interface{})
+	for idx := range doc.resolutions.resolutions {
+		err := ctx.Err()
+		if err != nil {
+			return err
+		}
+
+		r := &doc.resolutions.resolutions[idx]
+		var instance interface{}
+		switch {
+		case r.removed:
+			err := r.target.Unset()
+			if err != nil {
+				return err
+			}
+
+			continue
+		case r.source != "" && affected[r.source]:
+			referencedDocument, err := doc.getReferencedDocument(ctx, r.ref)
+			if err != nil {
+				return fmt.Errorf("could not get reference document: %w", err)
+			}
+
+			refObject, err := referencedDocument.getOrCreateObjectByPath(r.ref, false)
+			if err != nil {
+				return err
+			}
+
+			instance = refObject.instance
+		case r.source == "" && isPointer(r.instance):
+			var ok bool
+			if instance, ok = replaced[r.instance]; !ok {
+				continue
+			}
+		default:
+			continue
+		}
+
+		err = r.target.Set(instance)
+		if err != nil {
+			return err
+		}
+
+		if isPointer(r.instance) {
+			replaced[r.instance] = instance
+		}
+
+		r.instance = instance
+	}
+
+	return nil
+}
+
+// changed checks whether any of the changed paths points to the document with the canonical path
+func (doc Document) changed(canonicalPath string, changedPaths []string) bool {
+	for _, changedPath := range changedPaths {
+		if canonicalDocumentPath(changedPath) == canonicalPath {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isPointer checks whether the object is a pointer, which can be compared and used as a key of a map, unlike maps and slices
+func isPointer(instance interface{}) bool {
+	return instance != nil && reflect.TypeOf(instance).Kind() == reflect.Ptr
+}
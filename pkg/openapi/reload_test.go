@@ -0,0 +1,201 @@
+package openapi
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var reloadFiles = map[string]string{
+	"openapi.yaml": `openapi: 3.0.0
+info:
+  title: Pets
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: pets
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+  /owners:
+    get:
+      responses:
+        "200":
+          description: owners
+          content:
+            application/json:
+              schema:
+                $ref: 'owner.yaml#/components/schemas/Owner'
+components:
+  schemas:
+    Pet:
+      $ref: 'pets/pet.yaml#/components/schemas/Pet'
+`,
+	"pets/pet.yaml": `components:
+  schemas:
+    Pet:
+      title: pet
+      properties:
+        tag:
+          $ref: 'tag.yaml#/components/schemas/Tag'
+`,
+	"pets/tag.yaml": "components:\n  schemas:\n    Tag:\n      title: tag\n",
+	"owner.yaml":    "components:\n  schemas:\n    Owner:\n      title: owner\n",
+}
+
+// rewriteFile replaces the content of the file, failing the test when it cannot be written
+func rewriteFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	err := ioutil.WriteFile(path, []byte(content), os.FileMode(0644))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// assertReloaded checks that the reloaded document equals the document parsed again from scratch
+func assertReloaded(t *testing.T, cfg Config, doc Document, root string) {
+	t.Helper()
+
+	parsed, err := ParseDocument(cfg, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reloaded, expected := documentYAML(t, doc), documentYAML(t, parsed); reloaded != expected {
+		t.Errorf("expected the reloaded document to equal the parsed one:\n%s\ngot:\n%s", expected, reloaded)
+	}
+}
+
+func TestReload(t *testing.T) {
+	tests := []struct {
+		name    string
+		changed string
+		content string
+	}{
+		{
+			name:    "referenced file",
+			changed: "owner.yaml",
+			content: "components:\n  schemas:\n    Owner:\n      title: changed owner\n",
+		},
+		{
+			name:    "file referenced by a component",
+			changed: "pets/pet.yaml",
+			content: "components:\n  schemas:\n    Pet:\n      title: changed pet\n",
+		},
+		{
+			name:    "root file",
+			changed: "openapi.yaml",
+			content: strings.Replace(reloadFiles["openapi.yaml"], "title: Pets", "title: changed pets", 1),
+		},
+	}
+
+	configs := map[string]Config{
+		"local refs kept":     {},
+		"local refs inlined":  {InlineLocalRefs: true},
+		"remote refs inlined": {InlineRemoteRefs: true},
+	}
+
+	for _, tt := range tests {
+		for cfgName, cfg := range configs {
+			t.Run(tt.name+", "+cfgName, func(t *testing.T) {
+				dir := writeFiles(t, reloadFiles)
+				root := filepath.Join(dir, "openapi.yaml")
+				doc, err := ParseDocument(cfg, root)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				changed := filepath.Join(dir, filepath.FromSlash(tt.changed))
+				rewriteFile(t, changed, tt.content)
+
+				err = doc.Reload(changed)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !strings.Contains(documentYAML(t, doc), "changed") {
+					t.Errorf("expected the changed file to be reloaded, got:\n%s", documentYAML(t, doc))
+				}
+
+				assertReloaded(t, cfg, doc, root)
+			})
+		}
+	}
+}
+
+func TestReloadAfterFailure(t *testing.T) {
+	dir := writeFiles(t, reloadFiles)
+	root := filepath.Join(dir, "openapi.yaml")
+	doc, err := ParseDocument(Config{}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner := filepath.Join(dir, "owner.yaml")
+	rewriteFile(t, owner, "components: [")
+	err = doc.Reload(owner)
+	if err == nil {
+		t.Fatalf("expected reloading an invalid file to fail")
+	}
+
+	// the file failed to reload is reloaded by the next reload, even when it is not provided again
+	rewriteFile(t, owner, "components:\n  schemas:\n    Owner:\n      title: fixed owner\n")
+	err = doc.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertReloaded(t, Config{}, doc, root)
+}
+
+func TestReloadUnsupported(t *testing.T) {
+	err := Document{Root: &OpenAPI{}, ReferencedDocuments: NewDocumentCache()}.Reload()
+	if !errors.Is(err, ErrReloadUnsupported) {
+		t.Errorf("expected %v, got %v", ErrReloadUnsupported, err)
+	}
+}
+
+func TestSources(t *testing.T) {
+	dir := writeFiles(t, reloadFiles)
+	doc, err := ParseDocument(Config{}, filepath.Join(dir, "openapi.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sources := doc.Sources()
+	expected := []string{"openapi.yaml", "owner.yaml", "pets/pet.yaml", "pets/tag.yaml"}
+	if len(sources) != len(expected) {
+		t.Fatalf("expected sources %v, got %v", expected, sources)
+	}
+
+	for idx, name := range expected {
+		if sources[idx] != canonicalDocumentPath(filepath.Join(dir, filepath.FromSlash(name))) {
+			t.Errorf("expected sources %v, got %v", expected, sources)
+		}
+	}
+}
+
+func TestDocumentCacheInvalidate(t *testing.T) {
+	cache := NewDocumentCache()
+	cache.addDependency("/specs/openapi.yaml", "/specs/pet.yaml")
+	cache.addDependency("/specs/pet.yaml", "/specs/tag.yaml")
+	cache.addDependency("/specs/openapi.yaml", "/specs/owner.yaml")
+
+	affected := cache.invalidate("/specs/tag.yaml")
+	for _, path := range []string{"/specs/tag.yaml", "/specs/pet.yaml", "/specs/openapi.yaml"} {
+		if !affected[canonicalDocumentPath(path)] {
+			t.Errorf("expected %s to be affected, got %v", path, affected)
+		}
+	}
+
+	if affected[canonicalDocumentPath("/specs/owner.yaml")] {
+		t.Errorf("expected documents not depending on the changed one not to be affected, got %v", affected)
+	}
+}
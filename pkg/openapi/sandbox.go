@@ -0,0 +1,337 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrPathEscapesRoot occurs when a document, directly or through a symbolic link, is outside of the root directory of the sandbox
+	ErrPathEscapesRoot = errors.New("path escapes the sandbox root directory")
+	// ErrAbsolutePathForbidden occurs when a reference points to a document by an absolute path while the sandbox forbids absolute paths
+	ErrAbsolutePathForbidden = errors.New("absolute paths are forbidden in the sandbox")
+	// ErrSymlinkForbidden occurs when a path to a document goes through a symbolic link while the sandbox forbids symbolic links
+	ErrSymlinkForbidden = errors.New("symbolic links are forbidden in the sandbox")
+	// ErrURLForbidden occurs when a reference points to a document by an URL while the sandbox does not allow URLs
+	ErrURLForbidden = errors.New("URLs are forbidden in the sandbox")
+	// ErrFileTooLarge occurs when a document is larger than the sandbox allows
+	ErrFileTooLarge = errors.New("file exceeds the sandbox size limit")
+	// ErrTooManyFiles occurs when more documents are read than the sandbox allows
+	ErrTooManyFiles = errors.New("number of files exceeds the sandbox limit")
+	// ErrRefDepthExceeded occurs when a chain of references to documents is deeper than the sandbox allows
+	ErrRefDepthExceeded = errors.New("depth of references exceeds the sandbox limit")
+	// ErrNotRegularFile occurs when a document in the sandbox is not a regular file, eg. a directory or a device
+	ErrNotRegularFile = errors.New("not a regular file")
+	// ErrFileChanged occurs when the file opened in the sandbox is not the one its path leads to anymore, eg. when it was swapped for a symbolic link while it was opened
+	ErrFileChanged = errors.New("file changed while it was read")
+)
+
+// Sandbox limits documents that can be read while references are resolved, eg. for specifications submitted by users.
+// Zero limits are not enforced.
+type Sandbox struct {
+	// RootDir is a directory documents have to be in. When empty, the directory of the root document is used
+	RootDir string
+	// ForbidSymlinks rejects paths going through symbolic links below the root directory. When false, symbolic links are followed, as long as their targets stay inside of the root directory
+	ForbidSymlinks bool
+	// ForbidAbsolutePaths rejects references pointing to documents by absolute paths, eg. "/specs/common.yaml#/components/schemas/Money"
+	ForbidAbsolutePaths bool
+	// AllowURLs allows references pointing to documents by URLs, which are otherwise rejected
+	AllowURLs bool
+	// MaxFileSize limits the size of a single document, in bytes
+	MaxFileSize int64
+	// MaxFiles limits the number of distinct documents read, including the root document
+	MaxFiles int
+	// MaxRefDepth limits the length of chains of references to other documents, eg. 1 allows the root document to reference other documents, but not those documents to reference further ones
+	MaxRefDepth int
+}
+
+// sandboxState is shared by the root document and all documents referenced by it, which can be loaded concurrently
+type sandboxState struct {
+	mu              sync.Mutex
+	rootDir         string
+	resolvedRootDir string
+	files           map[string]bool
+}
+
+func newSandboxState() *sandboxState {
+	return &sandboxState{
+		files: make(map[string]bool),
+	}
+}
+
+func (s *sandboxState) roots() (string, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.rootDir, s.resolvedRootDir
+}
+
+// setSandboxRoot sets the root directory of the sandbox, unless it is already set. The configured root directory takes precedence over the provided one
+func (cfg Config) setSandboxRoot(dir string) {
+	if cfg.Sandbox == nil || cfg.sandboxState == nil {
+		return
+	}
+
+	cfg.sandboxState.mu.Lock()
+	defer cfg.sandboxState.mu.Unlock()
+
+	if cfg.sandboxState.rootDir != "" {
+		return
+	}
+
+	if cfg.Sandbox.RootDir != "" {
+		dir = cfg.Sandbox.RootDir
+	}
+
+	rootDir, err := filepath.Abs(dir)
+	if err != nil {
+		rootDir = filepath.Clean(dir)
+	}
+
+	cfg.sandboxState.rootDir = rootDir
+	cfg.sandboxState.resolvedRootDir = rootDir
+	if resolved, err := filepath.EvalSymlinks(rootDir); err == nil {
+		cfg.sandboxState.resolvedRootDir = resolved
+	}
+}
+
+// checkReference checks the path of a referenced document as it is written in the reference, before it is joined with the directory of the referencing document
+func (cfg Config) checkReference(documentPath string, depth int) error {
+	if cfg.Sandbox == nil {
+		return nil
+	}
+
+	if cfg.Sandbox.ForbidAbsolutePaths && filepath.IsAbs(documentPath) {
+		return fmt.Errorf("%w: %s", ErrAbsolutePathForbidden, documentPath)
+	}
+
+	if cfg.Sandbox.MaxRefDepth > 0 && depth > cfg.Sandbox.MaxRefDepth {
+		return fmt.Errorf("%w: %s is referenced at depth %d", ErrRefDepthExceeded, documentPath, depth)
+	}
+
+	return nil
+}
+
+// load reads the file with the loader of the config, within limits of the sandbox
+func (cfg Config) load(ctx context.Context, filePath string) ([]byte, error) {
+	if cfg.Sandbox == nil {
+		return loadContext(ctx, cfg.loader(), filePath)
+	}
+
+	err := cfg.checkPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if readsOSFiles(cfg.loader(), filePath) {
+		return cfg.loadOSFile(ctx, filePath)
+	}
+
+	data, err := loadContext(ctx, cfg.sandboxLoader(), filePath)
+	if errors.Is(err, ErrResponseTooLarge) {
+		return nil, fmt.Errorf("%w: %s has more than %d bytes", ErrFileTooLarge, filePath, cfg.Sandbox.MaxFileSize)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if cfg.Sandbox.MaxFileSize > 0 && int64(len(data)) > cfg.Sandbox.MaxFileSize {
+		return nil, fmt.Errorf("%w: %s has %d bytes", ErrFileTooLarge, filePath, len(data))
+	}
+
+	return data, nil
+}
+
+// sandboxLoader returns the loader of the config with its response size limited to the size limit of the sandbox, so documents fetched by URLs are not read whole before their size is checked
+func (cfg Config) sandboxLoader() Loader {
+	loader := cfg.loader()
+	if cfg.Sandbox.MaxFileSize <= 0 {
+		return loader
+	}
+
+	var httpLoader HTTPLoader
+	switch l := loader.(type) {
+	case HTTPLoader:
+		httpLoader = l
+	case *HTTPLoader:
+		httpLoader = *l
+	default:
+		return loader
+	}
+
+	if httpLoader.MaxBytes <= 0 || httpLoader.MaxBytes > cfg.Sandbox.MaxFileSize {
+		httpLoader.MaxBytes = cfg.Sandbox.MaxFileSize
+	}
+
+	return httpLoader
+}
+
+// checkPath checks the path of a document against the root directory and limits of the sandbox, before the document is read
+func (cfg Config) checkPath(filePath string) error {
+	rootDir, _ := cfg.sandboxState.roots()
+	if isURL(filePath) {
+		if !cfg.Sandbox.AllowURLs {
+			return fmt.Errorf("%w: %s", ErrURLForbidden, filePath)
+		}
+
+		return cfg.countFile(filePath)
+	}
+
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+
+	if _, inside := relativeToDir(rootDir, absPath); !inside {
+		return fmt.Errorf("%w: %s is outside of %s", ErrPathEscapesRoot, filePath, rootDir)
+	}
+
+	return cfg.countFile(absPath)
+}
+
+// loadOSFile reads the file from the OS filesystem through a single handle. Symbolic links and the size are checked against the opened file,
+// so the file cannot be swapped between the checks and reading, eg. for a symbolic link pointing outside of the root directory or for a larger file
+func (cfg Config) loadOSFile(ctx context.Context, filePath string) ([]byte, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(absPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%w: %s", ErrNotRegularFile, filePath)
+	}
+
+	err = cfg.checkOpenedFile(absPath, info)
+	if err != nil {
+		return nil, err
+	}
+
+	maxFileSize := cfg.Sandbox.MaxFileSize
+	if maxFileSize <= 0 {
+		return ioutil.ReadAll(contextReader{ctx: ctx, r: file})
+	}
+
+	if info.Size() > maxFileSize {
+		return nil, fmt.Errorf("%w: %s has %d bytes", ErrFileTooLarge, filePath, info.Size())
+	}
+
+	// the file can grow after it was opened, so it is not read past the limit
+	data, err := ioutil.ReadAll(io.LimitReader(contextReader{ctx: ctx, r: file}, maxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > maxFileSize {
+		return nil, fmt.Errorf("%w: %s has more than %d bytes", ErrFileTooLarge, filePath, maxFileSize)
+	}
+
+	return data, nil
+}
+
+// checkOpenedFile checks that the path leads to the opened file inside of the root directory: through no symbolic links when they are forbidden,
+// or to a target inside of the root directory otherwise
+func (cfg Config) checkOpenedFile(absPath string, opened os.FileInfo) error {
+	rootDir, resolvedRootDir := cfg.sandboxState.roots()
+	target := absPath
+	if cfg.Sandbox.ForbidSymlinks {
+		relPath, _ := relativeToDir(rootDir, absPath)
+		current := rootDir
+		for _, item := range strings.Split(relPath, string(filepath.Separator)) {
+			current = filepath.Join(current, item)
+			info, err := os.Lstat(current)
+			if err != nil {
+				return err
+			}
+
+			if info.Mode()&os.ModeSymlink != 0 {
+				return fmt.Errorf("%w: %s", ErrSymlinkForbidden, current)
+			}
+		}
+	} else {
+		resolved, err := filepath.EvalSymlinks(absPath)
+		if err != nil {
+			return err
+		}
+
+		if _, inside := relativeToDir(resolvedRootDir, resolved); !inside {
+			return fmt.Errorf("%w: %s links to %s", ErrPathEscapesRoot, absPath, resolved)
+		}
+
+		target = resolved
+	}
+
+	info, err := os.Lstat(target)
+	if err != nil {
+		return err
+	}
+
+	if !os.SameFile(info, opened) {
+		return fmt.Errorf("%w: %s", ErrFileChanged, absPath)
+	}
+
+	return nil
+}
+
+// readsOSFiles checks whether the loader reads the path from the OS filesystem
+func readsOSFiles(loader Loader, filePath string) bool {
+	switch l := loader.(type) {
+	case OSLoader, *OSLoader:
+		return true
+	case HTTPLoader:
+		return !isURL(filePath) && (l.Fallback == nil || readsOSFiles(l.Fallback, filePath))
+	case *HTTPLoader:
+		return !isURL(filePath) && (l.Fallback == nil || readsOSFiles(l.Fallback, filePath))
+	default:
+		return false
+	}
+}
+
+// countFile adds the file to files read in the sandbox, unless it has already been read
+func (cfg Config) countFile(filePath string) error {
+	cfg.sandboxState.mu.Lock()
+	defer cfg.sandboxState.mu.Unlock()
+
+	files := cfg.sandboxState.files
+	if files[filePath] {
+		return nil
+	}
+
+	if cfg.Sandbox.MaxFiles > 0 && len(files) >= cfg.Sandbox.MaxFiles {
+		return fmt.Errorf("%w: %s would be file number %d", ErrTooManyFiles, filePath, len(files)+1)
+	}
+
+	files[filePath] = true
+	return nil
+}
+
+// relativeToDir returns the path relative to the directory, and whether the path is inside of the directory
+func relativeToDir(dir, filePath string) (string, bool) {
+	relPath, err := filepath.Rel(dir, filePath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return relPath, true
+}
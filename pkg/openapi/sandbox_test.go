@@ -0,0 +1,273 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sandboxRoot returns a root document with the schema referencing the ref
+func sandboxRoot(ref string) string {
+	return fmt.Sprintf(`openapi: 3.0.0
+components:
+  schemas:
+    Pet:
+      $ref: '%s'
+`, ref)
+}
+
+func TestSandbox(t *testing.T) {
+	outside := writeFiles(t, map[string]string{
+		"secret.yaml": petSchema("secret"),
+	})
+
+	tests := []struct {
+		name    string
+		sandbox Sandbox
+		files   map[string]string
+		links   map[string]string
+		err     error
+	}{
+		{
+			name:    "relative ref inside of the root",
+			sandbox: Sandbox{},
+			files: map[string]string{
+				"specs/openapi.yaml":       sandboxRoot("common/pet.yaml#/components/schemas/Pet"),
+				"specs/common/pet.yaml":    sandboxRoot("../shared/pet.yaml#/components/schemas/Pet"),
+				"specs/shared/pet.yaml":    petSchema("shared"),
+				"specs/unused/unused.yaml": petSchema("unused"),
+			},
+		},
+		{
+			name:    "relative ref escaping the root",
+			sandbox: Sandbox{},
+			files: map[string]string{
+				"specs/openapi.yaml": sandboxRoot("../common.yaml#/components/schemas/Pet"),
+				"common.yaml":        petSchema("common"),
+			},
+			err: ErrPathEscapesRoot,
+		},
+		{
+			// absolute paths in refs are joined with the directory of the document as well, so they cannot point outside of the root
+			name:    "absolute ref outside of the root",
+			sandbox: Sandbox{},
+			files: map[string]string{
+				"specs/openapi.yaml": sandboxRoot(filepath.Join(outside, "secret.yaml") + "#/components/schemas/Pet"),
+			},
+			err: fs.ErrNotExist,
+		},
+		{
+			name:    "absolute refs forbidden",
+			sandbox: Sandbox{ForbidAbsolutePaths: true},
+			files: map[string]string{
+				"specs/openapi.yaml": sandboxRoot(filepath.Join(outside, "secret.yaml") + "#/components/schemas/Pet"),
+			},
+			err: ErrAbsolutePathForbidden,
+		},
+		{
+			name:    "symlink escaping the root",
+			sandbox: Sandbox{},
+			files: map[string]string{
+				"specs/openapi.yaml": sandboxRoot("common.yaml#/components/schemas/Pet"),
+			},
+			links: map[string]string{
+				"specs/common.yaml": filepath.Join(outside, "secret.yaml"),
+			},
+			err: ErrPathEscapesRoot,
+		},
+		{
+			name:    "symlink inside of the root",
+			sandbox: Sandbox{},
+			files: map[string]string{
+				"specs/openapi.yaml":    sandboxRoot("common.yaml#/components/schemas/Pet"),
+				"specs/shared/pet.yaml": petSchema("shared"),
+			},
+			links: map[string]string{
+				"specs/common.yaml": "shared/pet.yaml",
+			},
+		},
+		{
+			name:    "symlinks forbidden",
+			sandbox: Sandbox{ForbidSymlinks: true},
+			files: map[string]string{
+				"specs/openapi.yaml":    sandboxRoot("common.yaml#/components/schemas/Pet"),
+				"specs/shared/pet.yaml": petSchema("shared"),
+			},
+			links: map[string]string{
+				"specs/common.yaml": "shared/pet.yaml",
+			},
+			err: ErrSymlinkForbidden,
+		},
+		{
+			name:    "files limit",
+			sandbox: Sandbox{MaxFiles: 2},
+			files: map[string]string{
+				"specs/openapi.yaml":    sandboxRoot("common/pet.yaml#/components/schemas/Pet"),
+				"specs/common/pet.yaml": sandboxRoot("../shared/pet.yaml#/components/schemas/Pet"),
+				"specs/shared/pet.yaml": petSchema("shared"),
+			},
+			err: ErrTooManyFiles,
+		},
+		{
+			name:    "files limit with the same file referenced many times",
+			sandbox: Sandbox{MaxFiles: 2},
+			files: map[string]string{
+				"specs/openapi.yaml": sandboxRoot("common.yaml#/components/schemas/Pet") + "    Other:\n      $ref: 'common.yaml#/components/schemas/Pet'\n",
+				"specs/common.yaml":  petSchema("common"),
+			},
+		},
+		{
+			name:    "ref depth limit",
+			sandbox: Sandbox{MaxRefDepth: 1},
+			files: map[string]string{
+				"specs/openapi.yaml":    sandboxRoot("common/pet.yaml#/components/schemas/Pet"),
+				"specs/common/pet.yaml": sandboxRoot("../shared/pet.yaml#/components/schemas/Pet"),
+				"specs/shared/pet.yaml": petSchema("shared"),
+			},
+			err: ErrRefDepthExceeded,
+		},
+		{
+			name:    "file size limit",
+			sandbox: Sandbox{MaxFileSize: 100},
+			files: map[string]string{
+				"specs/openapi.yaml": sandboxRoot("common.yaml#/components/schemas/Pet"),
+				"specs/common.yaml":  petSchema(strings.Repeat("large", 20)),
+			},
+			err: ErrFileTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sandboxDir := writeFiles(t, tt.files)
+			for name, target := range tt.links {
+				err := os.Symlink(target, filepath.Join(sandboxDir, filepath.FromSlash(name)))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			sandbox := tt.sandbox
+			_, err := ParseDocument(Config{Sandbox: &sandbox}, filepath.Join(sandboxDir, "specs", "openapi.yaml"))
+			if tt.err == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestSandboxRootDir(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"specs/openapi.yaml": sandboxRoot("../common.yaml#/components/schemas/Pet"),
+		"common.yaml":        petSchema("common"),
+	})
+
+	doc, err := ParseDocument(Config{Sandbox: &Sandbox{RootDir: dir}}, filepath.Join(dir, "specs", "openapi.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.Root.Components.Schemas["Pet"].Title != "common" {
+		t.Errorf("expected the document in the configured root directory to be read")
+	}
+}
+
+func TestSandboxURLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(sandboxRoot("#/components/schemas/Other") + "    Other:\n      type: object\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	documentURL := server.URL + "/openapi.yaml"
+	tests := []struct {
+		name    string
+		sandbox Sandbox
+		err     error
+	}{
+		{
+			name:    "forbidden by default",
+			sandbox: Sandbox{},
+			err:     ErrURLForbidden,
+		},
+		{
+			name:    "allowed",
+			sandbox: Sandbox{AllowURLs: true},
+		},
+		{
+			name:    "response not read past the file size limit",
+			sandbox: Sandbox{AllowURLs: true, MaxFileSize: 16},
+			err:     ErrFileTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sandbox := tt.sandbox
+			_, err := ParseDocument(Config{Loader: HTTPLoader{}, Sandbox: &sandbox}, documentURL)
+			if tt.err == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestSandboxOpenedFileSwapped(t *testing.T) {
+	outside := writeFiles(t, map[string]string{
+		"secret.yaml": petSchema("secret"),
+	})
+	dir := writeFiles(t, map[string]string{
+		"openapi.yaml": petSchema("pet"),
+	})
+
+	cfg := Config{Sandbox: &Sandbox{}, sandboxState: newSandboxState()}
+	cfg.setSandboxRoot(dir)
+
+	path := filepath.Join(dir, "openapi.yaml")
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	opened, err := file.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the file is swapped for another one after it was opened, as if the path was changed between checks and reading
+	err = os.Rename(filepath.Join(outside, "secret.yaml"), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = cfg.checkOpenedFile(path, opened)
+	if !errors.Is(err, ErrFileChanged) {
+		t.Errorf("expected %v, got %v", ErrFileChanged, err)
+	}
+}
+
+func TestSandboxNotRegularFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"specs/openapi.yaml": sandboxRoot("common#/components/schemas/Pet"),
+		"specs/common/.keep": "",
+	})
+
+	_, err := ParseDocument(Config{Sandbox: &Sandbox{}}, filepath.Join(dir, "specs", "openapi.yaml"))
+	if !errors.Is(err, ErrNotRegularFile) {
+		t.Errorf("expected %v, got %v", ErrNotRegularFile, err)
+	}
+}
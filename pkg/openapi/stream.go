@@ -0,0 +1,493 @@
+package openapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	documentStartMarker = "---"
+	documentEndMarker   = "..."
+	directivePrefix     = "%"
+	commentPrefix       = "#"
+	sequenceItemPrefix  = "-"
+	emptyDocumentYAML   = "{}\n"
+)
+
+var (
+	// ErrStreamingUnsupported occurs when the document is streamed with a config that needs the whole document in memory, eg. inlining local references
+	ErrStreamingUnsupported = errors.New("configuration is not supported when streaming")
+)
+
+// ResolveStream reads the document from the reader, resolves its references and writes it to the writer - with the same output as Read, ResolveReferences and Write,
+// but without holding paths and webhooks in memory. Block-style YAML is read section by section, and every path and webhook is parsed, resolved and spooled to a temporary file on its own,
+// so memory is bounded by the largest path, components and documents referenced from paths. Components referenced from other documents are added to components, as by ResolveReferences.
+// Inlining local references and pruning components need the whole document and are not supported. Templates of substitution cannot span sections, and anchors cannot be aliased across sections.
+// After the call, the Root holds the document without paths and webhooks.
+func (doc Document) ResolveStream(r io.Reader, w io.Writer) error {
+	return doc.ResolveStreamContext(context.Background(), r, w)
+}
+
+// ResolveStreamContext streams the document as ResolveStream does, stopping when the context is canceled or its deadline is exceeded
+func (doc Document) ResolveStreamContext(ctx context.Context, r io.Reader, w io.Writer) error {
+	if doc.Cfg.InlineLocalRefs || doc.Cfg.PruneComponents {
+		return fmt.Errorf("%w: inlining local references and pruning components need the whole document", ErrStreamingUnsupported)
+	}
+
+	resolver, err := newStreamResolver(ctx, doc)
+	if err != nil {
+		return err
+	}
+	defer resolver.close()
+
+	reader := bufio.NewReader(contextReader{ctx: ctx, r: r})
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			lineErr := resolver.line(line)
+			if lineErr != nil {
+				return lineErr
+			}
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	err = resolver.finish()
+	if err != nil {
+		return err
+	}
+
+	return resolver.write(contextWriter{ctx: ctx, w: w})
+}
+
+// streamResolver splits the document into top-level sections, and sections of paths and webhooks into their entries
+type streamResolver struct {
+	ctx         context.Context
+	doc         Document
+	spools      map[string]*spool
+	components  *Components
+	section     bytes.Buffer
+	streamed    string
+	entryIndent int
+	entry       bytes.Buffer
+}
+
+func newStreamResolver(ctx context.Context, doc Document) (*streamResolver, error) {
+	resolver := &streamResolver{
+		ctx:        ctx,
+		doc:        doc,
+		spools:     make(map[string]*spool),
+		components: &Components{},
+	}
+
+	for _, key := range []string{pathsKey, webhooksKey} {
+		spool, err := newSpool()
+		if err != nil {
+			resolver.close()
+			return nil, err
+		}
+
+		resolver.spools[key] = spool
+	}
+
+	return resolver, nil
+}
+
+func (s *streamResolver) line(line string) error {
+	if isTopLevelLine(line) {
+		err := s.flush()
+		if err != nil {
+			return err
+		}
+
+		s.streamed = streamedKey(line)
+		s.entryIndent = -1
+		if s.streamed == "" {
+			s.section.WriteString(line)
+		}
+
+		return nil
+	}
+
+	if s.streamed == "" {
+		s.section.WriteString(line)
+		return nil
+	}
+
+	trimmed := strings.TrimLeft(line, " ")
+	if strings.TrimSpace(trimmed) == "" || strings.HasPrefix(trimmed, commentPrefix) {
+		if s.entry.Len() > 0 {
+			s.entry.WriteString(line)
+		}
+
+		return nil
+	}
+
+	indent := len(line) - len(trimmed)
+	if s.entryIndent < 0 {
+		s.entryIndent = indent
+	}
+
+	if indent <= s.entryIndent && !isSequenceItem(trimmed) {
+		err := s.flushEntry()
+		if err != nil {
+			return err
+		}
+	}
+
+	s.entry.WriteString(line)
+	return nil
+}
+
+// flush parses the finished top-level section into the root, or resolves the last entry of the finished section of paths or webhooks
+func (s *streamResolver) flush() error {
+	if s.section.Len() > 0 {
+		sectionDoc := Document{Cfg: s.doc.Cfg, Root: &OpenAPI{}}
+		err := sectionDoc.Parse(s.section.Bytes())
+		if err != nil {
+			return err
+		}
+
+		s.section.Reset()
+		addSection(s.doc.Root, sectionDoc.Root)
+	}
+
+	return s.flushEntry()
+}
+
+// addSection sets fields of the root that are set in the section, and adds extensions of the section
+func addSection(root, section *OpenAPI) {
+	rootValue := reflect.ValueOf(root).Elem()
+	sectionValue := reflect.ValueOf(section).Elem()
+	for idx := 0; idx < sectionValue.NumField(); idx++ {
+		sectionField := sectionValue.Field(idx)
+		if sectionField.IsZero() {
+			continue
+		}
+
+		rootField := rootValue.Field(idx)
+		if sectionField.Kind() != reflect.Map || rootField.IsNil() {
+			rootField.Set(sectionField)
+			continue
+		}
+
+		mapIter := sectionField.MapRange()
+		for mapIter.Next() {
+			rootField.SetMapIndex(mapIter.Key(), mapIter.Value())
+		}
+	}
+}
+
+// flushEntry parses and resolves the finished entry of paths or webhooks, and spools it
+func (s *streamResolver) flushEntry() error {
+	if s.entry.Len() == 0 {
+		return nil
+	}
+
+	entryDoc := Document{
+		Cfg:                 s.doc.Cfg,
+		RefDirectory:        s.doc.RefDirectory,
+		FileName:            s.doc.FileName,
+		Root:                &OpenAPI{},
+		ReferencedDocuments: s.doc.ReferencedDocuments,
+	}
+
+	err := entryDoc.Parse(append([]byte(s.streamed+":\n"), s.entry.Bytes()...))
+	if err != nil {
+		return err
+	}
+	s.entry.Reset()
+
+	entryDoc.Root.Components = &Components{}
+	err = entryDoc.LoadReferencedDocuments(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	err = entryDoc.ResolveReferencesContext(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	addComponents(s.components, entryDoc.Root.Components)
+
+	entries := entryDoc.Root.Paths
+	if s.streamed == webhooksKey {
+		entries = entryDoc.Root.Webhooks
+	}
+
+	for key, item := range entries {
+		single := &OpenAPI{Paths: map[string]*PathItem{key: item}}
+		data, err := yaml.Marshal(single)
+		if err != nil {
+			return err
+		}
+
+		err = s.spools[s.streamed].add(key, data[bytes.IndexByte(data, '\n')+1:])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// finish resolves references of the root without paths and webhooks, after components referenced from paths and webhooks are added
+func (s *streamResolver) finish() error {
+	err := s.flush()
+	if err != nil {
+		return err
+	}
+
+	if !componentsEmpty(s.components) {
+		if s.doc.Root.Components == nil {
+			s.doc.Root.Components = &Components{}
+		}
+
+		addComponents(s.doc.Root.Components, s.components)
+	}
+
+	err = s.doc.LoadReferencedDocuments(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.doc.ResolveReferencesContext(s.ctx)
+}
+
+// write writes sections of the root in the order of Write, with spooled paths and webhooks
+func (s *streamResolver) write(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	written := false
+
+	root := reflect.ValueOf(s.doc.Root).Elem()
+	fields := fieldsOf(root.Type())
+	for idx, name := range fields.names {
+		key := getYamlKeyFromField(root.Type().Field(idx))
+		if spool, ok := s.spools[key]; ok && spool.len() > 0 {
+			_, err := writer.WriteString(key + ":\n")
+			if err != nil {
+				return err
+			}
+
+			err = spool.writeTo(writer)
+			if err != nil {
+				return err
+			}
+
+			written = true
+			continue
+		}
+
+		section := &OpenAPI{}
+		reflect.ValueOf(section).Elem().FieldByName(name).Set(root.Field(idx))
+		data, err := yaml.Marshal(section)
+		if err != nil {
+			return err
+		}
+
+		if string(data) == emptyDocumentYAML {
+			continue
+		}
+
+		_, err = writer.Write(data)
+		if err != nil {
+			return err
+		}
+
+		written = true
+	}
+
+	if !written {
+		_, err := writer.WriteString(emptyDocumentYAML)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+func (s *streamResolver) close() {
+	for _, spool := range s.spools {
+		spool.close()
+	}
+}
+
+// isTopLevelLine checks whether the line starts a top-level key. Markers of a document start and end, directives and items of sequences, which can be indented as their keys, are not keys
+func isTopLevelLine(line string) bool {
+	if line == "" || strings.ContainsAny(line[:1], " \t\r\n"+commentPrefix) || isSequenceItem(line) {
+		return false
+	}
+
+	return !strings.HasPrefix(line, documentStartMarker) && !strings.HasPrefix(line, documentEndMarker) && !strings.HasPrefix(line, directivePrefix)
+}
+
+// isSequenceItem checks whether the line, without indentation, starts an item of a block sequence
+func isSequenceItem(trimmed string) bool {
+	return strings.HasPrefix(trimmed, sequenceItemPrefix) && (len(trimmed) == 1 || strings.ContainsAny(trimmed[1:2], " \t\r\n"))
+}
+
+// streamedKey returns paths or webhooks when the line starts their block-style section, or an empty string otherwise
+func streamedKey(line string) string {
+	content := line
+	if idx := strings.Index(content, " "+commentPrefix); idx >= 0 {
+		content = content[:idx]
+	}
+
+	switch strings.TrimSpace(content) {
+	case pathsKey + ":":
+		return pathsKey
+	case webhooksKey + ":":
+		return webhooksKey
+	default:
+		return ""
+	}
+}
+
+// addComponents adds components of the source to the target, replacing components with the same names
+func addComponents(target, source *Components) {
+	targetValue := reflect.ValueOf(target).Elem()
+	sourceValue := reflect.ValueOf(source).Elem()
+	for _, idx := range fieldsOf(targetValue.Type()).nested {
+		sourceField := sourceValue.Field(idx)
+		if sourceField.Kind() != reflect.Map || sourceField.Len() == 0 {
+			continue
+		}
+
+		targetField := targetValue.Field(idx)
+		if targetField.IsNil() {
+			targetField.Set(reflect.MakeMap(targetField.Type()))
+		}
+
+		mapIter := sourceField.MapRange()
+		for mapIter.Next() {
+			targetField.SetMapIndex(mapIter.Key(), mapIter.Value())
+		}
+	}
+}
+
+func componentsEmpty(components *Components) bool {
+	value := reflect.ValueOf(components).Elem()
+	for _, idx := range fieldsOf(value.Type()).nested {
+		if value.Field(idx).Len() > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// spool keeps entries of paths or webhooks in a temporary file, so they do not have to be kept in memory
+type spool struct {
+	file    *os.File
+	size    int64
+	entries map[string]spooledEntry
+}
+
+type spooledEntry struct {
+	offset int64
+	length int
+}
+
+func newSpool() (*spool, error) {
+	file, err := ioutil.TempFile("", "openapi-stream-*")
+	if err != nil {
+		return nil, err
+	}
+
+	return &spool{
+		file:    file,
+		entries: make(map[string]spooledEntry),
+	}, nil
+}
+
+// add appends the entry to the spool, replacing the entry with the same key
+func (s *spool) add(key string, data []byte) error {
+	_, err := s.file.Write(data)
+	if err != nil {
+		return err
+	}
+
+	s.entries[key] = spooledEntry{offset: s.size, length: len(data)}
+	s.size += int64(len(data))
+	return nil
+}
+
+func (s *spool) len() int {
+	return len(s.entries)
+}
+
+// writeTo writes entries in the order keys of maps are marshalled in
+func (s *spool) writeTo(w io.Writer) error {
+	keys := make([]string, 0, len(s.entries))
+	for key := range s.entries {
+		keys = append(keys, key)
+	}
+
+	ordered, err := yamlKeyOrder(keys)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range ordered {
+		entry := s.entries[key]
+		_, err := io.Copy(w, io.NewSectionReader(s.file, entry.offset, int64(entry.length)))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *spool) close() {
+	s.file.Close()
+	os.Remove(s.file.Name())
+}
+
+// yamlKeyOrder sorts keys in the order keys of maps are marshalled in, by marshalling them
+func yamlKeyOrder(keys []string) ([]string, error) {
+	indexes := make(map[string]int, len(keys))
+	for idx, key := range keys {
+		indexes[key] = idx
+	}
+
+	data, err := yaml.Marshal(indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	var marshalled yaml.MapSlice
+	err = yaml.Unmarshal(data, &marshalled)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make([]string, 0, len(keys))
+	for _, item := range marshalled {
+		idx, ok := item.Value.(int)
+		if !ok || idx < 0 || idx >= len(keys) {
+			return nil, fmt.Errorf("could not order key %v", item.Key)
+		}
+
+		ordered = append(ordered, keys[idx])
+	}
+
+	return ordered, nil
+}
@@ -0,0 +1,143 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const streamSpec = `openapi: 3.1.0
+info:
+  title: Pets
+  version: "1.0"
+# pets and their owners
+paths:
+  /pets:
+    get:
+      parameters:
+      - $ref: '#/components/parameters/Limit'
+      responses:
+        "200":
+          description: pets
+          content:
+            application/json:
+              schema:
+                $ref: 'common.yaml#/components/schemas/Money'
+
+  # owners are listed separately
+  /owners:
+    get:
+      responses:
+        "200":
+          description: owners
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Owner'
+webhooks: # sent on changes
+  newPet:
+    post:
+      responses:
+        "200":
+          description: sent
+components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+  schemas:
+    Owner:
+      type: string
+x-team: pets
+`
+
+var streamFiles = MapLoader{
+	"common.yaml": []byte("components:\n  schemas:\n    Money:\n      type: number\n"),
+}
+
+func TestResolveStream(t *testing.T) {
+	expectedDoc := NewDocument(Config{Loader: streamFiles})
+	err := expectedDoc.Read(strings.NewReader(streamSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = expectedDoc.ResolveReferences()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := documentYAML(t, expectedDoc)
+
+	var streamed bytes.Buffer
+	err = NewDocument(Config{Loader: streamFiles}).ResolveStream(strings.NewReader(streamSpec), &streamed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if streamed.String() != expected {
+		t.Errorf("expected the streamed output to equal the output of Write:\n%s\ngot:\n%s", expected, streamed.String())
+	}
+
+	if !strings.Contains(expected, "Money:") || !strings.Contains(expected, "newPet:") {
+		t.Errorf("expected remote components and webhooks in the output, got:\n%s", expected)
+	}
+}
+
+func TestResolveStreamEmptyDocument(t *testing.T) {
+	var streamed bytes.Buffer
+	err := NewDocument(Config{}).ResolveStream(strings.NewReader(""), &streamed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if streamed.String() != emptyDocumentYAML {
+		t.Errorf("expected %q, got %q", emptyDocumentYAML, streamed.String())
+	}
+}
+
+func TestResolveStreamUnsupportedConfig(t *testing.T) {
+	for _, cfg := range []Config{{InlineLocalRefs: true}, {PruneComponents: true}} {
+		err := NewDocument(cfg).ResolveStream(strings.NewReader(streamSpec), &bytes.Buffer{})
+		if !errors.Is(err, ErrStreamingUnsupported) {
+			t.Errorf("expected %v, got %v", ErrStreamingUnsupported, err)
+		}
+	}
+}
+
+func TestIsTopLevelLine(t *testing.T) {
+	tests := map[string]bool{
+		"paths:\n":       true,
+		"x-team: pets\n": true,
+		"  /pets:\n":     false,
+		"- item\n":       false,
+		"-key: value\n":  true,
+		"# comment\n":    false,
+		"---\n":          false,
+		"...\n":          false,
+		"%YAML 1.2\n":    false,
+		"\n":             false,
+	}
+
+	for line, expected := range tests {
+		if isTopLevelLine(line) != expected {
+			t.Errorf("%q: expected %t", line, expected)
+		}
+	}
+}
+
+func TestStreamedKey(t *testing.T) {
+	tests := map[string]string{
+		"paths:\n":                 pathsKey,
+		"webhooks: # on changes\n": webhooksKey,
+		"paths: {}\n":              "",
+		"components:\n":            "",
+	}
+
+	for line, expected := range tests {
+		if key := streamedKey(line); key != expected {
+			t.Errorf("%q: expected %q, got %q", line, expected, key)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// EnvValue is a name under which environment variables are available in {{ .Env.NAME }} placeholders
+	EnvValue = "Env"
+
+	valuePathSeparator = "."
+	assignmentOperator = "="
+)
+
+var (
+	// ErrUndefinedVariable occurs when a placeholder refers to a variable that is neither among values nor in the environment, and has no default
+	ErrUndefinedVariable = errors.New("variable is not defined")
+	// ErrInvalidAssignment occurs when an assignment is not in key=value form
+	ErrInvalidAssignment = errors.New("assignment should be in key=value form")
+	// ErrUnsafeValue occurs when a value would change the structure of the document if substituted into unquoted text, eg. a value with ": " in the middle of a text
+	ErrUnsafeValue = errors.New("value can not be safely substituted into unquoted text")
+
+	// placeholderPattern matches ${NAME} and ${NAME:-default} placeholders, along with {{ .Name }} placeholders.
+	// Plain $NAME is not matched, since "$ref" keys would be taken for variables
+	placeholderPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_.-]*)(:-([^}\n]*))?\}|\{\{\s*\.([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_-]*)*)\s*\}\}`)
+)
+
+// Substitution expands placeholders in the content of documents before parsing.
+// Both ${NAME} (with an optional default, eg. ${API_HOST:-localhost}) and {{ .Name }} placeholders are expanded. Other text, eg. a literal "{{", is left as it is.
+type Substitution struct {
+	// Values are available as ${name} and {{ .name }}. Nested values are available as ${parent.child} and {{ .parent.child }}
+	Values map[string]interface{}
+	// Env makes environment variables available as ${NAME}, when NAME is not among values, and as {{ .Env.NAME }}
+	Env bool
+}
+
+// NewSubstitution constructs Substitution without values
+func NewSubstitution(env bool) *Substitution {
+	return &Substitution{
+		Values: make(map[string]interface{}),
+		Env:    env,
+	}
+}
+
+// ReadValues reads values from a YAML file, overriding values with the same names
+func (s *Substitution) ReadValues(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var values map[string]interface{}
+	err = yaml.Unmarshal(data, &values)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range values {
+		s.Values[key] = stringKeys(value)
+	}
+
+	return nil
+}
+
+// Set sets a value from an assignment in key=value form. Dots in the key set nested values, eg. "db.host=localhost"
+func (s *Substitution) Set(assignment string) error {
+	idx := strings.Index(assignment, assignmentOperator)
+	if idx <= 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidAssignment, assignment)
+	}
+
+	keys := strings.Split(assignment[:idx], valuePathSeparator)
+	values := s.Values
+	for _, key := range keys[:len(keys)-1] {
+		nested, ok := values[key].(map[string]interface{})
+		if !ok {
+			nested = make(map[string]interface{})
+			values[key] = nested
+		}
+
+		values = nested
+	}
+
+	values[keys[len(keys)-1]] = assignment[idx+1:]
+	return nil
+}
+
+// Expand expands placeholders in the data. A nil Substitution leaves the data as it is.
+// Values are escaped according to the place of the placeholder: inside of quoted text they are escaped for the quotes, a placeholder being a whole unquoted value
+// is quoted when the value would not be read back as it is, and lines of values in block scalars are indented. Values that can not be safely inserted into
+// unquoted text, eg. with ": " inside, are an error. Placeholders in comments are left as they are.
+func (s *Substitution) Expand(data []byte) ([]byte, error) {
+	if s == nil {
+		return data, nil
+	}
+
+	expander := placeholderExpander{substitution: s, blockIndent: -1}
+	var expanded strings.Builder
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		expandedLine, err := expander.line(line)
+		if err != nil {
+			return data, err
+		}
+
+		expanded.WriteString(expandedLine)
+	}
+
+	if len(expander.undefined) > 0 {
+		return data, fmt.Errorf("%w: %s", ErrUndefinedVariable, strings.Join(expander.undefined, ", "))
+	}
+
+	return []byte(expanded.String()), nil
+}
+
+// placeholderExpander expands placeholders line by line, tracking YAML quoting and block scalars spanning many lines
+type placeholderExpander struct {
+	substitution *Substitution
+	// quote is a quote character of a quoted text continuing from previous lines, or 0 outside of quotes
+	quote byte
+	// blockIndent is an indentation of the line starting the current block scalar, or -1 outside of block scalars
+	blockIndent int
+	undefined   []string
+}
+
+func (e *placeholderExpander) line(line string) (string, error) {
+	indent := len(line) - len(strings.TrimLeft(line, " "))
+	if e.blockIndent >= 0 {
+		if strings.TrimSpace(line) == "" || indent > e.blockIndent {
+			return e.blockLine(line, indent)
+		}
+
+		e.blockIndent = -1
+	}
+
+	matches := placeholderPattern.FindAllStringSubmatchIndex(line, -1)
+	var expanded strings.Builder
+	// scalarStart is true where a new unquoted value can start: at the beginning of the line, after "- " and after "key: "
+	scalarStart := true
+	for i := 0; i < len(line); i++ {
+		if len(matches) > 0 && matches[0][0] < i {
+			matches = matches[1:]
+		}
+
+		if len(matches) > 0 && matches[0][0] == i && e.quote != '#' {
+			match := matches[0]
+			value, ok := e.value(line, match)
+			if ok {
+				replacement, err := e.escape(value, line[match[0]:match[1]], scalarStart, strings.TrimSpace(line[match[1]:]))
+				if err != nil {
+					return line, err
+				}
+
+				expanded.WriteString(replacement)
+			} else {
+				expanded.WriteString(line[match[0]:match[1]])
+			}
+
+			scalarStart = false
+			i = match[1] - 1
+			continue
+		}
+
+		c := line[i]
+		expanded.WriteByte(c)
+		switch e.quote {
+		case '#':
+			continue
+		case '"':
+			if c == '\\' && i+1 < len(line) {
+				i++
+				expanded.WriteByte(line[i])
+			} else if c == '"' {
+				e.quote = 0
+			}
+			continue
+		case '\'':
+			if c == '\'' && i+1 < len(line) && line[i+1] == '\'' {
+				i++
+				expanded.WriteByte(line[i])
+			} else if c == '\'' {
+				e.quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			e.quote = '#'
+		case (c == '"' || c == '\'') && scalarStart:
+			e.quote = c
+		case (c == '|' || c == '>') && scalarStart && isBlockHeader(line[i+1:]):
+			e.blockIndent = indent
+		case c == ':' && (i+1 == len(line) || line[i+1] == ' ' || line[i+1] == '\n'):
+			scalarStart = true
+		case c == '-' && scalarStart && (i+1 == len(line) || line[i+1] == ' ' || line[i+1] == '\n'):
+		case c == '[' || c == '{' || c == ',':
+			scalarStart = true
+		case c != ' ' && c != '\t' && c != '\n' && c != '\r':
+			scalarStart = false
+		}
+	}
+
+	// comments end with the line
+	if e.quote == '#' {
+		e.quote = 0
+	}
+
+	return expanded.String(), nil
+}
+
+// blockLine expands placeholders in a line of a block scalar, indenting lines of values the same as the line
+func (e *placeholderExpander) blockLine(line string, indent int) (string, error) {
+	var err error
+	expanded := placeholderPattern.ReplaceAllStringFunc(line, func(placeholder string) string {
+		match := placeholderPattern.FindStringSubmatchIndex(placeholder)
+		value, ok := e.value(placeholder, match)
+		if !ok {
+			return placeholder
+		}
+
+		if strings.Contains(value, "\r") {
+			err = fmt.Errorf("%w: %s", ErrUnsafeValue, placeholder)
+		}
+
+		return strings.ReplaceAll(value, "\n", "\n"+strings.Repeat(" ", indent))
+	})
+
+	return expanded, err
+}
+
+// value returns a value of the matched placeholder. Returned bool is false when the placeholder is undefined, which is recorded
+func (e *placeholderExpander) value(line string, match []int) (string, bool) {
+	if match[8] >= 0 {
+		name := line[match[8]:match[9]]
+		value, ok := e.substitution.lookupTemplate(name)
+		if !ok {
+			e.undefined = append(e.undefined, name)
+		}
+
+		return value, ok
+	}
+
+	name := line[match[2]:match[3]]
+	if value, ok := e.substitution.lookup(name); ok {
+		return value, true
+	}
+
+	// defaults are written by authors of the document, so they are inserted as they are
+	if match[4] >= 0 {
+		return line[match[6]:match[7]], true
+	}
+
+	e.undefined = append(e.undefined, name)
+	return "", false
+}
+
+// escape prepares the value for the place of the placeholder. Rest is the text following the placeholder in the line
+func (e *placeholderExpander) escape(value string, placeholder string, scalarStart bool, rest string) (string, error) {
+	switch e.quote {
+	case '"':
+		quoted := strconv.Quote(value)
+		return quoted[1 : len(quoted)-1], nil
+	case '\'':
+		if strings.ContainsAny(value, "\r\n") {
+			return value, fmt.Errorf("%w: %s", ErrUnsafeValue, placeholder)
+		}
+
+		return strings.ReplaceAll(value, "'", "''"), nil
+	}
+
+	if scalarStart && (rest == "" || strings.HasPrefix(rest, "#")) {
+		if isPlainScalar(value) {
+			return value, nil
+		}
+
+		return strconv.Quote(value), nil
+	}
+
+	if (scalarStart && !isPlainScalar(value)) || strings.ContainsAny(value, "\r\n") || strings.Contains(value, ": ") || strings.Contains(value, " #") || strings.HasSuffix(value, ":") {
+		return value, fmt.Errorf("%w: %s", ErrUnsafeValue, placeholder)
+	}
+
+	return value, nil
+}
+
+// isPlainScalar checks whether the value can be written as an unquoted YAML value and read back as the same text
+func isPlainScalar(value string) bool {
+	if value == "" || strings.TrimSpace(value) != value || strings.ContainsAny(value, "\r\n\t") {
+		return false
+	}
+
+	if strings.Contains(value, ": ") || strings.Contains(value, " #") || strings.HasSuffix(value, ":") {
+		return false
+	}
+
+	switch value[0] {
+	case '-', '?', ':':
+		return len(value) > 1 && value[1] != ' '
+	case ',', '[', ']', '{', '}', '#', '&', '*', '!', '|', '>', '\'', '"', '%', '@', '`':
+		return false
+	}
+
+	return true
+}
+
+// isBlockHeader checks whether the rest of the line following "|" or ">" holds only indicators of a block scalar, eg. "-" or "2", and a comment
+func isBlockHeader(rest string) bool {
+	rest = strings.TrimSpace(rest)
+	if idx := strings.Index(rest, " #"); idx >= 0 {
+		rest = strings.TrimSpace(rest[:idx])
+	}
+
+	return strings.Trim(rest, "+-0123456789") == ""
+}
+
+// lookup returns a value by its name, looking into nested values for names with dots and then into the environment
+func (s *Substitution) lookup(name string) (string, bool) {
+	if value, ok := s.lookupValue(name); ok {
+		return value, true
+	}
+
+	if s.Env {
+		return os.LookupEnv(name)
+	}
+
+	return "", false
+}
+
+// lookupTemplate returns a value of a {{ .Name }} placeholder, with environment variables available as {{ .Env.NAME }}
+func (s *Substitution) lookupTemplate(name string) (string, bool) {
+	if value, ok := s.lookupValue(name); ok {
+		return value, true
+	}
+
+	if s.Env && strings.HasPrefix(name, EnvValue+valuePathSeparator) {
+		return os.LookupEnv(strings.TrimPrefix(name, EnvValue+valuePathSeparator))
+	}
+
+	return "", false
+}
+
+// lookupValue returns a value by its name, looking into nested values for names with dots
+func (s *Substitution) lookupValue(name string) (string, bool) {
+	var value interface{} = s.Values
+	for _, key := range strings.Split(name, valuePathSeparator) {
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+
+		value, ok = values[key]
+		if !ok {
+			return "", false
+		}
+	}
+
+	if value == nil {
+		return "", false
+	}
+
+	return fmt.Sprint(value), true
+}
+
+// stringKeys converts maps decoded from YAML to maps with string keys, so they can be accessed in templates
+func stringKeys(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for key, child := range value {
+			converted[fmt.Sprint(key)] = stringKeys(child)
+		}
+
+		return converted
+	case []interface{}:
+		for idx, child := range value {
+			value[idx] = stringKeys(child)
+		}
+
+		return value
+	default:
+		return value
+	}
+}
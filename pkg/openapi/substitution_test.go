@@ -0,0 +1,222 @@
+package openapi
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+func testSubstitution(t *testing.T, assignments ...string) *Substitution {
+	t.Helper()
+
+	substitution := NewSubstitution(false)
+	for _, assignment := range assignments {
+		err := substitution.Set(assignment)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return substitution
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		name        string
+		assignments []string
+		input       string
+		expected    string
+	}{
+		{
+			name:        "both placeholder forms are expanded",
+			assignments: []string{"Version=1.2.0", "host=api.example.com"},
+			input:       "version: {{ .Version }}\nurl: https://${host}/v1\n",
+			expected:    "version: 1.2.0\nurl: https://api.example.com/v1\n",
+		},
+		{
+			name:        "nested values are expanded",
+			assignments: []string{"db.host=localhost"},
+			input:       "a: ${db.host}\nb: {{.db.host}}\n",
+			expected:    "a: localhost\nb: localhost\n",
+		},
+		{
+			name:     "defaults are used for undefined variables",
+			input:    "url: ${HOST:-localhost}\n",
+			expected: "url: localhost\n",
+		},
+		{
+			name:     "other braces and comments are left as they are",
+			input:    "description: use {{ if }} and {{ \"{{\" }}\n# ${UNDEFINED} {{ .Undefined }}\nkey: value # ${UNDEFINED}\n",
+			expected: "description: use {{ if }} and {{ \"{{\" }}\n# ${UNDEFINED} {{ .Undefined }}\nkey: value # ${UNDEFINED}\n",
+		},
+		{
+			name:        "whole unquoted values are quoted when needed",
+			assignments: []string{"title=a: b # c", "empty=", "list=[1, 2]"},
+			input:       "title: ${title}\nempty: ${empty}\nlist: ${list}\n",
+			expected:    "title: \"a: b # c\"\nempty: \"\"\nlist: \"[1, 2]\"\n",
+		},
+		{
+			name:        "values are escaped inside of quotes",
+			assignments: []string{"value=say \"hi\"\nit's me"},
+			input:       "double: \"<${value}>\"\n",
+			expected:    "double: \"<say \\\"hi\\\"\\nit's me>\"\n",
+		},
+		{
+			name:        "single quotes are doubled",
+			assignments: []string{"value=it's"},
+			input:       "single: '${value}'\n",
+			expected:    "single: 'it''s'\n",
+		},
+		{
+			name:        "lines of values in block scalars are indented",
+			assignments: []string{"text=first\nsecond"},
+			input:       "description: |\n  ${text}\nkey: value\n",
+			expected:    "description: |\n  first\n  second\nkey: value\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expanded, err := testSubstitution(t, tt.assignments...).Expand([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(expanded) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, string(expanded))
+			}
+		})
+	}
+}
+
+func TestExpandReadsValuesBack(t *testing.T) {
+	values := []string{"a: b", "- item", "#hash", "'quoted'", " padded ", "multi\nline", "{{ .Nested }}", "100"}
+	for _, value := range values {
+		substitution := testSubstitution(t, "value="+value)
+		expanded, err := substitution.Expand([]byte("plain: ${value}\ndouble: \"${value}\"\n"))
+		if err != nil {
+			t.Fatalf("%q: %v", value, err)
+		}
+
+		var result map[string]string
+		err = yaml.Unmarshal(expanded, &result)
+		if err != nil {
+			t.Fatalf("%q: could not parse %q: %v", value, expanded, err)
+		}
+
+		if result["plain"] != value || result["double"] != value {
+			t.Errorf("expected %q, got %q and %q", value, result["plain"], result["double"])
+		}
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		assignments []string
+		input       string
+		err         error
+	}{
+		{
+			name:  "undefined variable",
+			input: "url: ${HOST}\n",
+			err:   ErrUndefinedVariable,
+		},
+		{
+			name:  "undefined template value",
+			input: "version: {{ .Version }}\n",
+			err:   ErrUndefinedVariable,
+		},
+		{
+			name:        "structure changing value inside of unquoted text",
+			assignments: []string{"value=b: c"},
+			input:       "key: a ${value}\n",
+			err:         ErrUnsafeValue,
+		},
+		{
+			name:        "multiline value inside of single quotes",
+			assignments: []string{"value=a\nb"},
+			input:       "key: '${value}'\n",
+			err:         ErrUnsafeValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testSubstitution(t, tt.assignments...).Expand([]byte(tt.input))
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestExpandEnvironment(t *testing.T) {
+	err := os.Setenv("API_HOST", "env.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("API_HOST")
+
+	substitution := NewSubstitution(true)
+	err = substitution.Set("API_HOST=values.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expanded, err := substitution.Expand([]byte("a: ${API_HOST}\nb: {{ .Env.API_HOST }}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "a: values.example.com\nb: env.example.com\n"
+	if string(expanded) != expected {
+		t.Errorf("expected %q, got %q", expected, string(expanded))
+	}
+}
+
+func TestReadValues(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"values.yaml": "db:\n  port: 5432\n",
+	})
+
+	substitution := NewSubstitution(false)
+	err := substitution.ReadValues(filepath.Join(dir, "values.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expanded, err := substitution.Expand([]byte("port: ${db.port}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(expanded) != "port: 5432\n" {
+		t.Errorf("expected the nested value to be expanded, got %q", string(expanded))
+	}
+}
+
+func TestSetInvalidAssignment(t *testing.T) {
+	err := NewSubstitution(false).Set("novalue")
+	if !errors.Is(err, ErrInvalidAssignment) {
+		t.Errorf("expected %v, got %v", ErrInvalidAssignment, err)
+	}
+}
+
+func TestParseExpandsReferencedFiles(t *testing.T) {
+	doc := parseFiles(t, Config{Substitution: testSubstitution(t, "title=Pets: API")}, map[string]string{
+		"root.yaml":   "openapi: 3.0.0\ninfo:\n  title: ${title}\ncomponents:\n  schemas:\n    Pet:\n      $ref: 'common.yaml#/components/schemas/Pet'\n",
+		"common.yaml": "components:\n  schemas:\n    Pet:\n      title: ${title}\n",
+	}, "root.yaml")
+
+	if doc.Root.Info.Title != "Pets: API" {
+		t.Errorf("expected the title to be expanded, got %q", doc.Root.Info.Title)
+	}
+
+	if doc.Root.Components.Schemas["Pet"].Title != "Pets: API" {
+		t.Errorf("expected the title in the referenced file to be expanded, got %q", doc.Root.Components.Schemas["Pet"].Title)
+	}
+}
@@ -0,0 +1,78 @@
+package openapi
+
+import (
+	"reflect"
+	"sync"
+)
+
+// typeFields describes fields of a struct type of the model. It is computed once per type, so lookups do not scan fields
+type typeFields struct {
+	byTag  map[string]int
+	byName map[string]int
+	names  []string
+	// ref is an index of the Ref field, or -1 when the type has none
+	ref int
+	// nested are indexes of fields that can hold objects, and therefore references
+	nested []int
+}
+
+// typeIndex holds typeFields by reflect.Type of structs
+var typeIndex sync.Map
+
+// fieldsOf returns fields of the struct type, computing them on the first use
+func fieldsOf(structType reflect.Type) *typeFields {
+	if fields, ok := typeIndex.Load(structType); ok {
+		return fields.(*typeFields)
+	}
+
+	fields := &typeFields{
+		byTag:  make(map[string]int, structType.NumField()),
+		byName: make(map[string]int, structType.NumField()),
+		names:  make([]string, structType.NumField()),
+		ref:    -1,
+	}
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if _, ok := fields.byTag[getYamlKeyFromField(field)]; !ok {
+			fields.byTag[getYamlKeyFromField(field)] = i
+		}
+
+		fields.byName[field.Name] = i
+		fields.names[i] = field.Name
+		if field.Name == Ref && field.Type.Kind() == reflect.String {
+			fields.ref = i
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Struct, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Array:
+			fields.nested = append(fields.nested, i)
+		}
+	}
+
+	actual, _ := typeIndex.LoadOrStore(structType, fields)
+	return actual.(*typeFields)
+}
+
+// fieldByName returns the field of the struct value with the name
+func fieldByName(structValue reflect.Value, name string) (reflect.Value, bool) {
+	idx, ok := fieldsOf(structValue.Type()).byName[name]
+	if !ok {
+		return reflect.Value{}, false
+	}
+
+	return structValue.Field(idx), true
+}
+
+// mapKey converts the name to a key of the map type
+func mapKey(mapType reflect.Type, name string) (reflect.Value, bool) {
+	key := reflect.ValueOf(name)
+	switch {
+	case key.Type().AssignableTo(mapType.Key()):
+		return key, true
+	case key.Type().ConvertibleTo(mapType.Key()):
+		return key.Convert(mapType.Key()), true
+	default:
+		return reflect.Value{}, false
+	}
+}
@@ -2,49 +2,55 @@ package openapi
 
 // Contact ...
 type Contact struct {
-	Name  string `yaml:"name,omitempty"`
-	URL   string `yaml:"url,omitempty"`
-	Email string `yaml:"email,omitempty"`
+	Name       string                 `yaml:"name,omitempty"`
+	URL        string                 `yaml:"url,omitempty"`
+	Email      string                 `yaml:"email,omitempty"`
+	Extensions map[string]interface{} `yaml:",inline"`
 }
 
 // License ...
 type License struct {
-	Name string `yaml:"name,omitempty"`
-	URL  string `yaml:"url,omitempty"`
+	Name       string                 `yaml:"name,omitempty"`
+	URL        string                 `yaml:"url,omitempty"`
+	Extensions map[string]interface{} `yaml:",inline"`
 }
 
 // Info ...
 type Info struct {
-	Title          string   `yaml:"title,omitempty"`
-	Description    string   `yaml:"description,omitempty"`
-	Version        string   `yaml:"version,omitempty"`
-	TermsOfService string   `yaml:"termsOfService,omitempty"`
-	Contact        *Contact `yaml:"contact,omitempty"`
-	License        *License `yaml:"license,omitempty"`
+	Title          string                 `yaml:"title,omitempty"`
+	Description    string                 `yaml:"description,omitempty"`
+	Version        string                 `yaml:"version,omitempty"`
+	TermsOfService string                 `yaml:"termsOfService,omitempty"`
+	Contact        *Contact               `yaml:"contact,omitempty"`
+	License        *License               `yaml:"license,omitempty"`
+	Extensions     map[string]interface{} `yaml:",inline"`
 }
 
 // Encoding ...
 type Encoding struct {
-	AllowReserved bool               `yaml:"allowReserved,omitempty"`
-	ContentType   string             `yaml:"contentType,omitempty"`
-	Explode       bool               `yaml:"explode,omitempty"`
-	Headers       map[string]*Header `yaml:"header,omitempty"`
-	Style         string             `yaml:"string,omitempty"`
+	AllowReserved bool                   `yaml:"allowReserved,omitempty"`
+	ContentType   string                 `yaml:"contentType,omitempty"`
+	Explode       bool                   `yaml:"explode,omitempty"`
+	Headers       map[string]*Header     `yaml:"header,omitempty"`
+	Style         string                 `yaml:"string,omitempty"`
+	Extensions    map[string]interface{} `yaml:",inline"`
 }
 
 // MediaType ...
 type MediaType struct {
-	Ref      string               `yaml:"$ref,omitempty"`
-	Examples map[string]*Example  `yaml:"examples,omitempty"`
-	Encoding map[string]*Encoding `yaml:"encoding,omitempty"`
-	Schema   *Schema              `yaml:"schema,omitempty"`
+	Ref        string                 `yaml:"$ref,omitempty"`
+	Examples   map[string]*Example    `yaml:"examples,omitempty"`
+	Encoding   map[string]*Encoding   `yaml:"encoding,omitempty"`
+	Schema     *Schema                `yaml:"schema,omitempty"`
+	Extensions map[string]interface{} `yaml:",inline"`
 }
 
 // Response ...
 type Response struct {
-	Ref         string                `yaml:"$ref,omitempty"`
-	Content     map[string]*MediaType `yaml:"content,omitempty"`
-	Description string                `yaml:"description,omitempty"`
+	Ref         string                 `yaml:"$ref,omitempty"`
+	Content     map[string]*MediaType  `yaml:"content,omitempty"`
+	Description string                 `yaml:"description,omitempty"`
+	Extensions  map[string]interface{} `yaml:",inline"`
 }
 
 // Operation ...
@@ -61,30 +67,33 @@ type Operation struct {
 	Deprecated   bool                   `yaml:"deprecated,omitempty"`
 	Security     *SecurityRequirement   `yaml:"security,omitempty"`
 	Servers      []*Server              `yaml:"servers,omitempty"`
+	Extensions   map[string]interface{} `yaml:",inline"`
 }
 
 // PathItem ...
 type PathItem struct {
-	Ref         string       `yaml:"$ref,omitempty"`
-	Summary     string       `yaml:"summary,omitempty"`
-	Description string       `yaml:"description,omitempty"`
-	Get         *Operation   `yaml:"get,omitempty"`
-	Put         *Operation   `yaml:"put,omitempty"`
-	Post        *Operation   `yaml:"post,omitempty"`
-	Delete      *Operation   `yaml:"delete,omitempty"`
-	Options     *Operation   `yaml:"options,omitempty"`
-	Head        *Operation   `yaml:"head,omitempty"`
-	Patch       *Operation   `yaml:"patch,omitempty"`
-	Trace       *Operation   `yaml:"trace,omitempty"`
-	Servers     []*Server    `yaml:"servers,omitempty"`
-	Parameters  []*Parameter `yaml:"parameters,omitempty"`
+	Ref         string                 `yaml:"$ref,omitempty"`
+	Summary     string                 `yaml:"summary,omitempty"`
+	Description string                 `yaml:"description,omitempty"`
+	Get         *Operation             `yaml:"get,omitempty"`
+	Put         *Operation             `yaml:"put,omitempty"`
+	Post        *Operation             `yaml:"post,omitempty"`
+	Delete      *Operation             `yaml:"delete,omitempty"`
+	Options     *Operation             `yaml:"options,omitempty"`
+	Head        *Operation             `yaml:"head,omitempty"`
+	Patch       *Operation             `yaml:"patch,omitempty"`
+	Trace       *Operation             `yaml:"trace,omitempty"`
+	Servers     []*Server              `yaml:"servers,omitempty"`
+	Parameters  []*Parameter           `yaml:"parameters,omitempty"`
+	Extensions  map[string]interface{} `yaml:",inline"`
 }
 
 // ServerVariableObject ...
 type ServerVariableObject struct {
-	Default     string   `yaml:"default,omitempty"`
-	Description string   `yaml:"description,omitempty"`
-	Enum        []string `yaml:"enum,omitempty"`
+	Default     string                 `yaml:"default,omitempty"`
+	Description string                 `yaml:"description,omitempty"`
+	Enum        []string               `yaml:"enum,omitempty"`
+	Extensions  map[string]interface{} `yaml:",inline"`
 }
 
 // Server ...
@@ -92,6 +101,7 @@ type Server struct {
 	URL         string                           `yaml:"url,omitempty"`
 	Description string                           `yaml:"description,omitempty"`
 	Variables   map[string]*ServerVariableObject `yaml:"variables,omitempty"`
+	Extensions  map[string]interface{}           `yaml:",inline"`
 }
 
 // Tag ...
@@ -99,12 +109,14 @@ type Tag struct {
 	Name         string                 `yaml:"name,omitempty"`
 	Description  string                 `yaml:"description,omitempty"`
 	ExternalDocs *ExternalDocumentation `yaml:"externalDocs,omitempty"`
+	Extensions   map[string]interface{} `yaml:",inline"`
 }
 
 // ExternalDocumentation ...
 type ExternalDocumentation struct {
-	Description string `yaml:"description,omitempty"`
-	URL         string `yaml:"url,omitempty"`
+	Description string                 `yaml:"description,omitempty"`
+	URL         string                 `yaml:"url,omitempty"`
+	Extensions  map[string]interface{} `yaml:",inline"`
 }
 
 // SecurityRequirement ...
@@ -115,19 +127,23 @@ type OpenAPI struct {
 	Version      string                 `yaml:"openapi,omitempty"`
 	Info         *Info                  `yaml:"info,omitempty"`
 	Paths        map[string]*PathItem   `yaml:"paths,omitempty"`
+	Webhooks     map[string]*PathItem   `yaml:"webhooks,omitempty"`
 	Servers      []*Server              `yaml:"servers,omitempty"`
 	Components   *Components            `yaml:"components,omitempty"`
 	Security     []SecurityRequirement  `yaml:"security,omitempty"`
 	Tags         []*Tag                 `yaml:"tags,omitempty"`
 	ExternalDocs *ExternalDocumentation `yaml:"externalDocs,omitempty"`
+	Extensions   map[string]interface{} `yaml:",inline"`
 }
 
 // Discriminator ...
 type Discriminator struct {
+	Extensions map[string]interface{} `yaml:",inline"`
 }
 
 // XML ...
 type XML struct {
+	Extensions map[string]interface{} `yaml:",inline"`
 }
 
 // Schema ...
@@ -165,56 +181,64 @@ type Schema struct {
 	OneOf            []*Schema              `yaml:"oneOf,omitempty"`
 	AnyOf            []*Schema              `yaml:"anyOf,omitempty"`
 	Not              []*Schema              `yaml:"not,omitempty"`
+	Extensions       map[string]interface{} `yaml:",inline"`
 }
 
 // Parameter ...
 type Parameter struct {
-	Ref             string              `yaml:"$ref,omitempty"`
-	Name            string              `yaml:"name,omitempty"`
-	In              string              `yaml:"in,omitempty"`
-	Description     string              `yaml:"description,omitempty"`
-	Required        bool                `yaml:"required,omitempty"`
-	Deprecated      bool                `yaml:"deprecated,omitempty"`
-	AllowEmptyValue bool                `yaml:"allowEmptyValue,omitempty"`
-	Style           string              `yaml:"style,omitempty"`
-	Explode         bool                `yaml:"explode,omitempty"`
-	AllowReserved   bool                `yaml:"allowReserved,omitempty"`
-	Schema          *Schema             `yaml:"schema,omitempty"`
-	Example         string              `yaml:"example,omitempty"`
-	Examples        map[string]*Example `yaml:"examples,omitempty"`
+	Ref             string                 `yaml:"$ref,omitempty"`
+	Name            string                 `yaml:"name,omitempty"`
+	In              string                 `yaml:"in,omitempty"`
+	Description     string                 `yaml:"description,omitempty"`
+	Required        bool                   `yaml:"required,omitempty"`
+	Deprecated      bool                   `yaml:"deprecated,omitempty"`
+	AllowEmptyValue bool                   `yaml:"allowEmptyValue,omitempty"`
+	Style           string                 `yaml:"style,omitempty"`
+	Explode         bool                   `yaml:"explode,omitempty"`
+	AllowReserved   bool                   `yaml:"allowReserved,omitempty"`
+	Schema          *Schema                `yaml:"schema,omitempty"`
+	Example         string                 `yaml:"example,omitempty"`
+	Examples        map[string]*Example    `yaml:"examples,omitempty"`
+	Extensions      map[string]interface{} `yaml:",inline"`
 }
 
 // Example ...
 type Example struct {
-	Ref string `yaml:"$ref,omitempty"`
+	Ref        string                 `yaml:"$ref,omitempty"`
+	Extensions map[string]interface{} `yaml:",inline"`
 }
 
 // RequestBody ...
 type RequestBody struct {
-	Ref         string                `yaml:"$ref,omitempty"`
-	Description string                `yaml:"description,omitempty"`
-	Content     map[string]*MediaType `yaml:"content,omitempty"`
-	Required    bool                  `yaml:"required,omitempty"`
+	Ref         string                 `yaml:"$ref,omitempty"`
+	Description string                 `yaml:"description,omitempty"`
+	Content     map[string]*MediaType  `yaml:"content,omitempty"`
+	Required    bool                   `yaml:"required,omitempty"`
+	Extensions  map[string]interface{} `yaml:",inline"`
 }
 
 // Header ...
 type Header struct {
-	Ref string `yaml:"$ref,omitempty"`
+	Ref        string                 `yaml:"$ref,omitempty"`
+	Extensions map[string]interface{} `yaml:",inline"`
 }
 
 // SecurityScheme ...
 type SecurityScheme struct {
-	Ref string `yaml:"$ref,omitempty"`
+	Ref        string                 `yaml:"$ref,omitempty"`
+	Extensions map[string]interface{} `yaml:",inline"`
 }
 
 // Link ...
 type Link struct {
-	Ref string `yaml:"$ref,omitempty"`
+	Ref        string                 `yaml:"$ref,omitempty"`
+	Extensions map[string]interface{} `yaml:",inline"`
 }
 
 // Callback ...
 type Callback struct {
-	Ref string `yaml:"$ref,omitempty"`
+	Ref        string                 `yaml:"$ref,omitempty"`
+	Extensions map[string]interface{} `yaml:",inline"`
 }
 
 // Components ...
@@ -228,4 +252,5 @@ type Components struct {
 	SecuritySchemes map[string]*SecurityScheme `yaml:"securitySchemes,omitempty"`
 	Links           map[string]*Link           `yaml:"links,omitempty"`
 	Callbacks       map[string]*Callback       `yaml:"callback,omitempty"`
+	Extensions      map[string]interface{}     `yaml:",inline"`
 }
@@ -0,0 +1,189 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Bump is a part of the semantic version that should be incremented
+type Bump string
+
+const (
+	// NoBump keeps the version as it is
+	NoBump Bump = "none"
+	// PatchBump increments the patch version
+	PatchBump Bump = "patch"
+	// MinorBump increments the minor version and resets the patch version
+	MinorBump Bump = "minor"
+	// MajorBump increments the major version and resets minor and patch versions
+	MajorBump Bump = "major"
+)
+
+var (
+	// ErrInvalidVersion occurs when a version does not follow semantic versioning
+	ErrInvalidVersion = errors.New("version is not a semantic version")
+
+	bumpOrder = map[Bump]int{NoBump: 0, PatchBump: 1, MinorBump: 2, MajorBump: 3}
+	// semverPattern matches versions like 1.2.3, v1.2.3 or 1.2.3-rc.1+build.5
+	semverPattern = regexp.MustCompile(`^(v?)(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?$`)
+)
+
+// BumpRules configure how changes translate into a version bump
+type BumpRules struct {
+	// Breaking is a bump for breaking changes
+	Breaking Bump `yaml:"breaking,omitempty"`
+	// NonBreaking is a bump for backwards compatible changes
+	NonBreaking Bump `yaml:"nonBreaking,omitempty"`
+	// Deprecation is a bump for deprecations
+	Deprecation Bump `yaml:"deprecation,omitempty"`
+	// Informational is a bump for other informational changes
+	Informational Bump `yaml:"informational,omitempty"`
+	// Extensions override bumps for changes of specific extensions, eg. "x-breaking: major"
+	Extensions map[string]Bump `yaml:"extensions,omitempty"`
+	// InitialDevelopment lowers bumps of versions with major version 0 by one level, so breaking changes bump the minor version
+	InitialDevelopment bool `yaml:"initialDevelopment,omitempty"`
+}
+
+// DefaultBumpRules bump major version for breaking changes, minor version for additions and deprecations and patch version otherwise
+func DefaultBumpRules() BumpRules {
+	return BumpRules{
+		Breaking:      MajorBump,
+		NonBreaking:   MinorBump,
+		Deprecation:   MinorBump,
+		Informational: PatchBump,
+	}
+}
+
+// ReadBumpRules reads rules from a YAML file. Rules not specified in the file keep their default values
+func ReadBumpRules(path string) (BumpRules, error) {
+	rules := DefaultBumpRules()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return rules, err
+	}
+
+	err = yaml.UnmarshalStrict(data, &rules)
+	if err != nil {
+		return rules, err
+	}
+
+	return rules, rules.validate()
+}
+
+// SuggestBump returns the highest bump required by any of the changes
+func SuggestBump(changes []Change, rules BumpRules) Bump {
+	suggested := NoBump
+	for _, change := range changes {
+		bump := rules.bumpFor(change)
+		if bumpOrder[bump] > bumpOrder[suggested] {
+			suggested = bump
+		}
+	}
+
+	return suggested
+}
+
+// SuggestVersion bumps the current version according to the changes and the rules
+func SuggestVersion(current string, changes []Change, rules BumpRules) (string, Bump, error) {
+	bump := SuggestBump(changes, rules)
+
+	matches := semverPattern.FindStringSubmatch(current)
+	if matches == nil {
+		return current, bump, fmt.Errorf("%w: %s", ErrInvalidVersion, current)
+	}
+
+	if rules.InitialDevelopment && matches[2] == "0" && bump != NoBump && bump != PatchBump {
+		bump = lowerBump(bump)
+	}
+
+	version, err := BumpVersion(current, bump)
+	return version, bump, err
+}
+
+// BumpVersion increments the part of the semantic version. The "v" prefix is kept, pre-release and build metadata are dropped
+func BumpVersion(version string, bump Bump) (string, error) {
+	matches := semverPattern.FindStringSubmatch(version)
+	if matches == nil {
+		return version, fmt.Errorf("%w: %s", ErrInvalidVersion, version)
+	}
+
+	prefix := matches[1]
+	major, _ := strconv.Atoi(matches[2])
+	minor, _ := strconv.Atoi(matches[3])
+	patch, _ := strconv.Atoi(matches[4])
+
+	switch bump {
+	case MajorBump:
+		major, minor, patch = major+1, 0, 0
+	case MinorBump:
+		minor, patch = minor+1, 0
+	case PatchBump:
+		patch++
+	case NoBump:
+		return version, nil
+	default:
+		return version, fmt.Errorf("unknown bump %s", bump)
+	}
+
+	return fmt.Sprintf("%s%d.%d.%d", prefix, major, minor, patch), nil
+}
+
+// WriteVersion sets info.version of the file, keeping the rest of its content
+func WriteVersion(path string, version string) error {
+	document, err := readRawDocument(path)
+	if err != nil {
+		return err
+	}
+
+	document.content = setRawNode(document.content, []string{"info", "version"}, version)
+	return document.write()
+}
+
+func (r BumpRules) bumpFor(change Change) Bump {
+	if bump, ok := r.Extensions[change.Extension]; ok && change.Extension != "" {
+		return bump
+	}
+
+	switch {
+	case change.Level == Breaking:
+		return r.Breaking
+	case change.Level == NonBreaking:
+		return r.NonBreaking
+	case change.Kind == Deprecated:
+		return r.Deprecation
+	default:
+		return r.Informational
+	}
+}
+
+func (r BumpRules) validate() error {
+	bumps := []Bump{r.Breaking, r.NonBreaking, r.Deprecation, r.Informational}
+	for _, bump := range r.Extensions {
+		bumps = append(bumps, bump)
+	}
+
+	for _, bump := range bumps {
+		if _, ok := bumpOrder[bump]; !ok {
+			return fmt.Errorf("unknown bump %s", bump)
+		}
+	}
+
+	return nil
+}
+
+func lowerBump(bump Bump) Bump {
+	switch bump {
+	case MajorBump:
+		return MinorBump
+	case MinorBump:
+		return PatchBump
+	default:
+		return bump
+	}
+}
@@ -0,0 +1,118 @@
+package openapi
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestBumpVersion(t *testing.T) {
+	for _, tc := range []struct {
+		version  string
+		bump     Bump
+		expected string
+	}{
+		{"1.2.3", MajorBump, "2.0.0"},
+		{"1.2.3", MinorBump, "1.3.0"},
+		{"1.2.3", PatchBump, "1.2.4"},
+		{"1.2.3", NoBump, "1.2.3"},
+		{"v1.2.3-rc.1+build.5", MinorBump, "v1.3.0"},
+	} {
+		version, err := BumpVersion(tc.version, tc.bump)
+		if err != nil {
+			t.Fatalf("%s %s: unexpected error: %v", tc.version, tc.bump, err)
+		}
+
+		if version != tc.expected {
+			t.Errorf("%s %s: expected %s, got %s", tc.version, tc.bump, tc.expected, version)
+		}
+	}
+
+	if _, err := BumpVersion("1.2", PatchBump); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("expected ErrInvalidVersion, got %v", err)
+	}
+}
+
+func TestSuggestVersion(t *testing.T) {
+	initialDevelopment := DefaultBumpRules()
+	initialDevelopment.InitialDevelopment = true
+
+	extensions := DefaultBumpRules()
+	extensions.Extensions = map[string]Bump{"x-breaking": MajorBump}
+
+	for _, tc := range []struct {
+		name     string
+		version  string
+		changes  []Change
+		rules    BumpRules
+		expected string
+		bump     Bump
+	}{
+		{"no changes", "1.2.3", nil, DefaultBumpRules(), "1.2.3", NoBump},
+		{"breaking", "1.2.3", []Change{{Level: NonBreaking}, {Level: Breaking}}, DefaultBumpRules(), "2.0.0", MajorBump},
+		{"non-breaking", "1.2.3", []Change{{Level: NonBreaking}, {Level: Informational}}, DefaultBumpRules(), "1.3.0", MinorBump},
+		{"deprecation", "1.2.3", []Change{{Level: Informational, Kind: Deprecated}}, DefaultBumpRules(), "1.3.0", MinorBump},
+		{"informational", "1.2.3", []Change{{Level: Informational, Kind: Changed}}, DefaultBumpRules(), "1.2.4", PatchBump},
+		{"extension", "1.2.3", []Change{{Level: Informational, Extension: "x-breaking"}}, extensions, "2.0.0", MajorBump},
+		{"initial development", "0.2.3", []Change{{Level: Breaking}}, initialDevelopment, "0.3.0", MinorBump},
+		{"initial development after 1.0.0", "1.2.3", []Change{{Level: Breaking}}, initialDevelopment, "2.0.0", MajorBump},
+	} {
+		version, bump, err := SuggestVersion(tc.version, tc.changes, tc.rules)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+
+		if version != tc.expected || bump != tc.bump {
+			t.Errorf("%s: expected %s (%s), got %s (%s)", tc.name, tc.expected, tc.bump, version, bump)
+		}
+	}
+}
+
+func TestReadBumpRules(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"rules.yaml": `deprecation: patch
+extensions:
+  x-breaking: major
+`,
+		"invalid.yaml": "breaking: huge\n",
+		"unknown.yaml": "breakin: major\n",
+	})
+
+	rules, err := ReadBumpRules(filepath.Join(dir, "rules.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if rules.Deprecation != PatchBump || rules.Breaking != MajorBump || rules.Extensions["x-breaking"] != MajorBump {
+		t.Errorf("expected rules from the file merged with defaults, got %+v", rules)
+	}
+
+	for _, file := range []string{"invalid.yaml", "unknown.yaml"} {
+		if _, err := ReadBumpRules(filepath.Join(dir, file)); err == nil {
+			t.Errorf("%s: expected an error", file)
+		}
+	}
+}
+
+func TestWriteVersion(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"openapi.yaml": `openapi: 3.0.0
+info:
+  title: shop
+  version: 1.2.3
+paths: {}
+`,
+	})
+
+	path := filepath.Join(dir, "openapi.yaml")
+	if err := WriteVersion(path, "1.3.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertFileContent(t, path, `openapi: 3.0.0
+info:
+  title: shop
+  version: 1.3.0
+paths: {}
+`)
+}
@@ -0,0 +1,67 @@
+#!/bin/bash
+
+# Times oas-yaml-combine on a large generated specification: a root file with operations and local refs, and a file with remote components.
+# Usage: ./scripts/bench_combine.sh [operations count, 3000 by default] [git revision to compare with, eg. HEAD~1]
+
+set -e
+
+OPERATIONS=${1:-3000}
+BASELINE=$2
+BENCH_DIR=$(mktemp -d)
+trap 'rm -rf "$BENCH_DIR"' EXIT
+
+awk -v operations="$OPERATIONS" 'BEGIN {
+  print "openapi: 3.0.0"
+  print "info: {title: Bench, version: \"1.0\"}"
+  print "paths:"
+  for (i = 0; i < operations; i++) {
+    printf "  /resources%d/{id}:\n    get:\n      operationId: getResource%d\n      parameters:\n      - {$ref: \"#/components/parameters/Id\"}\n", i, i
+    printf "      responses:\n        \"200\":\n          description: ok\n          content:\n            application/json:\n"
+    printf "              schema: {$ref: \"#/components/schemas/Resource%d\"}\n", i
+    printf "        default: {$ref: \"components.yaml#/components/responses/Error\"}\n"
+  }
+  print "components:"
+  print "  parameters:"
+  print "    Id: {name: id, in: path, required: true, schema: {type: string}}"
+  print "  responses: {}"
+  print "  schemas:"
+  for (i = 0; i < operations; i++) {
+    printf "    Resource%d:\n      type: object\n      required: [id]\n      properties:\n        id: {type: string}\n", i
+    printf "        money: {$ref: \"components.yaml#/components/schemas/Money\"}\n"
+    for (j = 0; j < 5; j++) {
+      printf "        field%d: {type: string, maxLength: 64, description: \"Field %d of resource %d\"}\n", j, j, i
+    }
+  }
+}' > "$BENCH_DIR/openapi.yaml"
+
+cat > "$BENCH_DIR/components.yaml" <<EOF
+components:
+  responses:
+    Error:
+      description: error
+      content:
+        application/json:
+          schema: {\$ref: "#/components/schemas/Error"}
+  schemas:
+    Error: {type: object, properties: {message: {type: string}}}
+    Money: {type: object, properties: {amount: {type: number}, currency: {type: string}}}
+EOF
+
+go build -o "$BENCH_DIR/oas-yaml-combine" ./cmd/oas-yaml-combine/main.go
+BINARIES=("$BENCH_DIR/oas-yaml-combine")
+
+if [ -n "$BASELINE" ]; then
+  git worktree add --detach "$BENCH_DIR/baseline" "$BASELINE" > /dev/null 2>&1
+  (cd "$BENCH_DIR/baseline" && go build -o ../oas-yaml-combine-baseline ./cmd/oas-yaml-combine/main.go)
+  git worktree remove --force "$BENCH_DIR/baseline"
+  BINARIES+=("$BENCH_DIR/oas-yaml-combine-baseline")
+fi
+
+echo "$(wc -l < "$BENCH_DIR/openapi.yaml") lines, $OPERATIONS operations"
+for binary in "${BINARIES[@]}"; do
+  for flags in "" "-inline-local" "-inline-local -inline-remote"; do
+    start=$(date +%s%N)
+    $binary -input-file "$BENCH_DIR/openapi.yaml" -output-file "$BENCH_DIR/output.yaml" $flags > /dev/null
+    echo "$(basename "$binary") ${flags:-(no flags)}: $(( ($(date +%s%N) - start) / 1000000 ))ms"
+  done
+done
@@ -1,4 +1,21 @@
 #!/bin/bash
 
 GOARCH=amd64 go build -ldflags "-s -w" -o ./build/oas-yaml-combine ./cmd/oas-yaml-combine/main.go
-GOOS=windows GOARCH=amd64 go build -ldflags "-s -w" -o ./build/oas-yaml-combine.exe ./cmd/oas-yaml-combine/main.go
\ No newline at end of file
+GOOS=windows GOARCH=amd64 go build -ldflags "-s -w" -o ./build/oas-yaml-combine.exe ./cmd/oas-yaml-combine/main.go
+GOARCH=amd64 go build -ldflags "-s -w" -o ./build/oas-refactor ./cmd/oas-refactor/main.go
+GOOS=windows GOARCH=amd64 go build -ldflags "-s -w" -o ./build/oas-refactor.exe ./cmd/oas-refactor/main.go
+
+GOARCH=amd64 go build -ldflags "-s -w" -o ./build/oas-graph ./cmd/oas-graph/main.go
+GOOS=windows GOARCH=amd64 go build -ldflags "-s -w" -o ./build/oas-graph.exe ./cmd/oas-graph/main.go
+
+GOARCH=amd64 go build -ldflags "-s -w" -o ./build/oas-diff ./cmd/oas-diff/main.go
+GOOS=windows GOARCH=amd64 go build -ldflags "-s -w" -o ./build/oas-diff.exe ./cmd/oas-diff/main.go
+
+GOARCH=amd64 go build -ldflags "-s -w" -o ./build/oas-changelog ./cmd/oas-changelog/main.go
+GOOS=windows GOARCH=amd64 go build -ldflags "-s -w" -o ./build/oas-changelog.exe ./cmd/oas-changelog/main.go
+
+GOARCH=amd64 go build -ldflags "-s -w" -o ./build/oas-semver ./cmd/oas-semver/main.go
+GOOS=windows GOARCH=amd64 go build -ldflags "-s -w" -o ./build/oas-semver.exe ./cmd/oas-semver/main.go
+
+GOARCH=amd64 go build -ldflags "-s -w" -o ./build/oas-format ./cmd/oas-format/main.go
+GOOS=windows GOARCH=amd64 go build -ldflags "-s -w" -o ./build/oas-format.exe ./cmd/oas-format/main.go